@@ -12,6 +12,7 @@ import (
 type Environment struct {
 	pCtx            parser.Context
 	maxMapRecursion int
+	mappingHook     func(blobl string, exec *mapping.Executor)
 }
 
 // GlobalEnvironment returns the global default environment. Modifying this
@@ -74,6 +75,9 @@ func (e *Environment) NewMapping(blobl string) (*mapping.Executor, error) {
 	if e.maxMapRecursion > 0 {
 		exec.SetMaxMapRecursion(e.maxMapRecursion)
 	}
+	if e.mappingHook != nil {
+		e.mappingHook(blobl, exec)
+	}
 	return exec, nil
 }
 
@@ -92,6 +96,17 @@ func (e *Environment) Deactivated() *Environment {
 	return &env
 }
 
+// WithTypeChecking returns a version of the environment where the types of
+// values given to functions and methods are checked at parse time, and values
+// that are statically known to be of an unsupported type result in parse
+// errors. This is useful for linting mappings, where type errors that would
+// otherwise only be seen during execution can be caught early.
+func (e *Environment) WithTypeChecking() *Environment {
+	env := *e
+	env.pCtx = env.pCtx.WithTypeChecking()
+	return &env
+}
+
 // OnlyPure removes any methods and functions that have been registered but are
 // marked as impure. Impure in this context means the method/function is able to
 // mutate global state or access machine state (read environment variables,
@@ -178,6 +193,15 @@ func (e *Environment) WithMaxMapRecursion(n int) *Environment {
 	return &env
 }
 
+// WithMappingHook returns a copy of the environment where a closure function is
+// called with each mapping successfully parsed with NewMapping, which allows
+// the resulting executors to be instrumented.
+func (e *Environment) WithMappingHook(fn func(blobl string, exec *mapping.Executor)) *Environment {
+	env := *e
+	env.mappingHook = fn
+	return &env
+}
+
 // WalkFunctions executes a provided function argument for every function that
 // has been registered to the environment.
 func (e *Environment) WalkFunctions(fn func(name string, spec query.FunctionSpec)) {
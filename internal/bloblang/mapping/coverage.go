@@ -0,0 +1,183 @@
+package mapping
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+)
+
+// BranchCoverage describes the number of times a branch of an if or match
+// expression within a statement was taken.
+type BranchCoverage struct {
+	Line       int    `json:"line"`
+	Column     int    `json:"column"`
+	Expression string `json:"expression"`
+	Branch     string `json:"branch"`
+	Hits       uint64 `json:"hits"`
+}
+
+// StatementCoverage describes the number of times a statement of a mapping was
+// executed, and the number of times each branch of the if and match expressions
+// within the statement were taken. Branches are only listed for expressions
+// that were evaluated at least once.
+type StatementCoverage struct {
+	Line     int              `json:"line"`
+	Target   string           `json:"target"`
+	Hits     uint64           `json:"hits"`
+	Branches []BranchCoverage `json:"branches,omitempty"`
+}
+
+type expressionCoverage struct {
+	offset     int
+	line, col  int
+	expression string
+	branches   []string
+	hits       []uint64
+}
+
+// Coverage records the number of times each statement of a mapping has been
+// executed. A Coverage is safe for concurrent use and can be shared by any
+// number of executors parsed from the same mapping.
+type Coverage struct {
+	executions uint64
+	statements []StatementCoverage
+	hits       []uint64
+
+	exprMut     sync.Mutex
+	expressions []map[int]*expressionCoverage
+}
+
+// NewCoverage creates an empty coverage record for the statements of a mapping
+// executor.
+func NewCoverage(e *Executor) *Coverage {
+	c := &Coverage{
+		statements:  make([]StatementCoverage, len(e.statements)),
+		hits:        make([]uint64, len(e.statements)),
+		expressions: make([]map[int]*expressionCoverage, len(e.statements)),
+	}
+	for i, stmt := range e.statements {
+		var line int
+		if len(e.input) > 0 && len(stmt.input) > 0 {
+			line, _ = LineAndColOf(e.input, stmt.input)
+		}
+		c.statements[i] = StatementCoverage{
+			Line:   line,
+			Target: targetString(stmt.assignment.Target()),
+		}
+		c.expressions[i] = map[int]*expressionCoverage{}
+	}
+	return c
+}
+
+// Executions returns the number of times the mapping was executed.
+func (c *Coverage) Executions() uint64 {
+	return atomic.LoadUint64(&c.executions)
+}
+
+// Statements returns a snapshot of the coverage of each statement.
+func (c *Coverage) Statements() []StatementCoverage {
+	c.exprMut.Lock()
+	defer c.exprMut.Unlock()
+
+	stmts := make([]StatementCoverage, len(c.statements))
+	for i := range c.statements {
+		stmts[i] = c.statements[i]
+		stmts[i].Hits = atomic.LoadUint64(&c.hits[i])
+
+		exprs := make([]*expressionCoverage, 0, len(c.expressions[i]))
+		for _, expr := range c.expressions[i] {
+			exprs = append(exprs, expr)
+		}
+		sort.Slice(exprs, func(i, j int) bool {
+			return exprs[i].offset < exprs[j].offset
+		})
+		for _, expr := range exprs {
+			for j, branch := range expr.branches {
+				stmts[i].Branches = append(stmts[i].Branches, BranchCoverage{
+					Line:       expr.line,
+					Column:     expr.col,
+					Expression: expr.expression,
+					Branch:     branch,
+					Hits:       expr.hits[j],
+				})
+			}
+		}
+	}
+	return stmts
+}
+
+func (c *Coverage) execute() {
+	if c == nil {
+		return
+	}
+	atomic.AddUint64(&c.executions, 1)
+}
+
+func (c *Coverage) hit(index int) {
+	if c == nil {
+		return
+	}
+	atomic.AddUint64(&c.hits[index], 1)
+}
+
+// tracer returns a tracer for recording the branches taken by a single
+// execution of a mapping, or nil if coverage isn't being recorded.
+func (c *Coverage) tracer() *query.Tracer {
+	if c == nil {
+		return nil
+	}
+	return query.NewTracer()
+}
+
+// hitBranches records the branches taken during the execution of a statement
+// from a tracer, where branches of expressions outside of the mapping input,
+// such as those of imported maps, are ignored.
+func (c *Coverage) hitBranches(input []rune, index int, tracer *query.Tracer) {
+	if c == nil {
+		return
+	}
+	branchHits := tracer.TakeBranchHits()
+	if len(branchHits) == 0 {
+		return
+	}
+
+	c.exprMut.Lock()
+	defer c.exprMut.Unlock()
+
+	for _, h := range branchHits {
+		if len(h.Input) == 0 || len(h.Input) > len(input) || &input[len(input)-len(h.Input)] != &h.Input[0] {
+			continue
+		}
+		offset := len(input) - len(h.Input)
+		expr, exists := c.expressions[index][offset]
+		if !exists {
+			line, col := LineAndColOf(input, h.Input)
+			expr = &expressionCoverage{
+				offset:     offset,
+				line:       line,
+				col:        col,
+				expression: h.Expression,
+				branches:   h.Branches,
+				hits:       make([]uint64, len(h.Branches)),
+			}
+			c.expressions[index][offset] = expr
+		}
+		if h.Index < len(expr.hits) {
+			expr.hits[h.Index]++
+		}
+	}
+}
+
+// SetCoverage configures the executor to record the execution of each of its
+// statements to a coverage record. Returns an error if the coverage record was
+// created for a mapping with a different number of statements.
+func (e *Executor) SetCoverage(c *Coverage) error {
+	if c != nil && len(c.statements) != len(e.statements) {
+		return errors.New("coverage statement count does not match the mapping")
+	}
+	e.coverage = c
+	return nil
+}
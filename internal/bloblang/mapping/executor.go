@@ -63,7 +63,9 @@ type Executor struct {
 	annotation string
 	input      []rune
 	maps       map[string]query.Function
+	funcs      map[string]*query.UserFunction
 	statements []Statement
+	coverage   *Coverage
 
 	maxMapStacks int
 }
@@ -75,14 +77,35 @@ const defaultMaxMapStacks = 5000
 // is an optional slice pointing to the parsed expression that created the
 // executor.
 func NewExecutor(annotation string, input []rune, maps map[string]query.Function, statements ...Statement) *Executor {
-	return &Executor{annotation, input, maps, statements, defaultMaxMapStacks}
+	return &Executor{
+		annotation:   annotation,
+		input:        input,
+		maps:         maps,
+		statements:   statements,
+		maxMapStacks: defaultMaxMapStacks,
+	}
 }
 
-// SetMaxMapRecursion configures the maximum recursion allowed for maps, if the
-// execution of this mapping matches this number of recursive map calls the
-// mapping will error out.
+// SetMaxMapRecursion configures the maximum recursion allowed for maps and
+// functions, if the execution of this mapping matches this number of recursive
+// map or function calls the mapping will error out.
 func (e *Executor) SetMaxMapRecursion(m int) {
 	e.maxMapStacks = m
+	for _, v := range e.maps {
+		if mExec, ok := v.(*Executor); ok {
+			mExec.maxMapStacks = m
+		}
+	}
+	for _, v := range e.funcs {
+		if fExec, ok := v.Body().(*Executor); ok {
+			fExec.maxMapStacks = m
+		}
+	}
+}
+
+// SetFunctions sets the function definitions contained within the mapping.
+func (e *Executor) SetFunctions(funcs map[string]*query.UserFunction) {
+	e.funcs = funcs
 }
 
 // Annotation returns a string annotation that describes the mapping executor.
@@ -95,6 +118,11 @@ func (e *Executor) Maps() map[string]query.Function {
 	return e.maps
 }
 
+// Functions returns any function definitions contained within the mapping.
+func (e *Executor) Functions() map[string]*query.UserFunction {
+	return e.funcs
+}
+
 // QueryPart executes the bloblang mapping on a particular message index of a
 // batch. The message is parsed as a JSON document in order to provide the
 // mapping context. The result of the mapping is expected to be a boolean value
@@ -166,7 +194,11 @@ func (e *Executor) mapPart(appendTo *message.Part, index int, reference Message)
 
 	vars := map[string]interface{}{}
 
-	for _, stmt := range e.statements {
+	e.coverage.execute()
+	tracer := e.coverage.tracer()
+
+	for i, stmt := range e.statements {
+		e.coverage.hit(i)
 		res, err := stmt.query.Exec(query.FunctionContext{
 			Maps:     e.maps,
 			Vars:     vars,
@@ -174,7 +206,9 @@ func (e *Executor) mapPart(appendTo *message.Part, index int, reference Message)
 			MsgBatch: reference,
 			NewMeta:  newPart,
 			NewValue: &newValue,
+			Tracer:   tracer,
 		}.WithValueFunc(lazyValue))
+		e.coverage.hitBranches(e.input, i, tracer)
 		if err != nil {
 			var line int
 			if len(e.input) > 0 && len(stmt.input) > 0 {
@@ -257,8 +291,15 @@ func (e *Executor) Exec(ctx query.FunctionContext) (interface{}, error) {
 	var newObj interface{} = query.Nothing(nil)
 	ctx.NewValue = &newObj
 
-	for _, stmt := range e.statements {
+	e.coverage.execute()
+	if e.coverage != nil {
+		ctx.Tracer = e.coverage.tracer()
+	}
+
+	for i, stmt := range e.statements {
+		e.coverage.hit(i)
 		res, err := stmt.query.Exec(ctx)
+		e.coverage.hitBranches(e.input, i, ctx.Tracer)
 		if err != nil {
 			return nil, formatExecErr(err, true, e.input, stmt.input)
 		}
@@ -280,8 +321,15 @@ func (e *Executor) Exec(ctx query.FunctionContext) (interface{}, error) {
 
 // ExecOnto a provided assignment context.
 func (e *Executor) ExecOnto(ctx query.FunctionContext, onto AssignmentContext) error {
-	for _, stmt := range e.statements {
+	e.coverage.execute()
+	if e.coverage != nil {
+		ctx.Tracer = e.coverage.tracer()
+	}
+
+	for i, stmt := range e.statements {
+		e.coverage.hit(i)
 		res, err := stmt.query.Exec(ctx)
+		e.coverage.hitBranches(e.input, i, ctx.Tracer)
 		if err != nil {
 			return formatExecErr(err, true, e.input, stmt.input)
 		}
@@ -296,6 +344,39 @@ func (e *Executor) ExecOnto(ctx query.FunctionContext, onto AssignmentContext) e
 	return nil
 }
 
+// ExecOntoWithTrace executes the mapping onto a provided assignment context in
+// the same way as ExecOnto, but also returns a trace of each statement that was
+// executed. The trace is returned even when an error occurs, in which case the
+// last statement of the trace is the one that failed.
+func (e *Executor) ExecOntoWithTrace(ctx query.FunctionContext, onto AssignmentContext) ([]StatementTrace, error) {
+	ctx.Tracer = query.NewTracer()
+	e.coverage.execute()
+
+	traces := make([]StatementTrace, 0, len(e.statements))
+	for i, stmt := range e.statements {
+		var line int
+		if len(e.input) > 0 && len(stmt.input) > 0 {
+			line, _ = LineAndColOf(e.input, stmt.input)
+		}
+
+		e.coverage.hit(i)
+		res, err := stmt.query.Exec(ctx)
+		e.coverage.hitBranches(e.input, i, ctx.Tracer)
+		if err != nil {
+			err = formatExecErr(err, true, e.input, stmt.input)
+			return append(traces, newStatementTrace(stmt, line, nil, err, ctx.Tracer)), err
+		}
+		if _, isNothing := res.(query.Nothing); !isNothing {
+			if err = stmt.assignment.Apply(res, onto); err != nil {
+				err = formatExecErr(err, false, e.input, stmt.input)
+				return append(traces, newStatementTrace(stmt, line, nil, err, ctx.Tracer)), err
+			}
+		}
+		traces = append(traces, newStatementTrace(stmt, line, res, nil, ctx.Tracer))
+	}
+	return traces, nil
+}
+
 // ToBytes executes this function for a message of a batch and returns the
 // result marshalled into a byte slice.
 func (e *Executor) ToBytes(ctx query.FunctionContext) []byte {
@@ -0,0 +1,58 @@
+package mapping
+
+import (
+	"strings"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+)
+
+// StatementTrace describes the execution of a single statement of a mapping,
+// including the value that it evaluated to and the branches of any if or match
+// expressions that were taken in order to reach that value.
+type StatementTrace struct {
+	Line     int                 `json:"line"`
+	Target   string              `json:"target"`
+	Value    interface{}         `json:"value,omitempty"`
+	Deleted  bool                `json:"deleted,omitempty"`
+	Skipped  bool                `json:"skipped,omitempty"`
+	Error    string              `json:"error,omitempty"`
+	Branches []query.BranchTrace `json:"branches,omitempty"`
+}
+
+func targetString(t TargetPath) string {
+	switch t.Type {
+	case TargetVariable:
+		return "$" + strings.Join(t.Path, ".")
+	case TargetMetadata:
+		if len(t.Path) == 0 {
+			return "meta"
+		}
+		return "meta " + strings.Join(t.Path, ".")
+	}
+	if len(t.Path) == 0 {
+		return "root"
+	}
+	return "root." + query.SliceToDotPath(t.Path...)
+}
+
+func newStatementTrace(stmt Statement, line int, res interface{}, err error, tracer *query.Tracer) StatementTrace {
+	sTrace := StatementTrace{
+		Line:     line,
+		Target:   targetString(stmt.assignment.Target()),
+		Branches: tracer.TakeBranches(),
+	}
+	if err != nil {
+		sTrace.Error = err.Error()
+		return sTrace
+	}
+	switch res.(type) {
+	case query.Delete:
+		sTrace.Deleted = true
+	case query.Nothing:
+		sTrace.Skipped = true
+	default:
+		// Values may be mutated by subsequent assignments.
+		sTrace.Value = query.IClone(res)
+	}
+	return sTrace
+}
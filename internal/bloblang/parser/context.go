@@ -16,6 +16,10 @@ type Context struct {
 	Methods      *query.MethodSet
 	namedContext *namedContext
 	importer     Importer
+	typeChecking bool
+
+	// Functions defined within the mapping being parsed.
+	userFunctions map[string]*query.UserFunction
 }
 
 // EmptyContext returns a parser context with no functions, methods or import
@@ -62,18 +66,47 @@ func (pCtx Context) HasNamedContext(name string) bool {
 	return false
 }
 
+// withUserFunctions returns a Context where functions defined within a mapping
+// are registered to and resolved from the provided map.
+func (pCtx Context) withUserFunctions(funcs map[string]*query.UserFunction) Context {
+	pCtx.userFunctions = funcs
+	return pCtx
+}
+
 // InitFunction attempts to initialise a function from the available
 // constructors of the parser context.
 func (pCtx Context) InitFunction(name string, args *query.ParsedParams) (query.Function, error) {
+	if pCtx.typeChecking {
+		if err := pCtx.Functions.CheckTypes(name, args); err != nil {
+			return nil, err
+		}
+	}
 	return pCtx.Functions.Init(name, args)
 }
 
 // InitMethod attempts to initialise a method from the available constructors of
 // the parser context.
 func (pCtx Context) InitMethod(name string, target query.Function, args *query.ParsedParams) (query.Function, error) {
+	if pCtx.typeChecking {
+		if err := pCtx.Methods.CheckTypes(name, target, args); err != nil {
+			return nil, err
+		}
+	}
 	return pCtx.Methods.Init(name, target, args)
 }
 
+// WithTypeChecking returns a Context where the types of values given to
+// functions and methods are checked during parsing, and any that are known to
+// be unsupported without needing to execute the mapping result in an error.
+//
+// Type checking is not enabled by default as mappings that contain these
+// errors could still be executed successfully, e.g. when the offending
+// expression is within a branch that is never taken.
+func (pCtx Context) WithTypeChecking() Context {
+	pCtx.typeChecking = true
+	return pCtx
+}
+
 // WithImporter returns a Context where imports are made from the provided
 // Importer implementation.
 func (pCtx Context) WithImporter(importer Importer) Context {
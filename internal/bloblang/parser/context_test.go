@@ -9,6 +9,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
 )
 
 func TestContextImportIsolation(t *testing.T) {
@@ -169,3 +171,103 @@ func TestContextCustomImports(t *testing.T) {
 		assert.Equal(t, `map baz { root.baz = this.baz }`, string(content))
 	}
 }
+
+func TestContextTypeChecking(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapping string
+		err     string
+	}{
+		{
+			name:    "unknown input types",
+			mapping: `root.total = this.price.uppercase()`,
+		},
+		{
+			name:    "supported coerced type",
+			mapping: `root.total = this.price.string().uppercase()`,
+		},
+		{
+			name:    "unsupported coerced type",
+			mapping: `root.total = this.price.number().uppercase()`,
+			err:     "line 1 char 34: method uppercase: expected string or bytes value, got number from method number",
+		},
+		{
+			name:    "unsupported literal type",
+			mapping: `root.total = 10.uppercase()`,
+			err:     "line 1 char 17: method uppercase: expected string or bytes value, got number from number literal",
+		},
+		{
+			name:    "unsupported function type",
+			mapping: `root.total = now().sum()`,
+			err:     "line 1 char 20: method sum: expected number or array value, got string from function now",
+		},
+		{
+			name:    "unsupported comparison type",
+			mapping: `root.total = (this.a > this.b).keys()`,
+			err:     "line 1 char 32: method keys: expected object value, got bool from field `this.b`",
+		},
+		{
+			name:    "unsupported object literal",
+			mapping: `root.total = {"a":this.a}.join(",")`,
+			err:     "line 1 char 27: method join: expected array value, got object from object literal",
+		},
+		{
+			name:    "unsupported argument type",
+			mapping: `root.total = this.a.has_prefix(this.b.length())`,
+			err:     "line 1 char 21: field value: expected string or bytes value, got number from method length",
+		},
+		{
+			name:    "nested within branch",
+			mapping: `root.total = if this.a { this.b.keys().uppercase() }`,
+			err:     "line 1 char 40: method uppercase: expected string or bytes value, got array from method keys",
+		},
+		{
+			name: "nested within map",
+			mapping: `map foo {
+  root = this.length().lowercase()
+}
+root = this.apply("foo")`,
+			err: "line 2 char 24: method lowercase: expected string or bytes value, got number from method length",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseMapping(GlobalContext(), test.mapping)
+			require.Nil(t, err)
+
+			_, err = ParseMapping(GlobalContext().WithTypeChecking(), test.mapping)
+			if test.err == "" {
+				require.Nil(t, err)
+			} else {
+				require.NotNil(t, err)
+				assert.Equal(t, test.err, err.ErrorAtPosition([]rune(test.mapping)))
+			}
+		})
+	}
+}
+
+func TestContextTypeCheckingDocsExamples(t *testing.T) {
+	pCtx := GlobalContext().WithTypeChecking()
+	checkExamples := func(name string, examples []query.ExampleSpec) {
+		for i, e := range examples {
+			if _, err := ParseMapping(GlobalContext(), e.Mapping); err != nil {
+				// Some examples cannot be parsed outside of their environment.
+				continue
+			}
+			if _, err := ParseMapping(pCtx, e.Mapping); err != nil {
+				t.Errorf("%v example %v: %v", name, i, err.ErrorAtPosition([]rune(e.Mapping)))
+			}
+		}
+	}
+	for _, spec := range query.FunctionDocs() {
+		checkExamples("function "+spec.Name, spec.Examples)
+	}
+	for _, spec := range query.MethodDocs() {
+		checkExamples("method "+spec.Name, spec.Examples)
+		for _, cat := range spec.Categories {
+			checkExamples("method "+spec.Name, cat.Examples)
+		}
+	}
+}
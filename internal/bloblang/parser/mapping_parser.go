@@ -49,14 +49,17 @@ func parseExecutor(pCtx Context) Func {
 
 	return func(input []rune) Result {
 		maps := map[string]query.Function{}
+		funcs := map[string]*query.UserFunction{}
 		statements := []mapping.Statement{}
 
+		ctx := pCtx.withUserFunctions(funcs)
 		statement := OneOf(
-			importParser(maps, pCtx),
-			mapParser(maps, pCtx),
-			letStatementParser(pCtx),
-			metaStatementParser(false, pCtx),
-			plainMappingStatementParser(pCtx),
+			importParser(maps, funcs, ctx),
+			mapParser(maps, ctx),
+			funcParser(maps, funcs, ctx),
+			letStatementParser(ctx),
+			metaStatementParser(false, ctx),
+			plainMappingStatementParser(ctx),
 		)
 
 		res := allWhitespace(input)
@@ -92,7 +95,9 @@ func parseExecutor(pCtx Context) Func {
 				statements = append(statements, mStmt)
 			}
 		}
-		return Success(mapping.NewExecutor("", input, maps, statements...), res.Remaining)
+		exec := mapping.NewExecutor("", input, maps, statements...)
+		exec.SetFunctions(funcs)
+		return Success(exec, res.Remaining)
 	}
 }
 
@@ -181,7 +186,7 @@ func varNameParser() Func {
 	)
 }
 
-func importParser(maps map[string]query.Function, pCtx Context) Func {
+func importParser(maps map[string]query.Function, funcs map[string]*query.UserFunction, pCtx Context) Func {
 	p := Sequence(
 		Term("import"),
 		SpacesAndTabs(),
@@ -214,8 +219,8 @@ func importParser(maps map[string]query.Function, pCtx Context) Func {
 		}
 
 		exec := execRes.Payload.(*mapping.Executor)
-		if len(exec.Maps()) == 0 {
-			err := fmt.Errorf("no maps to import from '%v'", fpath)
+		if len(exec.Maps()) == 0 && len(exec.Functions()) == 0 {
+			err := fmt.Errorf("no maps or functions to import from '%v'", fpath)
 			return Fail(NewFatalError(input, err), input)
 		}
 
@@ -232,6 +237,18 @@ func importParser(maps map[string]query.Function, pCtx Context) Func {
 			return Fail(NewFatalError(input, err), input)
 		}
 
+		for k, v := range exec.Functions() {
+			if _, exists := funcs[k]; exists {
+				collisions = append(collisions, k)
+			} else {
+				funcs[k] = v
+			}
+		}
+		if len(collisions) > 0 {
+			err := fmt.Errorf("function name collisions from import '%v': %v", fpath, collisions)
+			return Fail(NewFatalError(input, err), input)
+		}
+
 		return Success(fpath, res.Remaining)
 	}
 }
@@ -303,6 +320,103 @@ func mapParser(maps map[string]query.Function, pCtx Context) Func {
 	}
 }
 
+func funcParser(maps map[string]query.Function, funcs map[string]*query.UserFunction, pCtx Context) Func {
+	newline := NewlineAllowComment()
+	whitespace := SpacesAndTabs()
+	allWhitespace := DiscardAll(OneOf(whitespace, newline))
+
+	header := Sequence(
+		Term("func"),
+		whitespace,
+		// Prevents a missing name from being captured by the next parser
+		MustBe(
+			Expect(
+				SnakeCase(),
+				"function name",
+			),
+		),
+		MustBe(
+			DelimitedPattern(
+				Expect(Sequence(Char('('), allWhitespace), "function parameters"),
+				Expect(SnakeCase(), "parameter name"),
+				Expect(Sequence(Discard(whitespace), Char(','), allWhitespace), "comma"),
+				Expect(Sequence(allWhitespace, Char(')')), "closing bracket"),
+				false,
+			),
+		),
+		SpacesAndTabs(),
+	)
+
+	body := DelimitedPattern(
+		Sequence(
+			Char('{'),
+			allWhitespace,
+		),
+		OneOf(
+			letStatementParser(pCtx),
+			metaStatementParser(true, pCtx),
+			plainMappingStatementParser(pCtx),
+		),
+		Sequence(
+			Discard(whitespace),
+			newline,
+			allWhitespace,
+		),
+		Sequence(
+			allWhitespace,
+			Char('}'),
+		),
+		true,
+	)
+
+	return func(input []rune) Result {
+		res := header(input)
+		if res.Err != nil {
+			return res
+		}
+
+		seqSlice := res.Payload.([]interface{})
+		ident := seqSlice[2].(string)
+
+		if _, exists := funcs[ident]; exists {
+			return Fail(NewFatalError(input, fmt.Errorf("function name collision: %v", ident)), input)
+		}
+		if _, err := pCtx.Functions.Params(ident); err == nil {
+			return Fail(NewFatalError(input, fmt.Errorf("function name collision with builtin function: %v", ident)), input)
+		}
+
+		var paramNames []string
+		seen := map[string]struct{}{}
+		for _, v := range seqSlice[3].([]interface{}) {
+			name := v.(string)
+			if _, exists := seen[name]; exists {
+				return Fail(NewFatalError(input, fmt.Errorf("duplicate parameter name: %v", name)), input)
+			}
+			seen[name] = struct{}{}
+			paramNames = append(paramNames, name)
+		}
+
+		// The function is registered before parsing the body so that it can
+		// call itself recursively.
+		uFn := query.NewUserFunction(ident, paramNames...)
+		funcs[ident] = uFn
+
+		if res = body(res.Remaining); res.Err != nil {
+			delete(funcs, ident)
+			return Fail(res.Err, input)
+		}
+
+		stmtSlice := res.Payload.([]interface{})
+		statements := make([]mapping.Statement, len(stmtSlice))
+		for i, v := range stmtSlice {
+			statements[i] = v.(mapping.Statement)
+		}
+
+		uFn.SetBody(mapping.NewExecutor("function "+ident, input, maps, statements...))
+		return Success(ident, res.Remaining)
+	}
+}
+
 func letStatementParser(pCtx Context) Func {
 	p := Sequence(
 		Expect(Term("let"), "assignment"),
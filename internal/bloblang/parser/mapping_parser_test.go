@@ -9,6 +9,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/benthosdev/benthos/v4/internal/bloblang/mapping"
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
 	"github.com/benthosdev/benthos/v4/internal/message"
 )
 
@@ -18,10 +20,12 @@ func TestMappingErrors(t *testing.T) {
 	badMapFile := filepath.Join(dir, "bad_map.blobl")
 	noMapsFile := filepath.Join(dir, "no_maps.blobl")
 	goodMapFile := filepath.Join(dir, "good_map.blobl")
+	goodFuncFile := filepath.Join(dir, "good_func.blobl")
 
 	require.NoError(t, os.WriteFile(badMapFile, []byte(`not a map bruh`), 0o777))
 	require.NoError(t, os.WriteFile(noMapsFile, []byte(`foo = "this is valid but has no maps"`), 0o777))
 	require.NoError(t, os.WriteFile(goodMapFile, []byte(`map foo { foo = "this is valid" }`), 0o777))
+	require.NoError(t, os.WriteFile(goodFuncFile, []byte(`func foo(a) { root = $a }`), 0o777))
 
 	tests := map[string]struct {
 		mapping     string
@@ -37,12 +41,12 @@ func TestMappingErrors(t *testing.T) {
 		},
 		"no mappings": {
 			mapping:     ``,
-			errContains: `line 1 char 1: expected import, map, or assignment`,
+			errContains: `line 1 char 1: expected import, map, func, or assignment`,
 		},
 		"no mappings 2": {
 			mapping: `
    `,
-			errContains: `line 2 char 4: expected import, map, or assignment`,
+			errContains: `line 2 char 4: expected import, map, func, or assignment`,
 		},
 		"double mapping": {
 			mapping:     `foo = bar bar = baz`,
@@ -83,13 +87,13 @@ meta foo = "bar"`,
 		"bad char 2": {
 			mapping: `let foo = bar
 !foo = bar`,
-			errContains: `line 2 char 1: expected import, map, or assignment`,
+			errContains: `line 2 char 1: expected import, map, func, or assignment`,
 		},
 		"bad char 3": {
 			mapping: `let foo = bar
 !foo = bar
 this = that`,
-			errContains: `line 2 char 1: expected import, map, or assignment`,
+			errContains: `line 2 char 1: expected import, map, func, or assignment`,
 		},
 		"bad query": {
 			mapping:     `foo = blah.`,
@@ -139,7 +143,7 @@ foo = bar.apply("from_import")`, badMapFile),
 			mapping: fmt.Sprintf(`import "%v"
 
 foo = bar.apply("from_import")`, noMapsFile),
-			errContains: fmt.Sprintf(`line 1 char 1: no maps to import from '%v'`, noMapsFile),
+			errContains: fmt.Sprintf(`line 1 char 1: no maps or functions to import from '%v'`, noMapsFile),
 		},
 		"colliding maps file import": {
 			mapping: fmt.Sprintf(`map "foo" { this = that }			
@@ -149,10 +153,70 @@ import "%v"
 foo = bar.apply("foo")`, goodMapFile),
 			errContains: fmt.Sprintf(`line 3 char 1: map name collisions from import '%v': [foo]`, goodMapFile),
 		},
+		"double func definition": {
+			mapping: `func foo(a) {
+  root = $a
+}
+func foo(b) {
+  root = $b
+}
+foo = foo(bar)`,
+			errContains: `line 4 char 1: function name collision: foo`,
+		},
+		"func collides with builtin": {
+			mapping: `func uuid_v4() {
+  root = "nope"
+}
+foo = uuid_v4()`,
+			errContains: `line 1 char 1: function name collision with builtin function: uuid_v4`,
+		},
+		"func duplicate parameters": {
+			mapping: `func foo(a, a) {
+  root = $a
+}
+foo = foo(1, 2)`,
+			errContains: `line 1 char 1: duplicate parameter name: a`,
+		},
+		"func missing parameters": {
+			mapping: `func foo {
+  root = "foo"
+}
+foo = foo()`,
+			errContains: `line 1 char 9: required: expected function parameters`,
+		},
+		"func contains meta assignment": {
+			mapping: `func foo() {
+  meta foo = "bar"
+}
+foo = foo()`,
+			errContains: `line 2 char 3: setting meta fields from within a map is not allowed`,
+		},
+		"func wrong number of arguments": {
+			mapping: `func foo(a, b) {
+  root = $a + $b
+}
+foo = foo(1)`,
+			errContains: `line 4 char 7: missing parameter: b`,
+		},
+		"func used before definition": {
+			mapping: `foo = foo(1)
+func foo(a) {
+  root = $a
+}`,
+			errContains: `line 1 char 7: unrecognised function 'foo'`,
+		},
+		"colliding funcs file import": {
+			mapping: fmt.Sprintf(`func foo(b) { root = $b }
+
+import "%v"
+
+foo = foo(bar)`, goodFuncFile),
+			errContains: fmt.Sprintf(`line 3 char 1: function name collisions from import '%v': [foo]`, goodFuncFile),
+		},
 		"quotes at root": {
 			mapping: `
 "root.something" = 5 + 2`,
-			errContains: "line 2 char 1: expected import, map, or assignment",
+			errContains: "line 2 char 1: expected import, map, func, or assignment",
 		},
 	}
 
@@ -179,6 +243,11 @@ func TestMappings(t *testing.T) {
 	directMapFile := filepath.Join(dir, "direct_map.blobl")
 	require.NoError(t, os.WriteFile(directMapFile, []byte(`root.nested = this`), 0o777))
 
+	goodFuncFile := filepath.Join(dir, "foo_func.blobl")
+	require.NoError(t, os.WriteFile(goodFuncFile, []byte(`func greet(name, greeting) {
+  root = "%s %s".format($greeting, $name)
+}`), 0o777))
+
 	type part struct {
 		Content string
 		Meta    map[string]string
@@ -457,6 +526,62 @@ root = this.apply("foo")`, goodMapFile),
 				Content: `{"foo":"this is valid","nested":{"outter":{"inner":"hello world"}}}`,
 			},
 		},
+		"test function with parameters": {
+			mapping: `func normalise(value, fallback) {
+  let trimmed = $value.string().trim().lowercase()
+  root = if $trimmed == "" { $fallback } else { $trimmed }
+}
+
+root.a = normalise(this.a, "none")
+root.b = normalise(fallback: "none", value: this.b)`,
+			input: []part{
+				{Content: `{"a":"  FOO ","b":""}`},
+			},
+			output: part{
+				Content: `{"a":"foo","b":"none"}`,
+			},
+		},
+		"test recursive function": {
+			mapping: `func factorial(n) {
+  root = if $n <= 1 { 1 } else { $n * factorial($n - 1) }
+}
+
+root = factorial(this.n)`,
+			input: []part{
+				{Content: `{"n":5}`},
+			},
+			output: part{
+				Content: `120`,
+			},
+		},
+		"test function called from map": {
+			mapping: `func double(n) {
+  root = $n * 2
+}
+
+map things {
+  root.doubled = double(this.value)
+}
+
+root = this.apply("things")`,
+			input: []part{
+				{Content: `{"value":4}`},
+			},
+			output: part{
+				Content: `{"doubled":8}`,
+			},
+		},
+		"test imported function": {
+			mapping: fmt.Sprintf(`import "%v"
+
+root.greeting = greet(this.name, "hello")`, goodFuncFile),
+			input: []part{
+				{Content: `{"name":"bob"}`},
+			},
+			output: part{
+				Content: `{"greeting":"hello bob"}`,
+			},
+		},
 		"test directly imported map": {
 			mapping: fmt.Sprintf(`from "%v"`, directMapFile),
 			input: []part{
@@ -502,3 +627,115 @@ root = this.apply("foo")`, goodMapFile),
 		})
 	}
 }
+
+func TestMappingFunctionIsolation(t *testing.T) {
+	exec, perr := ParseMapping(GlobalContext(), `func foo(a) {
+  root = $a + $b
+}
+
+let b = 10
+root = foo(5)`)
+	require.Nil(t, perr)
+
+	_, err := exec.MapPart(0, message.QuickBatch([][]byte{[]byte(`{}`)}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "variable 'b' undefined")
+
+	exec, perr = ParseMapping(GlobalContext(), `func foo(a) {
+  root = this.value + $a
+}
+
+root = foo(5)`)
+	require.Nil(t, perr)
+
+	_, err = exec.MapPart(0, message.QuickBatch([][]byte{[]byte(`{"value":10}`)}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "context was undefined")
+}
+
+func TestMappingFunctionRecursionLimit(t *testing.T) {
+	exec, perr := ParseMapping(GlobalContext(), `func forever(n) {
+  root = forever($n + 1)
+}
+
+root = forever(0)`)
+	require.Nil(t, perr)
+
+	exec.SetMaxMapRecursion(10)
+
+	_, err := exec.MapPart(0, message.QuickBatch([][]byte{[]byte(`{}`)}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "entering function forever exceeded maximum allowed stacks of 10")
+}
+
+func TestMappingTrace(t *testing.T) {
+	exec, perr := ParseMapping(GlobalContext(), `let kind = if this.value > 10 { "big" } else if this.value > 5 { "medium" } else { "small" }
+root.kind = $kind
+root.size = match $kind {
+  "small" => 1
+  "medium" => 2
+  _ => 3
+}
+meta foo = "bar"
+root.nope = deleted()
+root.skipped = if false { "nope" }`)
+	require.Nil(t, perr)
+
+	var result interface{} = query.Nothing(nil)
+	vars := map[string]interface{}{}
+	msg := message.QuickBatch([][]byte{[]byte(`{}`)})
+	var value interface{} = map[string]interface{}{"value": int64(7)}
+
+	traces, err := exec.ExecOntoWithTrace(query.FunctionContext{
+		Maps:     exec.Maps(),
+		Vars:     vars,
+		MsgBatch: msg,
+		NewMeta:  msg.Get(0),
+		NewValue: &result,
+	}.WithValue(value), mapping.AssignmentContext{
+		Vars:  vars,
+		Meta:  msg.Get(0),
+		Value: &result,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []mapping.StatementTrace{
+		{
+			Line: 1, Target: "$kind", Value: "medium",
+			Branches: []query.BranchTrace{{Expression: "if", Branch: "else if 1"}},
+		},
+		{Line: 2, Target: "root.kind", Value: "medium"},
+		{
+			Line: 3, Target: "root.size", Value: int64(2),
+			Branches: []query.BranchTrace{{Expression: "match", Branch: "case 2"}},
+		},
+		{Line: 8, Target: "meta foo", Value: "bar"},
+		{Line: 9, Target: "root.nope", Deleted: true},
+		{
+			Line: 10, Target: "root.skipped", Skipped: true,
+			Branches: []query.BranchTrace{{Expression: "if", Branch: "none"}},
+		},
+	}, traces)
+	assert.Equal(t, map[string]interface{}{"kind": "medium", "size": int64(2)}, result)
+
+	exec, perr = ParseMapping(GlobalContext(), `root.a = "foo"
+root.b = this.nope.number()`)
+	require.Nil(t, perr)
+
+	traces, err = exec.ExecOntoWithTrace(query.FunctionContext{
+		Maps:     exec.Maps(),
+		Vars:     vars,
+		MsgBatch: msg,
+		NewMeta:  msg.Get(0),
+		NewValue: &result,
+	}.WithValue(value), mapping.AssignmentContext{
+		Vars:  vars,
+		Meta:  msg.Get(0),
+		Value: &result,
+	})
+	require.Error(t, err)
+	require.Len(t, traces, 2)
+	assert.Equal(t, "root.a", traces[0].Target)
+	assert.Equal(t, "root.b", traces[1].Target)
+	assert.Equal(t, err.Error(), traces[1].Error)
+}
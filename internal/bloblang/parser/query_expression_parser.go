@@ -116,7 +116,7 @@ func matchExpressionParser(pCtx Context) Func {
 			cases = append(cases, caseVal.(query.MatchCase))
 		}
 
-		res.Payload = query.NewMatchFunction(contextFn, cases...)
+		res.Payload = query.NewMatchFunction(input, contextFn, cases...)
 		return res
 	}
 }
@@ -201,7 +201,7 @@ func ifExpressionParser(pCtx Context) Func {
 			elseFn, _ = res.Payload.([]interface{})[5].(query.Function)
 		}
 
-		res.Payload = query.NewIfFunction(queryFn, ifFn, elseIfs, elseFn)
+		res.Payload = query.NewIfFunction(input, queryFn, ifFn, elseIfs, elseFn)
 		return res
 	}
 }
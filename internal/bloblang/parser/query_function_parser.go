@@ -354,6 +354,18 @@ func functionParser(pCtx Context) Func {
 		seqSlice := res.Payload.([]interface{})
 
 		targetFunc := seqSlice[0].(string)
+		if uFn, exists := pCtx.userFunctions[targetFunc]; exists {
+			parsedParams, err := extractArgsParserResult(uFn.Params(), seqSlice[1].([]interface{}))
+			if err != nil {
+				return Fail(NewFatalError(input, err), input)
+			}
+			fn, err := uFn.Init(parsedParams)
+			if err != nil {
+				return Fail(NewFatalError(input, err), input)
+			}
+			return Success(fn, res.Remaining)
+		}
+
 		params, err := pCtx.Functions.Params(targetFunc)
 		if err != nil {
 			return Fail(NewFatalError(input, err), input)
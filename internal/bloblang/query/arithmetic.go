@@ -466,6 +466,7 @@ func NewArithmeticExpression(fns []Function, ops []ArithmeticOperator) (Function
 			if fnsNew[len(fnsNew)-1], err = arithmeticFunc(leftFn, rightFn, opFunc); err != nil {
 				return nil, err
 			}
+			fnsNew[len(fnsNew)-1] = withType(fnsNew[len(fnsNew)-1], ValueNumber)
 		} else if op == ArithmeticPipe {
 			fnsNew[len(fnsNew)-1] = coalesce(leftFn, rightFn)
 		} else {
@@ -486,6 +487,10 @@ func NewArithmeticExpression(fns []Function, ops []ArithmeticOperator) (Function
 			if fnsNew[len(fnsNew)-1], err = arithmeticFunc(leftFn, rightFn, opFunc); err != nil {
 				return nil, err
 			}
+			if op == ArithmeticSub {
+				// Addition also concatenates strings.
+				fnsNew[len(fnsNew)-1] = withType(fnsNew[len(fnsNew)-1], ValueNumber)
+			}
 		} else {
 			fnsNew = append(fnsNew, rightFn)
 			opsNew = append(opsNew, op)
@@ -504,6 +509,7 @@ func NewArithmeticExpression(fns []Function, ops []ArithmeticOperator) (Function
 			if fnsNew[len(fnsNew)-1], err = arithmeticFunc(leftFn, rightFn, opFunc); err != nil {
 				return nil, err
 			}
+			fnsNew[len(fnsNew)-1] = withType(fnsNew[len(fnsNew)-1], ValueBool)
 		} else {
 			fnsNew = append(fnsNew, rightFn)
 			opsNew = append(opsNew, op)
@@ -520,9 +526,9 @@ func NewArithmeticExpression(fns []Function, ops []ArithmeticOperator) (Function
 		leftFn, rightFn := fnsNew[len(fnsNew)-1], fns[i+1]
 		switch op {
 		case ArithmeticAnd:
-			fnsNew[len(fnsNew)-1] = boolAnd(leftFn, rightFn)
+			fnsNew[len(fnsNew)-1] = withType(boolAnd(leftFn, rightFn), ValueBool)
 		case ArithmeticOr:
-			fnsNew[len(fnsNew)-1] = boolOr(leftFn, rightFn)
+			fnsNew[len(fnsNew)-1] = withType(boolOr(leftFn, rightFn), ValueBool)
 		default:
 			fnsNew = append(fnsNew, rightFn)
 			opsNew = append(opsNew, op)
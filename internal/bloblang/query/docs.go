@@ -73,6 +73,10 @@ type FunctionSpec struct {
 	// Impure indicates that a function accesses or interacts with the outter
 	// environment, and is therefore unsafe to execute in shared environments.
 	Impure bool `json:"impure"`
+
+	// OutputType is the type of value returned by the function when it is
+	// always the same, or empty when it cannot be known until execution.
+	OutputType ValueType `json:"output_type,omitempty"`
 }
 
 // NewFunctionSpec creates a new function spec.
@@ -106,6 +110,13 @@ func (s FunctionSpec) Param(def ParamDefinition) FunctionSpec {
 	return s
 }
 
+// Returns declares the type of value that the function always returns, which
+// allows mappings to be type checked without being executed.
+func (s FunctionSpec) Returns(t ValueType) FunctionSpec {
+	s.OutputType = t
+	return s
+}
+
 // NewDeprecatedFunctionSpec creates a new function spec that is deprecated.
 func NewDeprecatedFunctionSpec(name, description string, examples ...ExampleSpec) FunctionSpec {
 	return FunctionSpec{
@@ -139,6 +150,7 @@ var (
 	MethodCategoryTime           MethodCategory = "Timestamp Manipulation"
 	MethodCategoryRegexp         MethodCategory = "Regular Expressions"
 	MethodCategoryEncoding       MethodCategory = "Encoding and Encryption"
+	MethodCategoryJWT            MethodCategory = "JSON Web Tokens"
 	MethodCategoryCoercion       MethodCategory = "Type Coercion"
 	MethodCategoryParsing        MethodCategory = "Parsing"
 	MethodCategoryObjectAndArray MethodCategory = "Object & Array Manipulation"
@@ -178,6 +190,14 @@ type MethodSpec struct {
 	// Impure indicates that a method accesses or interacts with the outter
 	// environment, and is therefore unsafe to execute in shared environments.
 	Impure bool `json:"impure"`
+
+	// InputTypes are the types of value that the method can be applied to, or
+	// empty when the method accepts any type of value.
+	InputTypes []ValueType `json:"input_types,omitempty"`
+
+	// OutputType is the type of value returned by the method when it is always
+	// the same, or empty when it cannot be known until execution.
+	OutputType ValueType `json:"output_type,omitempty"`
 }
 
 // NewMethodSpec creates a new method spec.
@@ -231,6 +251,20 @@ func (m MethodSpec) Param(def ParamDefinition) MethodSpec {
 	return m
 }
 
+// Inputs declares the types of value that the method can be applied to, which
+// allows mappings to be type checked without being executed.
+func (m MethodSpec) Inputs(types ...ValueType) MethodSpec {
+	m.InputTypes = types
+	return m
+}
+
+// Returns declares the type of value that the method always returns, which
+// allows mappings to be type checked without being executed.
+func (m MethodSpec) Returns(t ValueType) MethodSpec {
+	m.OutputType = t
+	return m
+}
+
 // VariadicParams configures the method spec to allow variadic parameters.
 func (m MethodSpec) VariadicParams() MethodSpec {
 	m.Params = VariadicParams()
@@ -20,8 +20,10 @@ func NewMatchCase(caseFn, queryFn Function) MatchCase {
 }
 
 // NewMatchFunction takes a contextual mapping and a list of MatchCases, when
-// the function is executed
-func NewMatchFunction(contextFn Function, cases ...MatchCase) Function {
+// the function is executed. The input parameter is an optional slice pointing
+// to the parsed expression that created the function.
+func NewMatchFunction(input []rune, contextFn Function, cases ...MatchCase) Function {
+	expr := newMatchBranchExpression(input, cases)
 	if contextFn == nil {
 		contextFn = ClosureFunction("this", func(ctx FunctionContext) (interface{}, error) {
 			var value interface{}
@@ -43,9 +45,11 @@ func NewMatchFunction(contextFn Function, cases ...MatchCase) Function {
 				return nil, fmt.Errorf("failed to check match case %v: %w", i, err)
 			}
 			if matched, _ := caseVal.(bool); matched {
+				ctx.Tracer.recordBranch(expr, i)
 				return c.queryFn.Exec(caseCtx)
 			}
 		}
+		ctx.Tracer.recordBranch(expr, len(expr.branches)-1)
 		return Nothing(nil), nil
 	}, func(ctx TargetsContext) (TargetsContext, []TargetPath) {
 		contextCtx, contextTargets := contextFn.QueryTargets(ctx)
@@ -74,8 +78,11 @@ type ElseIf struct {
 
 // NewIfFunction creates a logical if expression from a query which should
 // return a boolean value. If the returned boolean is true then the ifFn is
-// executed and returned, otherwise elseFn is executed and returned.
-func NewIfFunction(queryFn, ifFn Function, elseIfs []ElseIf, elseFn Function) Function {
+// executed and returned, otherwise elseFn is executed and returned. The input
+// parameter is an optional slice pointing to the parsed expression that created
+// the function.
+func NewIfFunction(input []rune, queryFn, ifFn Function, elseIfs []ElseIf, elseFn Function) Function {
+	expr := newIfBranchExpression(input, len(elseIfs), elseFn != nil)
 	allFns := []Function{
 		queryFn, ifFn, elseFn,
 	}
@@ -89,6 +96,7 @@ func NewIfFunction(queryFn, ifFn Function, elseIfs []ElseIf, elseFn Function) Fu
 			return nil, fmt.Errorf("failed to check if condition: %w", err)
 		}
 		if queryRes, _ := queryVal.(bool); queryRes {
+			ctx.Tracer.recordBranch(expr, 0)
 			return ifFn.Exec(ctx)
 		}
 
@@ -98,13 +106,16 @@ func NewIfFunction(queryFn, ifFn Function, elseIfs []ElseIf, elseFn Function) Fu
 				return nil, fmt.Errorf("failed to check if condition %v: %w", i+1, err)
 			}
 			if queryRes, _ := queryVal.(bool); queryRes {
+				ctx.Tracer.recordBranch(expr, i+1)
 				return eFn.MapFn.Exec(ctx)
 			}
 		}
 
 		if elseFn != nil {
+			ctx.Tracer.recordBranch(expr, len(expr.branches)-1)
 			return elseFn.Exec(ctx)
 		}
+		ctx.Tracer.recordBranch(expr, len(expr.branches)-1)
 		return Nothing(nil), nil
 	}, aggregateTargetPaths(allFns...))
 }
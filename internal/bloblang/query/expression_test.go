@@ -32,6 +32,7 @@ func TestExpressions(t *testing.T) {
 	}{
 		"if false": {
 			input: NewIfFunction(
+				nil,
 				mustFunc(NewArithmeticExpression(
 					[]Function{
 						NewLiteralFunction("", int64(10)),
@@ -49,6 +50,7 @@ func TestExpressions(t *testing.T) {
 		},
 		"if false else": {
 			input: NewIfFunction(
+				nil,
 				mustFunc(NewArithmeticExpression(
 					[]Function{
 						NewLiteralFunction("", int64(10)),
@@ -66,6 +68,7 @@ func TestExpressions(t *testing.T) {
 		},
 		"if else if": {
 			input: NewIfFunction(
+				nil,
 				NewLiteralFunction("", false),
 				NewLiteralFunction("", "foo"),
 				[]ElseIf{
@@ -84,6 +87,7 @@ func TestExpressions(t *testing.T) {
 		},
 		"if true": {
 			input: NewIfFunction(
+				nil,
 				mustFunc(NewArithmeticExpression(
 					[]Function{
 						NewLiteralFunction("", int64(10)),
@@ -101,6 +105,7 @@ func TestExpressions(t *testing.T) {
 		},
 		"if query fails": {
 			input: NewIfFunction(
+				nil,
 				NewVarFunction("doesnt exist"),
 				NewLiteralFunction("", "foo"),
 				nil,
@@ -110,6 +115,7 @@ func TestExpressions(t *testing.T) {
 		},
 		"match context fails": {
 			input: NewMatchFunction(
+				nil,
 				NewVarFunction("doesnt exist"),
 				NewMatchCase(NewLiteralFunction("", true), NewLiteralFunction("", "foo")),
 			),
@@ -117,6 +123,7 @@ func TestExpressions(t *testing.T) {
 		},
 		"match first case fails": {
 			input: NewMatchFunction(
+				nil,
 				NewLiteralFunction("", "context"),
 				NewMatchCase(NewVarFunction("doesnt exist"), NewLiteralFunction("", "foo")),
 				NewMatchCase(NewLiteralFunction("", true), NewLiteralFunction("", "bar")),
@@ -125,6 +132,7 @@ func TestExpressions(t *testing.T) {
 		},
 		"match second case fails": {
 			input: NewMatchFunction(
+				nil,
 				NewLiteralFunction("", "context"),
 				NewMatchCase(NewLiteralFunction("", true), NewLiteralFunction("", "bar")),
 				NewMatchCase(NewVarFunction("doesnt exist"), NewLiteralFunction("", "foo")),
@@ -133,6 +141,7 @@ func TestExpressions(t *testing.T) {
 		},
 		"match context empty": {
 			input: NewMatchFunction(
+				nil,
 				nil,
 				NewMatchCase(NewLiteralFunction("", true), NewFieldFunction("")),
 			),
@@ -144,6 +153,7 @@ func TestExpressions(t *testing.T) {
 		},
 		"match context": {
 			input: NewMatchFunction(
+				nil,
 				NewLiteralFunction("", "context"),
 				NewMatchCase(NewLiteralFunction("", true), NewFieldFunction("")),
 			),
@@ -151,6 +161,7 @@ func TestExpressions(t *testing.T) {
 		},
 		"match context all fail": {
 			input: NewMatchFunction(
+				nil,
 				NewLiteralFunction("", "context"),
 				NewMatchCase(NewLiteralFunction("", false), NewLiteralFunction("", "foo")),
 				NewMatchCase(NewLiteralFunction("", false), NewLiteralFunction("", "bar")),
@@ -328,6 +339,7 @@ func TestExpressionTargets(t *testing.T) {
 		},
 		"if query path": {
 			input: NewIfFunction(
+				nil,
 				mustFunc(InitFunctionHelper("json", "foo.bar")),
 				NewLiteralFunction("", "foo"),
 				nil,
@@ -340,6 +352,7 @@ func TestExpressionTargets(t *testing.T) {
 		},
 		"if else if query path": {
 			input: NewIfFunction(
+				nil,
 				mustFunc(InitFunctionHelper("json", "foo.bar")),
 				NewLiteralFunction("", "foo"),
 				[]ElseIf{
@@ -364,6 +377,7 @@ func TestExpressionTargets(t *testing.T) {
 		},
 		"match empty context": {
 			input: NewMatchFunction(
+				nil,
 				nil,
 				NewMatchCase(
 					NewFieldFunction("foo"),
@@ -383,6 +397,7 @@ func TestExpressionTargets(t *testing.T) {
 		},
 		"match meta context": {
 			input: NewMatchFunction(
+				nil,
 				mustFunc(InitFunctionHelper("meta", "foo")),
 				NewMatchCase(
 					mustFunc(InitFunctionHelper("meta", "bar")),
@@ -402,6 +417,7 @@ func TestExpressionTargets(t *testing.T) {
 		},
 		"match value context": {
 			input: NewMatchFunction(
+				nil,
 				NewFieldFunction("foo.bar"),
 				NewMatchCase(
 					mustFunc(InitFunctionHelper("meta", "bar")),
@@ -69,10 +69,15 @@ func (f *FunctionSet) Init(name string, args *ParsedParams) (Function, error) {
 	if !exists {
 		return nil, badFunctionErr(name)
 	}
+	outputType := f.specs[name].OutputType
 	if f.disableCtors {
-		return disabledFunction(name), nil
+		return withType(disabledFunction(name), outputType), nil
 	}
-	return wrapCtorWithDynamicArgs(name, args, ctor)
+	fn, err := wrapCtorWithDynamicArgs(name, args, ctor)
+	if err != nil {
+		return nil, err
+	}
+	return withType(fn, outputType), nil
 }
 
 // Without creates a clone of the function set that can be mutated in isolation,
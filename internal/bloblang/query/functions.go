@@ -184,7 +184,7 @@ var _ = registerSimpleFunction(
 		NewExampleSpec("",
 			`root = if batch_index() > 0 { deleted() }`,
 		),
-	),
+	).Returns(ValueNumber),
 	func(ctx FunctionContext) (interface{}, error) {
 		return int64(ctx.Index), nil
 	},
@@ -199,7 +199,7 @@ var _ = registerSimpleFunction(
 		NewExampleSpec("",
 			`root.foo = batch_size()`,
 		),
-	),
+	).Returns(ValueNumber),
 	func(ctx FunctionContext) (interface{}, error) {
 		return int64(ctx.MsgBatch.Len()), nil
 	},
@@ -216,7 +216,7 @@ var _ = registerSimpleFunction(
 			`{"foo":"bar"}`,
 			`{"doc":"{\"foo\":\"bar\"}"}`,
 		),
-	),
+	).Returns(ValueBytes),
 	func(ctx FunctionContext) (interface{}, error) {
 		return ctx.MsgBatch.Get(ctx.Index).Get(), nil
 	},
@@ -236,7 +236,7 @@ root.id = count("bloblang_function_example")`,
 			`{"message":"bar"}`,
 			`{"id":2,"message":"bar"}`,
 		),
-	).Param(ParamString("name", "An identifier for the counter.")).MarkImpure(),
+	).Param(ParamString("name", "An identifier for the counter.")).MarkImpure().Returns(ValueNumber),
 	countFunction,
 )
 
@@ -395,7 +395,7 @@ root.c = range(0, -this.max, -2)`,
 	).
 		Param(ParamInt64("start", "The start value.")).
 		Param(ParamInt64("stop", "The stop value.")).
-		Param(ParamInt64("step", "The step value.").Default(1)),
+		Param(ParamInt64("step", "The step value.").Default(1)).Returns(ValueArray),
 	rangeFunction,
 )
 
@@ -440,7 +440,7 @@ var _ = registerSimpleFunction(
 		NewExampleSpec("",
 			`root.thing.host = hostname()`,
 		),
-	).MarkImpure(),
+	).MarkImpure().Returns(ValueString),
 	func(_ FunctionContext) (interface{}, error) {
 		hn, err := os.Hostname()
 		if err != nil {
@@ -646,7 +646,7 @@ root.second = random_int(1)`,
 			"seed",
 			"A seed to use, if a query is provided it will only be resolved once during the lifetime of the mapping.",
 			true,
-		).Default(NewLiteralFunction("", 0))),
+		).Default(NewLiteralFunction("", 0))).Returns(ValueNumber),
 	randomIntFunction,
 )
 
@@ -694,7 +694,7 @@ var _ = registerFunction(
 		NewExampleSpec("",
 			`root.received_at = now().format_timestamp("Mon Jan 2 15:04:05 -0700 MST 2006", "UTC")`,
 		),
-	),
+	).Returns(ValueString),
 	func(args *ParsedParams) (Function, error) {
 		return ClosureFunction("function now", func(_ FunctionContext) (interface{}, error) {
 			return time.Now().Format(time.RFC3339Nano), nil
@@ -709,7 +709,7 @@ var _ = registerSimpleFunction(
 		NewExampleSpec("",
 			`root.received_at = timestamp_unix()`,
 		),
-	),
+	).Returns(ValueNumber),
 	func(_ FunctionContext) (interface{}, error) {
 		return time.Now().Unix(), nil
 	},
@@ -722,7 +722,7 @@ var _ = registerSimpleFunction(
 		NewExampleSpec("",
 			`root.received_at = timestamp_unix_nano()`,
 		),
-	),
+	).Returns(ValueNumber),
 	func(_ FunctionContext) (interface{}, error) {
 		return time.Now().UnixNano(), nil
 	},
@@ -765,7 +765,7 @@ var _ = registerSimpleFunction(
 		FunctionCategoryGeneral, "uuid_v4",
 		"Generates a new RFC-4122 UUID each time it is invoked and prints a string representation.",
 		NewExampleSpec("", `root.id = uuid_v4()`),
-	),
+	).Returns(ValueString),
 	func(_ FunctionContext) (interface{}, error) {
 		u4, err := uuid.NewV4()
 		if err != nil {
@@ -786,7 +786,7 @@ var _ = registerFunction(
 		NewExampleSpec("It is also possible to specify an optional custom alphabet after the length parameter.", `root.id = nanoid(54, "abcde")`),
 	).
 		Param(ParamInt64("length", "An optional length.").Optional()).
-		Param(ParamString("alphabet", "An optional custom alphabet to use for generating IDs. When specified the field `length` must also be present.").Optional()),
+		Param(ParamString("alphabet", "An optional custom alphabet to use for generating IDs. When specified the field `length` must also be present.").Optional()).Returns(ValueString),
 	nanoidFunction,
 )
 
@@ -820,7 +820,7 @@ var _ = registerSimpleFunction(
 		FunctionCategoryGeneral, "ksuid",
 		"Generates a new ksuid each time it is invoked and prints a string representation.",
 		NewExampleSpec("", `root.id = ksuid()`),
-	),
+	).Returns(ValueString),
 	func(_ FunctionContext) (interface{}, error) {
 		return ksuid.New().String(), nil
 	},
@@ -0,0 +1,335 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonValuesEqual returns true if two structured values are equal, where
+// numbers are compared by value regardless of their underlying type.
+func jsonValuesEqual(lhs, rhs interface{}) bool {
+	switch l := lhs.(type) {
+	case map[string]interface{}:
+		r, ok := rhs.(map[string]interface{})
+		if !ok || len(l) != len(r) {
+			return false
+		}
+		for k, lv := range l {
+			rv, exists := r[k]
+			if !exists || !jsonValuesEqual(lv, rv) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		r, ok := rhs.([]interface{})
+		if !ok || len(l) != len(r) {
+			return false
+		}
+		for i, lv := range l {
+			if !jsonValuesEqual(lv, r[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return restrictForComparison(lhs) == restrictForComparison(rhs)
+}
+
+//------------------------------------------------------------------------------
+
+func jsonPointerEscape(segment string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(segment, "~", "~0"), "/", "~1")
+}
+
+func jsonPointerParse(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("json pointer '%v' must begin with '/'", pointer)
+	}
+	segments := strings.Split(pointer[1:], "/")
+	for i, s := range segments {
+		segments[i] = strings.ReplaceAll(strings.ReplaceAll(s, "~1", "/"), "~0", "~")
+	}
+	return segments, nil
+}
+
+// jsonPatchDiff appends to a list of JSON Patch operations that would
+// transform a value into another, where the path is the JSON pointer of the
+// values being compared.
+func jsonPatchDiff(ops []interface{}, path string, from, to interface{}) []interface{} {
+	switch f := from.(type) {
+	case map[string]interface{}:
+		t, ok := to.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(f)+len(t))
+		for k := range f {
+			keys = append(keys, k)
+		}
+		for k := range t {
+			if _, exists := f[k]; !exists {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			childPath := path + "/" + jsonPointerEscape(k)
+			fv, fExists := f[k]
+			tv, tExists := t[k]
+			switch {
+			case !tExists:
+				ops = append(ops, map[string]interface{}{"op": "remove", "path": childPath})
+			case !fExists:
+				ops = append(ops, map[string]interface{}{"op": "add", "path": childPath, "value": IClone(tv)})
+			default:
+				ops = jsonPatchDiff(ops, childPath, fv, tv)
+			}
+		}
+		return ops
+	case []interface{}:
+		t, ok := to.([]interface{})
+		if !ok {
+			break
+		}
+		i := 0
+		for ; i < len(f) && i < len(t); i++ {
+			ops = jsonPatchDiff(ops, path+"/"+strconv.Itoa(i), f[i], t[i])
+		}
+		for j := i; j < len(t); j++ {
+			ops = append(ops, map[string]interface{}{"op": "add", "path": path + "/" + strconv.Itoa(j), "value": IClone(t[j])})
+		}
+		// Remove from the end so that the indexes of remaining elements are
+		// unchanged.
+		for j := len(f) - 1; j >= i; j-- {
+			ops = append(ops, map[string]interface{}{"op": "remove", "path": path + "/" + strconv.Itoa(j)})
+		}
+		return ops
+	}
+	if !jsonValuesEqual(from, to) {
+		ops = append(ops, map[string]interface{}{"op": "replace", "path": path, "value": IClone(to)})
+	}
+	return ops
+}
+
+//------------------------------------------------------------------------------
+
+func jsonPatchArrayIndex(arr []interface{}, segment string, allowEnd bool) (int, error) {
+	if allowEnd && segment == "-" {
+		return len(arr), nil
+	}
+	i, err := strconv.Atoi(segment)
+	if err != nil || i < 0 || (segment != "0" && segment[0] == '0') {
+		return 0, fmt.Errorf("invalid array index: %v", segment)
+	}
+	max := len(arr) - 1
+	if allowEnd {
+		max = len(arr)
+	}
+	if i > max {
+		return 0, fmt.Errorf("array index %v out of bounds", i)
+	}
+	return i, nil
+}
+
+// jsonPointerGet returns the value found at a parsed JSON pointer.
+func jsonPointerGet(doc interface{}, path []string) (interface{}, error) {
+	for i, segment := range path {
+		switch t := doc.(type) {
+		case map[string]interface{}:
+			v, exists := t[segment]
+			if !exists {
+				return nil, fmt.Errorf("path /%v does not exist", strings.Join(path[:i+1], "/"))
+			}
+			doc = v
+		case []interface{}:
+			index, err := jsonPatchArrayIndex(t, segment, false)
+			if err != nil {
+				return nil, err
+			}
+			doc = t[index]
+		default:
+			return nil, fmt.Errorf("path /%v does not exist", strings.Join(path[:i+1], "/"))
+		}
+	}
+	return doc, nil
+}
+
+// jsonPatchModifyParent executes a closure on the parent of the value found at
+// a parsed JSON pointer, and returns the resulting document.
+func jsonPatchModifyParent(doc interface{}, path []string, fn func(parent interface{}, key string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return fn(doc, path[0])
+	}
+	switch t := doc.(type) {
+	case map[string]interface{}:
+		child, exists := t[path[0]]
+		if !exists {
+			return nil, fmt.Errorf("path segment %v does not exist", path[0])
+		}
+		newChild, err := jsonPatchModifyParent(child, path[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		t[path[0]] = newChild
+		return t, nil
+	case []interface{}:
+		index, err := jsonPatchArrayIndex(t, path[0], false)
+		if err != nil {
+			return nil, err
+		}
+		newChild, err := jsonPatchModifyParent(t[index], path[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		t[index] = newChild
+		return t, nil
+	}
+	return nil, fmt.Errorf("path segment %v does not exist", path[0])
+}
+
+func jsonPatchAdd(doc interface{}, path []string, value interface{}, replace bool) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return jsonPatchModifyParent(doc, path, func(parent interface{}, key string) (interface{}, error) {
+		switch t := parent.(type) {
+		case map[string]interface{}:
+			if _, exists := t[key]; replace && !exists {
+				return nil, fmt.Errorf("path segment %v does not exist", key)
+			}
+			t[key] = value
+			return t, nil
+		case []interface{}:
+			index, err := jsonPatchArrayIndex(t, key, !replace)
+			if err != nil {
+				return nil, err
+			}
+			if replace {
+				t[index] = value
+				return t, nil
+			}
+			t = append(t, nil)
+			copy(t[index+1:], t[index:])
+			t[index] = value
+			return t, nil
+		}
+		return nil, NewTypeError(parent, ValueObject, ValueArray)
+	})
+}
+
+func jsonPatchRemove(doc interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, errors.New("cannot remove the root of a document")
+	}
+	return jsonPatchModifyParent(doc, path, func(parent interface{}, key string) (interface{}, error) {
+		switch t := parent.(type) {
+		case map[string]interface{}:
+			if _, exists := t[key]; !exists {
+				return nil, fmt.Errorf("path segment %v does not exist", key)
+			}
+			delete(t, key)
+			return t, nil
+		case []interface{}:
+			index, err := jsonPatchArrayIndex(t, key, false)
+			if err != nil {
+				return nil, err
+			}
+			return append(t[:index], t[index+1:]...), nil
+		}
+		return nil, NewTypeError(parent, ValueObject, ValueArray)
+	})
+}
+
+func jsonPatchOpPointer(op map[string]interface{}, field string) ([]string, error) {
+	v, exists := op[field]
+	if !exists {
+		return nil, fmt.Errorf("missing field %v", field)
+	}
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("field %v: %w", field, NewTypeError(v, ValueString))
+	}
+	return jsonPointerParse(s)
+}
+
+// jsonPatchApply applies a single JSON Patch operation to a document and
+// returns the result. The document may be modified in place.
+func jsonPatchApply(doc interface{}, op map[string]interface{}) (interface{}, error) {
+	path, err := jsonPatchOpPointer(op, "path")
+	if err != nil {
+		return nil, err
+	}
+
+	opName, _ := op["op"].(string)
+	switch opName {
+	case "add", "replace", "test":
+		value, exists := op["value"]
+		if !exists {
+			return nil, errors.New("missing field value")
+		}
+		value = IClone(value)
+		if opName == "test" {
+			current, err := jsonPointerGet(doc, path)
+			if err != nil {
+				return nil, err
+			}
+			if !jsonValuesEqual(current, value) {
+				return nil, errors.New("test failed, value does not match")
+			}
+			return doc, nil
+		}
+		return jsonPatchAdd(doc, path, value, opName == "replace")
+	case "remove":
+		return jsonPatchRemove(doc, path)
+	case "move", "copy":
+		from, err := jsonPatchOpPointer(op, "from")
+		if err != nil {
+			return nil, err
+		}
+		value, err := jsonPointerGet(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		if opName == "copy" {
+			return jsonPatchAdd(doc, path, IClone(value), false)
+		}
+		if len(path) > len(from) && strings.Join(path[:len(from)], "/") == strings.Join(from, "/") {
+			return nil, errors.New("cannot move a value into one of its children")
+		}
+		if doc, err = jsonPatchRemove(doc, from); err != nil {
+			return nil, err
+		}
+		return jsonPatchAdd(doc, path, value, false)
+	}
+	return nil, fmt.Errorf("unrecognised operation: %v", op["op"])
+}
+
+//------------------------------------------------------------------------------
+
+// jsonMergePatch applies a merge patch as described in RFC 7396 to a target
+// value. The target may be modified in place.
+func jsonMergePatch(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = map[string]interface{}{}
+	}
+	for k, v := range patchObj {
+		if v == nil {
+			delete(targetObj, k)
+			continue
+		}
+		targetObj[k] = jsonMergePatch(targetObj[k], v)
+	}
+	return targetObj
+}
@@ -0,0 +1,240 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONPatchDiffRoundTrip(t *testing.T) {
+	testCases := []struct {
+		name string
+		from interface{}
+		to   interface{}
+		ops  []interface{}
+	}{
+		{
+			name: "identical",
+			from: map[string]interface{}{"a": int64(1), "b": []interface{}{"c"}},
+			to:   map[string]interface{}{"a": 1.0, "b": []interface{}{"c"}},
+			ops:  []interface{}{},
+		},
+		{
+			name: "root replaced",
+			from: "foo",
+			to:   map[string]interface{}{"a": "b"},
+			ops: []interface{}{
+				map[string]interface{}{"op": "replace", "path": "", "value": map[string]interface{}{"a": "b"}},
+			},
+		},
+		{
+			name: "escaped keys",
+			from: map[string]interface{}{"a/b": "foo", "c~d": "bar"},
+			to:   map[string]interface{}{"a/b": "baz"},
+			ops: []interface{}{
+				map[string]interface{}{"op": "replace", "path": "/a~1b", "value": "baz"},
+				map[string]interface{}{"op": "remove", "path": "/c~0d"},
+			},
+		},
+		{
+			name: "arrays shrink",
+			from: []interface{}{"a", "b", "c", "d"},
+			to:   []interface{}{"a", "x"},
+			ops: []interface{}{
+				map[string]interface{}{"op": "replace", "path": "/1", "value": "x"},
+				map[string]interface{}{"op": "remove", "path": "/3"},
+				map[string]interface{}{"op": "remove", "path": "/2"},
+			},
+		},
+		{
+			name: "arrays grow",
+			from: map[string]interface{}{"a": []interface{}{}},
+			to:   map[string]interface{}{"a": []interface{}{"b", map[string]interface{}{"c": "d"}}},
+			ops: []interface{}{
+				map[string]interface{}{"op": "add", "path": "/a/0", "value": "b"},
+				map[string]interface{}{"op": "add", "path": "/a/1", "value": map[string]interface{}{"c": "d"}},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			diffFn, err := InitMethodHelper("diff", NewLiteralFunction("", IClone(test.from)), IClone(test.to))
+			require.NoError(t, err)
+
+			ops, err := diffFn.Exec(FunctionContext{})
+			require.NoError(t, err)
+			assert.Equal(t, test.ops, ops)
+
+			patchFn, err := InitMethodHelper("patch", NewLiteralFunction("", IClone(test.from)), ops)
+			require.NoError(t, err)
+
+			res, err := patchFn.Exec(FunctionContext{})
+			require.NoError(t, err)
+			assert.True(t, jsonValuesEqual(test.to, res), "%v != %v", test.to, res)
+		})
+	}
+}
+
+func TestJSONPatchErrors(t *testing.T) {
+	doc := map[string]interface{}{
+		"a": []interface{}{"b", "c"},
+		"d": map[string]interface{}{"e": "f"},
+	}
+
+	testCases := []struct {
+		name string
+		op   map[string]interface{}
+		err  string
+	}{
+		{
+			name: "unknown op",
+			op:   map[string]interface{}{"op": "nope", "path": "/a"},
+			err:  "operation 0: unrecognised operation: nope",
+		},
+		{
+			name: "bad pointer",
+			op:   map[string]interface{}{"op": "remove", "path": "a"},
+			err:  "operation 0: json pointer 'a' must begin with '/'",
+		},
+		{
+			name: "remove missing",
+			op:   map[string]interface{}{"op": "remove", "path": "/d/nope"},
+			err:  "operation 0: path segment nope does not exist",
+		},
+		{
+			name: "replace missing",
+			op:   map[string]interface{}{"op": "replace", "path": "/nope", "value": "foo"},
+			err:  "operation 0: path segment nope does not exist",
+		},
+		{
+			name: "add out of bounds",
+			op:   map[string]interface{}{"op": "add", "path": "/a/3", "value": "foo"},
+			err:  "operation 0: array index 3 out of bounds",
+		},
+		{
+			name: "add bad index",
+			op:   map[string]interface{}{"op": "add", "path": "/a/01", "value": "foo"},
+			err:  "operation 0: invalid array index: 01",
+		},
+		{
+			name: "test fails",
+			op:   map[string]interface{}{"op": "test", "path": "/d/e", "value": "g"},
+			err:  "operation 0: test failed, value does not match",
+		},
+		{
+			name: "move into child",
+			op:   map[string]interface{}{"op": "move", "from": "/d", "path": "/d/e/f"},
+			err:  "operation 0: cannot move a value into one of its children",
+		},
+		{
+			name: "missing value",
+			op:   map[string]interface{}{"op": "add", "path": "/g"},
+			err:  "operation 0: missing field value",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			fn, err := InitMethodHelper("patch", NewLiteralFunction("", IClone(doc)), []interface{}{test.op})
+			require.NoError(t, err)
+
+			_, err = fn.Exec(FunctionContext{})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.err)
+		})
+	}
+}
+
+func TestJSONPatchOperations(t *testing.T) {
+	doc := map[string]interface{}{
+		"a": []interface{}{"b", "c"},
+		"d": map[string]interface{}{"e": "f"},
+	}
+
+	fn, err := InitMethodHelper("patch", NewLiteralFunction("", doc), []interface{}{
+		map[string]interface{}{"op": "test", "path": "/d/e", "value": "f"},
+		map[string]interface{}{"op": "add", "path": "/a/1", "value": "x"},
+		map[string]interface{}{"op": "move", "from": "/d/e", "path": "/a/0"},
+		map[string]interface{}{"op": "copy", "from": "/a", "path": "/g"},
+		map[string]interface{}{"op": "remove", "path": "/a/-"},
+	})
+	require.NoError(t, err)
+
+	_, err = fn.Exec(FunctionContext{})
+	require.Error(t, err, "the end of array index is only valid for add operations")
+
+	fn, err = InitMethodHelper("patch", NewLiteralFunction("", doc), []interface{}{
+		map[string]interface{}{"op": "test", "path": "/d/e", "value": "f"},
+		map[string]interface{}{"op": "add", "path": "/a/1", "value": "x"},
+		map[string]interface{}{"op": "move", "from": "/d/e", "path": "/a/0"},
+		map[string]interface{}{"op": "copy", "from": "/a", "path": "/g"},
+		map[string]interface{}{"op": "remove", "path": "/a/3"},
+	})
+	require.NoError(t, err)
+
+	res, err := fn.Exec(FunctionContext{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"a": []interface{}{"f", "b", "x"},
+		"d": map[string]interface{}{},
+		"g": []interface{}{"f", "b", "x", "c"},
+	}, res)
+
+	// The target must not be modified
+	assert.Equal(t, map[string]interface{}{
+		"a": []interface{}{"b", "c"},
+		"d": map[string]interface{}{"e": "f"},
+	}, doc)
+}
+
+func TestJSONMergePatch(t *testing.T) {
+	testCases := []struct {
+		target interface{}
+		patch  interface{}
+		exp    interface{}
+	}{
+		{
+			target: map[string]interface{}{"a": "b"},
+			patch:  map[string]interface{}{"a": "c"},
+			exp:    map[string]interface{}{"a": "c"},
+		},
+		{
+			target: map[string]interface{}{"a": "b", "b": "c"},
+			patch:  map[string]interface{}{"a": nil},
+			exp:    map[string]interface{}{"b": "c"},
+		},
+		{
+			target: map[string]interface{}{"a": []interface{}{"b"}},
+			patch:  map[string]interface{}{"a": "c"},
+			exp:    map[string]interface{}{"a": "c"},
+		},
+		{
+			target: []interface{}{"a", "b"},
+			patch:  map[string]interface{}{"a": "b", "c": nil},
+			exp:    map[string]interface{}{"a": "b"},
+		},
+		{
+			target: map[string]interface{}{"e": nil},
+			patch:  map[string]interface{}{"a": map[string]interface{}{"bb": map[string]interface{}{"ccc": nil}}},
+			exp:    map[string]interface{}{"e": nil, "a": map[string]interface{}{"bb": map[string]interface{}{}}},
+		},
+		{
+			target: map[string]interface{}{"a": "foo"},
+			patch:  "bar",
+			exp:    "bar",
+		},
+	}
+
+	for i, test := range testCases {
+		fn, err := InitMethodHelper("merge_patch", NewLiteralFunction("", test.target), test.patch)
+		require.NoError(t, err)
+
+		res, err := fn.Exec(FunctionContext{})
+		require.NoError(t, err)
+		assert.Equal(t, test.exp, res, i)
+	}
+}
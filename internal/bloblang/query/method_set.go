@@ -65,10 +65,15 @@ func (m *MethodSet) Init(name string, target Function, args *ParsedParams) (Func
 	if !exists {
 		return nil, badMethodErr(name)
 	}
+	outputType := m.specs[name].OutputType
 	if m.disableCtors {
-		return disabledMethod(name), nil
+		return withType(disabledMethod(name), outputType), nil
 	}
-	return wrapMethodCtorWithDynamicArgs(name, target, args, ctor)
+	fn, err := wrapMethodCtorWithDynamicArgs(name, target, args, ctor)
+	if err != nil {
+		return nil, err
+	}
+	return withType(fn, outputType), nil
 }
 
 // Without creates a clone of the method set that can be mutated in isolation,
@@ -84,7 +84,7 @@ var _ = registerMethod(
 			`root.foo = this.thing.bool()
 root.bar = this.thing.bool(true)`,
 		),
-	).Param(ParamBool("default", "An optional value to yield if the target cannot be parsed as a boolean.").Optional()),
+	).Param(ParamBool("default", "An optional value to yield if the target cannot be parsed as a boolean.").Optional()).Returns(ValueBool),
 	boolMethod,
 )
 
@@ -417,7 +417,7 @@ var _ = registerMethod(
 			`root.foo = this.thing.number() + 10
 root.bar = this.thing.number(5) * 10`,
 		),
-	).Param(ParamFloat("default", "An optional value to yield if the target cannot be parsed as a number.").Optional()),
+	).Param(ParamFloat("default", "An optional value to yield if the target cannot be parsed as a number.").Optional()).Returns(ValueNumber),
 	numberCoerceMethod,
 )
 
@@ -483,7 +483,7 @@ root.foo_type = this.foo.type()`,
 			`{"bar":10,"foo":"is a string"}`,
 			`{"bar_type":"number","foo_type":"string"}`,
 		),
-	),
+	).Returns(ValueString),
 	func(*ParsedParams) (simpleMethod, error) {
 		return func(v interface{}, ctx FunctionContext) (interface{}, error) {
 			return string(ITypeOf(v)), nil
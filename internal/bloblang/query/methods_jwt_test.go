@@ -0,0 +1,111 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWTParseErrors(t *testing.T) {
+	secret := "dont-tell-anyone"
+
+	signHS256 := func(claims jwt.MapClaims) string {
+		s, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+		require.NoError(t, err)
+		return s
+	}
+
+	testCases := []struct {
+		name        string
+		method      string
+		target      interface{}
+		args        []interface{}
+		errContains string
+	}{
+		{
+			name:        "wrong secret",
+			method:      "parse_jwt_hs256",
+			target:      signHS256(jwt.MapClaims{"sub": "foo"}),
+			args:        []interface{}{"not the secret"},
+			errContains: "signature is invalid",
+		},
+		{
+			name:        "expired token",
+			method:      "parse_jwt_hs256",
+			target:      signHS256(jwt.MapClaims{"sub": "foo", "exp": time.Now().Add(-time.Hour).Unix()}),
+			args:        []interface{}{secret},
+			errContains: "Token is expired",
+		},
+		{
+			name:        "token not yet valid",
+			method:      "parse_jwt_hs256",
+			target:      signHS256(jwt.MapClaims{"sub": "foo", "nbf": time.Now().Add(time.Hour).Unix()}),
+			args:        []interface{}{secret},
+			errContains: "Token is not valid yet",
+		},
+		{
+			name:        "unexpected signing method",
+			method:      "parse_jwt_hs256",
+			target:      "eyJhbGciOiJub25lIiwidHlwIjoiSldUIn0.eyJzdWIiOiJmb28ifQ.",
+			args:        []interface{}{secret},
+			errContains: "signing method none is invalid",
+		},
+		{
+			name:        "malformed token",
+			method:      "parse_jwt_hs256",
+			target:      "not a token",
+			args:        []interface{}{secret},
+			errContains: "token contains an invalid number of segments",
+		},
+		{
+			name:        "not a string",
+			method:      "parse_jwt_hs256",
+			target:      int64(10),
+			args:        []interface{}{secret},
+			errContains: "expected string value, got number",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			fn, err := InitMethodHelper(test.method, NewLiteralFunction("", test.target), test.args...)
+			require.NoError(t, err)
+
+			_, err = fn.Exec(FunctionContext{})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.errContains)
+		})
+	}
+}
+
+func TestJWTSignAndParse(t *testing.T) {
+	claims := map[string]interface{}{
+		"sub":  "foo",
+		"name": "bar",
+	}
+
+	signFn, err := InitMethodHelper("sign_jwt_hs256", NewLiteralFunction("", claims), "dont-tell-anyone")
+	require.NoError(t, err)
+
+	parseFn, err := InitMethodHelper("parse_jwt_hs256", signFn, "dont-tell-anyone")
+	require.NoError(t, err)
+
+	res, err := parseFn.Exec(FunctionContext{})
+	require.NoError(t, err)
+	assert.Equal(t, claims, res)
+
+	_, err = InitMethodHelper("sign_jwt_rs256", NewLiteralFunction("", claims), "not a pem key")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse signing secret")
+
+	signFn, err = InitMethodHelper("sign_jwt_hs256", NewLiteralFunction("", "not an object"), "dont-tell-anyone")
+	require.NoError(t, err)
+
+	_, err = signFn.Exec(FunctionContext{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected object value, got string")
+}
@@ -16,7 +16,7 @@ var _ = registerSimpleMethod(
 			`{"value":-5.9}`,
 			`{"new_value":5.9}`,
 		),
-	),
+	).Inputs(ValueNumber).Returns(ValueNumber),
 	func(*ParsedParams) (simpleMethod, error) {
 		return numberMethod(func(f *float64, i *int64, ui *uint64) (interface{}, error) {
 			var v float64
@@ -42,7 +42,7 @@ var _ = registerSimpleMethod(
 			`{"value":-5.9}`,
 			`{"new_value":-5}`,
 		),
-	),
+	).Inputs(ValueNumber).Returns(ValueNumber),
 	func(*ParsedParams) (simpleMethod, error) {
 		return numberMethod(func(f *float64, i *int64, ui *uint64) (interface{}, error) {
 			if f != nil {
@@ -67,7 +67,7 @@ var _ = registerSimpleMethod(
 			`{"value":5.7}`,
 			`{"new_value":5}`,
 		),
-	),
+	).Inputs(ValueNumber).Returns(ValueNumber),
 	func(*ParsedParams) (simpleMethod, error) {
 		return numberMethod(func(f *float64, i *int64, ui *uint64) (interface{}, error) {
 			if f != nil {
@@ -91,7 +91,7 @@ var _ = registerSimpleMethod(
 			`{"value":2.7183}`,
 			`{"new_value":1}`,
 		),
-	),
+	).Inputs(ValueNumber).Returns(ValueNumber),
 	func(*ParsedParams) (simpleMethod, error) {
 		return numberMethod(func(f *float64, i *int64, ui *uint64) (interface{}, error) {
 			var v float64
@@ -117,7 +117,7 @@ var _ = registerSimpleMethod(
 			`{"value":1000}`,
 			`{"new_value":3}`,
 		),
-	),
+	).Inputs(ValueNumber).Returns(ValueNumber),
 	func(*ParsedParams) (simpleMethod, error) {
 		return numberMethod(func(f *float64, i *int64, ui *uint64) (interface{}, error) {
 			var v float64
@@ -151,7 +151,7 @@ var _ = registerSimpleMethod(
 			`{"value":7}`,
 			`{"new_value":7}`,
 		),
-	),
+	).Inputs(ValueArray).Returns(ValueNumber),
 	func(*ParsedParams) (simpleMethod, error) {
 		return func(v interface{}, ctx FunctionContext) (interface{}, error) {
 			arr, ok := v.([]interface{})
@@ -194,7 +194,7 @@ var _ = registerSimpleMethod(
 			`{"value":23}`,
 			`{"new_value":10}`,
 		),
-	),
+	).Inputs(ValueArray).Returns(ValueNumber),
 	func(*ParsedParams) (simpleMethod, error) {
 		return func(v interface{}, ctx FunctionContext) (interface{}, error) {
 			arr, ok := v.([]interface{})
@@ -232,7 +232,7 @@ var _ = registerSimpleMethod(
 			`{"value":5.9}`,
 			`{"new_value":6}`,
 		),
-	),
+	).Inputs(ValueNumber).Returns(ValueNumber),
 	func(*ParsedParams) (simpleMethod, error) {
 		return numberMethod(func(f *float64, i *int64, ui *uint64) (interface{}, error) {
 			if f != nil {
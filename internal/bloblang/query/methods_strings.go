@@ -46,7 +46,7 @@ var _ = registerSimpleMethod(
 			`{"name":"foobar bazson"}`,
 			`{"first_byte":102}`,
 		),
-	),
+	).Returns(ValueBytes),
 	func(*ParsedParams) (simpleMethod, error) {
 		return func(v interface{}, ctx FunctionContext) (interface{}, error) {
 			return IToBytes(v), nil
@@ -67,7 +67,7 @@ var _ = registerSimpleMethod(
 			`{"title":"the foo bar"}`,
 			`{"title":"The Foo Bar"}`,
 		),
-	),
+	).Inputs(ValueString, ValueBytes),
 	func(*ParsedParams) (simpleMethod, error) {
 		return func(v interface{}, ctx FunctionContext) (interface{}, error) {
 			switch t := v.(type) {
@@ -445,7 +445,7 @@ var _ = registerSimpleMethod(
 			`{"value":"foo & bar"}`,
 			`{"escaped":"foo &amp; bar"}`,
 		),
-	),
+	).Inputs(ValueString, ValueBytes).Returns(ValueString),
 	func(*ParsedParams) (simpleMethod, error) {
 		return stringMethod(func(s string) (interface{}, error) {
 			return html.EscapeString(s), nil
@@ -471,7 +471,7 @@ var _ = registerSimpleMethod(
 			`the cat meowed, the dog woofed`,
 			`{"index":8}`,
 		),
-	).Param(ParamString("value", "A string to search for.")),
+	).Param(ParamString("value", "A string to search for.")).Inputs(ValueString, ValueBytes).Returns(ValueNumber),
 	func(args *ParsedParams) (simpleMethod, error) {
 		substring, err := args.FieldString("value")
 		if err != nil {
@@ -502,7 +502,7 @@ var _ = registerSimpleMethod(
 			`{"value":"foo &amp; bar"}`,
 			`{"unescaped":"foo & bar"}`,
 		),
-	),
+	).Inputs(ValueString, ValueBytes).Returns(ValueString),
 	func(*ParsedParams) (simpleMethod, error) {
 		return stringMethod(func(s string) (interface{}, error) {
 			return html.UnescapeString(s), nil
@@ -523,7 +523,7 @@ var _ = registerSimpleMethod(
 			`{"value":"foo & bar"}`,
 			`{"escaped":"foo+%26+bar"}`,
 		),
-	),
+	).Inputs(ValueString, ValueBytes).Returns(ValueString),
 	func(*ParsedParams) (simpleMethod, error) {
 		return stringMethod(func(s string) (interface{}, error) {
 			return url.QueryEscape(s), nil
@@ -544,7 +544,7 @@ var _ = registerSimpleMethod(
 			`{"value":"foo+%26+bar"}`,
 			`{"unescaped":"foo & bar"}`,
 		),
-	),
+	).Inputs(ValueString, ValueBytes).Returns(ValueString),
 	func(*ParsedParams) (simpleMethod, error) {
 		return stringMethod(func(s string) (interface{}, error) {
 			return url.QueryUnescape(s)
@@ -642,7 +642,7 @@ root.t2 = this.v2.has_prefix("foo")`,
 			`{"v1":"foobar","v2":"barfoo"}`,
 			`{"t1":true,"t2":false}`,
 		),
-	).Param(ParamString("value", "The string to test.")),
+	).Param(ParamString("value", "The string to test.")).Inputs(ValueString, ValueBytes).Returns(ValueBool),
 	func(args *ParsedParams) (simpleMethod, error) {
 		prefix, err := args.FieldString("value")
 		if err != nil {
@@ -675,7 +675,7 @@ root.t2 = this.v2.has_suffix("foo")`,
 			`{"v1":"foobar","v2":"barfoo"}`,
 			`{"t1":false,"t2":true}`,
 		),
-	).Param(ParamString("value", "The string to test.")),
+	).Param(ParamString("value", "The string to test.")).Inputs(ValueString, ValueBytes).Returns(ValueBool),
 	func(args *ParsedParams) (simpleMethod, error) {
 		suffix, err := args.FieldString("value")
 		if err != nil {
@@ -821,7 +821,7 @@ root.joined_numbers = this.numbers.map_each(this.string()).join(",")`,
 			`{"words":["hello","world"],"numbers":[3,8,11]}`,
 			`{"joined_numbers":"3,8,11","joined_words":"helloworld"}`,
 		),
-	).Param(ParamString("delimiter", "An optional delimiter to add between each string.").Optional()),
+	).Param(ParamString("delimiter", "An optional delimiter to add between each string.").Optional()).Inputs(ValueArray).Returns(ValueString),
 	func(args *ParsedParams) (simpleMethod, error) {
 		delimArg, err := args.FieldOptionalString("delimiter")
 		if err != nil {
@@ -869,7 +869,7 @@ var _ = registerSimpleMethod(
 			`{"foo":"hello world"}`,
 			`{"foo":"HELLO WORLD"}`,
 		),
-	),
+	).Inputs(ValueString, ValueBytes),
 	func(*ParsedParams) (simpleMethod, error) {
 		return func(v interface{}, ctx FunctionContext) (interface{}, error) {
 			switch t := v.(type) {
@@ -897,7 +897,7 @@ var _ = registerSimpleMethod(
 			`{"foo":"HELLO WORLD"}`,
 			`{"foo":"hello world"}`,
 		),
-	),
+	).Inputs(ValueString, ValueBytes),
 	func(*ParsedParams) (simpleMethod, error) {
 		return func(v interface{}, ctx FunctionContext) (interface{}, error) {
 			switch t := v.(type) {
@@ -983,7 +983,7 @@ var _ = registerSimpleMethod(
 			`{"doc":"{\"foo\":\"bar\"}"}`,
 			`{"doc":{"foo":"bar"}}`,
 		),
-	),
+	).Inputs(ValueString, ValueBytes),
 	func(*ParsedParams) (simpleMethod, error) {
 		return func(v interface{}, ctx FunctionContext) (interface{}, error) {
 			var jsonBytes []byte
@@ -1550,7 +1550,7 @@ var _ = registerSimpleMethod(
 			`{"thing":"foo\nbar"}`,
 			`{"quoted":"\"foo\\nbar\""}`,
 		),
-	),
+	).Inputs(ValueString, ValueBytes).Returns(ValueString),
 	func(*ParsedParams) (simpleMethod, error) {
 		return stringMethod(func(s string) (interface{}, error) {
 			return strconv.Quote(s), nil
@@ -1571,7 +1571,7 @@ var _ = registerSimpleMethod(
 			`{"thing":"\"foo\\nbar\""}`,
 			`{"unquoted":"foo\nbar"}`,
 		),
-	),
+	).Inputs(ValueString, ValueBytes).Returns(ValueString),
 	func(*ParsedParams) (simpleMethod, error) {
 		return stringMethod(func(s string) (interface{}, error) {
 			return strconv.Unquote(s)
@@ -1709,7 +1709,7 @@ var _ = registerSimpleMethod(
 			`{"value":"paranormal"}`,
 			`{"matches":["ar","an","al"]}`,
 		),
-	).Param(ParamString("pattern", "The pattern to match against.")),
+	).Param(ParamString("pattern", "The pattern to match against.")).Inputs(ValueString, ValueBytes).Returns(ValueArray),
 	func(args *ParsedParams) (simpleMethod, error) {
 		reStr, err := args.FieldString("pattern")
 		if err != nil {
@@ -1755,7 +1755,7 @@ var _ = registerSimpleMethod(
 			`{"value":"-axxb-ab-"}`,
 			`{"matches":[["axxb","xx"],["ab",""]]}`,
 		),
-	).Param(ParamString("pattern", "The pattern to match against.")),
+	).Param(ParamString("pattern", "The pattern to match against.")).Inputs(ValueString, ValueBytes).Returns(ValueArray),
 	func(args *ParsedParams) (simpleMethod, error) {
 		reStr, err := args.FieldString("pattern")
 		if err != nil {
@@ -1814,7 +1814,7 @@ var _ = registerSimpleMethod(
 			`{"value":"option1: value1"}`,
 			`{"matches":{"0":"option1: value1","key":"option1","value":"value1"}}`,
 		),
-	).Param(ParamString("pattern", "The pattern to match against.")),
+	).Param(ParamString("pattern", "The pattern to match against.")).Inputs(ValueString, ValueBytes).Returns(ValueObject),
 	func(args *ParsedParams) (simpleMethod, error) {
 		reStr, err := args.FieldString("pattern")
 		if err != nil {
@@ -1871,7 +1871,7 @@ var _ = registerSimpleMethod(
 			`{"value":"option1: value1\noption2: value2\noption3: value3"}`,
 			`{"matches":[{"0":"option1: value1","key":"option1","value":"value1"},{"0":"option2: value2","key":"option2","value":"value2"},{"0":"option3: value3","key":"option3","value":"value3"}]}`,
 		),
-	).Param(ParamString("pattern", "The pattern to match against.")),
+	).Param(ParamString("pattern", "The pattern to match against.")).Inputs(ValueString, ValueBytes).Returns(ValueArray),
 	func(args *ParsedParams) (simpleMethod, error) {
 		reStr, err := args.FieldString("pattern")
 		if err != nil {
@@ -1935,7 +1935,7 @@ var _ = registerSimpleMethod(
 			`{"value":"there are ten puppies"}`,
 			`{"matches":false}`,
 		),
-	).Param(ParamString("pattern", "The pattern to match against.")),
+	).Param(ParamString("pattern", "The pattern to match against.")).Inputs(ValueString, ValueBytes).Returns(ValueBool),
 	func(args *ParsedParams) (simpleMethod, error) {
 		reStr, err := args.FieldString("pattern")
 		if err != nil {
@@ -2027,7 +2027,7 @@ var _ = registerSimpleMethod(
 			`{"value":"foo,bar,baz"}`,
 			`{"new_value":["foo","bar","baz"]}`,
 		),
-	).Param(ParamString("delimiter", "The delimiter to split with.")),
+	).Param(ParamString("delimiter", "The delimiter to split with.")).Inputs(ValueString, ValueBytes).Returns(ValueArray),
 	func(args *ParsedParams) (simpleMethod, error) {
 		delim, err := args.FieldString("delimiter")
 		if err != nil {
@@ -2074,7 +2074,7 @@ var _ = registerSimpleMethod(
 			`{"id":228930314431312345}`,
 			`{"id":"228930314431312345"}`,
 		),
-	),
+	).Returns(ValueString),
 	func(*ParsedParams) (simpleMethod, error) {
 		return func(v interface{}, ctx FunctionContext) (interface{}, error) {
 			return IToString(v), nil
@@ -2100,7 +2100,7 @@ var _ = registerSimpleMethod(
 			`{"value":"<article><p>the plain <strong>old text</strong></p></article>"}`,
 			`{"stripped":"<article>the plain old text</article>"}`,
 		),
-	).Param(ParamArray("preserve", "An optional array of element types to preserve in the output.").Optional()),
+	).Param(ParamArray("preserve", "An optional array of element types to preserve in the output.").Optional()).Inputs(ValueString, ValueBytes),
 	func(args *ParsedParams) (simpleMethod, error) {
 		p := bluemonday.NewPolicy()
 		tags, err := args.FieldOptionalArray("preserve")
@@ -2143,7 +2143,7 @@ root.description = this.description.trim()`,
 			`{"description":"  something happened and its amazing! ","title":"!!!watch out!?"}`,
 			`{"description":"something happened and its amazing!","title":"watch out"}`,
 		),
-	).Param(ParamString("cutset", "An optional string of characters to trim from the target value.").Optional()),
+	).Param(ParamString("cutset", "An optional string of characters to trim from the target value.").Optional()).Inputs(ValueString, ValueBytes),
 	func(args *ParsedParams) (simpleMethod, error) {
 		cutset, err := args.FieldOptionalString("cutset")
 		if err != nil {
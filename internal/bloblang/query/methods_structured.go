@@ -26,7 +26,7 @@ var _ = registerSimpleMethod(
 			`{"patrons":[{"id":"1","age":45},{"id":"2","age":23}]}`,
 			`{"all_over_21":true}`,
 		),
-	).Param(ParamQuery("test", "A test query to apply to each element.", false)),
+	).Param(ParamQuery("test", "A test query to apply to each element.", false)).Inputs(ValueArray).Returns(ValueBool),
 	func(args *ParsedParams) (simpleMethod, error) {
 		queryFn, err := args.FieldQuery("test")
 		if err != nil {
@@ -72,7 +72,7 @@ var _ = registerSimpleMethod(
 			`{"patrons":[{"id":"1","age":10},{"id":"2","age":12}]}`,
 			`{"any_over_21":false}`,
 		),
-	).Param(ParamQuery("test", "A test query to apply to each element.", false)),
+	).Param(ParamQuery("test", "A test query to apply to each element.", false)).Inputs(ValueArray).Returns(ValueBool),
 	func(args *ParsedParams) (simpleMethod, error) {
 		queryFn, err := args.FieldQuery("test")
 		if err != nil {
@@ -203,7 +203,7 @@ var _ = registerSimpleMethod(
 			`{"thing":"this bar that"}`,
 			`{"has_foo":false}`,
 		),
-	).Param(ParamAny("value", "A value to test against elements of the target.")),
+	).Param(ParamAny("value", "A value to test against elements of the target.")).Inputs(ValueString, ValueBytes, ValueArray, ValueObject).Returns(ValueBool),
 	func(args *ParsedParams) (simpleMethod, error) {
 		compareRight, err := args.Field("value")
 		if err != nil {
@@ -250,6 +250,36 @@ var _ = registerSimpleMethod(
 
 //------------------------------------------------------------------------------
 
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"diff", "",
+	).InCategory(
+		MethodCategoryObjectAndArray,
+		"Compares the target value with another and returns an array of [JSON Patch (RFC 6902)](https://datatracker.ietf.org/doc/html/rfc6902) operations that would transform the target into the other value. Objects are compared field by field and arrays are compared element by element, with any other differing values resulting in a `replace` operation. The result can be applied to the target with the [`patch`](#patch) method.",
+		NewExampleSpec("",
+			`root = this.before.diff(this.after)`,
+			`{"before":{"name":"foo","tags":["a","b"],"meta":{"v":1}},"after":{"name":"bar","tags":["a"],"meta":{"v":1},"id":5}}`,
+			`[{"op":"add","path":"/id","value":5},{"op":"replace","path":"/name","value":"bar"},{"op":"remove","path":"/tags/1"}]`,
+		),
+		NewExampleSpec("Emit only the fields of a document that have changed since the previous version.",
+			`root.changes = this.previous.diff(this.current).map_each(op -> op.path)`,
+			`{"previous":{"a":1,"b":2},"current":{"a":1,"b":3}}`,
+			`{"changes":["/b"]}`,
+		),
+	).Param(ParamAny("other", "The value to compare the target against.")).Returns(ValueArray),
+	func(args *ParsedParams) (simpleMethod, error) {
+		other, err := args.Field("other")
+		if err != nil {
+			return nil, err
+		}
+		return func(v interface{}, ctx FunctionContext) (interface{}, error) {
+			return jsonPatchDiff([]interface{}{}, "", v, other), nil
+		}, nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"enumerated",
@@ -442,7 +472,7 @@ var _ = registerSimpleMethod(
 			`["foo",["bar","baz"],"buz"]`,
 			`{"result":["foo","bar","baz","buz"]}`,
 		),
-	),
+	).Inputs(ValueArray).Returns(ValueArray),
 	func(*ParsedParams) (simpleMethod, error) {
 		return func(v interface{}, ctx FunctionContext) (interface{}, error) {
 			array, isArray := v.([]interface{})
@@ -645,7 +675,7 @@ var _ = registerSimpleMethod(
 			`{"foo":{"bar":1,"baz":2}}`,
 			`{"foo_keys":["bar","baz"]}`,
 		),
-	),
+	).Inputs(ValueObject).Returns(ValueArray),
 	func(*ParsedParams) (simpleMethod, error) {
 		return func(v interface{}, ctx FunctionContext) (interface{}, error) {
 			if m, ok := v.(map[string]interface{}); ok {
@@ -675,7 +705,7 @@ var _ = registerSimpleMethod(
 			`{"foo":{"bar":1,"baz":2}}`,
 			`{"foo_key_values":[{"key":"bar","value":1},{"key":"baz","value":2}]}`,
 		),
-	),
+	).Inputs(ValueObject).Returns(ValueArray),
 	func(*ParsedParams) (simpleMethod, error) {
 		return func(v interface{}, ctx FunctionContext) (interface{}, error) {
 			if m, ok := v.(map[string]interface{}); ok {
@@ -714,7 +744,7 @@ var _ = registerSimpleMethod(
 			`{"foo":{"first":"bar","second":"baz"}}`,
 			`{"foo_len":2}`,
 		),
-	),
+	).Inputs(ValueString, ValueBytes, ValueArray, ValueObject).Returns(ValueNumber),
 	func(*ParsedParams) (simpleMethod, error) {
 		return func(v interface{}, ctx FunctionContext) (interface{}, error) {
 			var length int64
@@ -976,6 +1006,31 @@ func assigner(destination, source interface{}) interface{} {
 
 //------------------------------------------------------------------------------
 
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"merge_patch", "",
+	).InCategory(
+		MethodCategoryObjectAndArray,
+		"Applies a [JSON Merge Patch (RFC 7396)](https://datatracker.ietf.org/doc/html/rfc7396) document to the target value. Fields of the patch are recursively merged into the target, where fields with a value of `null` are removed and any value that isn't an object replaces the target entirely.",
+		NewExampleSpec("",
+			`root = this.doc.merge_patch(this.patch)`,
+			`{"doc":{"title":"Goodbye!","author":{"givenName":"John","familyName":"Doe"},"tags":["example","sample"]},"patch":{"title":"Hello!","author":{"familyName":null},"tags":["example"]}}`,
+			`{"author":{"givenName":"John"},"tags":["example"],"title":"Hello!"}`,
+		),
+	).Param(ParamAny("patch", "The merge patch document to apply.")),
+	func(args *ParsedParams) (simpleMethod, error) {
+		patch, err := args.Field("patch")
+		if err != nil {
+			return nil, err
+		}
+		return func(v interface{}, ctx FunctionContext) (interface{}, error) {
+			return jsonMergePatch(IClone(v), IClone(patch)), nil
+		}, nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"not_empty", "",
@@ -1028,6 +1083,47 @@ var _ = registerSimpleMethod(
 
 //------------------------------------------------------------------------------
 
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"patch", "",
+	).InCategory(
+		MethodCategoryObjectAndArray,
+		"Applies an array of [JSON Patch (RFC 6902)](https://datatracker.ietf.org/doc/html/rfc6902) operations to the target value and returns the result. The operations `add`, `remove`, `replace`, `move`, `copy` and `test` are supported, and if any operation fails then an error is returned and none of the operations are applied.",
+		NewExampleSpec("",
+			`root = this.doc.patch(this.ops)`,
+			`{"doc":{"name":"foo","tags":["a"]},"ops":[{"op":"replace","path":"/name","value":"bar"},{"op":"add","path":"/tags/-","value":"b"},{"op":"copy","from":"/name","path":"/alias"}]}`,
+			`{"alias":"bar","name":"bar","tags":["a","b"]}`,
+		),
+		NewExampleSpec("A diff between two documents can be applied to the first in order to obtain the second.",
+			`root = this.before.patch(this.before.diff(this.after)) == this.after`,
+			`{"before":{"a":[1,2,3],"b":"foo"},"after":{"a":[1,3],"c":"bar"}}`,
+			`true`,
+		),
+	).Param(ParamArray("operations", "An array of JSON Patch operations.")),
+	func(args *ParsedParams) (simpleMethod, error) {
+		ops, err := args.FieldArray("operations")
+		if err != nil {
+			return nil, err
+		}
+		return func(v interface{}, ctx FunctionContext) (interface{}, error) {
+			doc := IClone(v)
+			for i, op := range ops {
+				opObj, ok := op.(map[string]interface{})
+				if !ok {
+					return nil, fmt.Errorf("operation %v: %w", i, NewTypeError(op, ValueObject))
+				}
+				var err error
+				if doc, err = jsonPatchApply(doc, opObj); err != nil {
+					return nil, fmt.Errorf("operation %v: %w", i, err)
+				}
+			}
+			return doc, nil
+		}, nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
 var _ = registerMethod(
 	NewMethodSpec(
 		"sort", "",
@@ -1336,7 +1432,7 @@ var _ = registerMethod(
 			`{"foo":[3,8,4]}`,
 			`{"sum":15}`,
 		),
-	),
+	).Inputs(ValueNumber, ValueArray).Returns(ValueNumber),
 	sumMethod,
 )
 
@@ -1386,7 +1482,7 @@ var _ = registerSimpleMethod(
 			"emit",
 			"An optional query that can be used in order to yield a value for each element to determine uniqueness.",
 			false,
-		).Optional()),
+		).Optional()).Inputs(ValueArray).Returns(ValueArray),
 	uniqueMethod,
 )
 
@@ -1483,7 +1579,7 @@ var _ = registerSimpleMethod(
 			`{"foo":{"bar":1,"baz":2}}`,
 			`{"foo_vals":[1,2]}`,
 		),
-	),
+	).Inputs(ValueObject).Returns(ValueArray),
 	func(*ParsedParams) (simpleMethod, error) {
 		return func(v interface{}, ctx FunctionContext) (interface{}, error) {
 			if m, ok := v.(map[string]interface{}); ok {
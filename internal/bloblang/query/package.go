@@ -70,6 +70,10 @@ type FunctionContext struct {
 	NewMeta  MetaMsg
 	NewValue *interface{}
 
+	// Tracer is optional and records the branches taken by if and match
+	// expressions when set.
+	Tracer *Tracer
+
 	valueFn    func() *interface{}
 	value      *interface{}
 	nextValue  *interface{}
@@ -0,0 +1,112 @@
+package query
+
+import (
+	"strconv"
+)
+
+// BranchTrace describes a branch of an if or match expression that was taken
+// during the execution of a mapping.
+type BranchTrace struct {
+	Expression string `json:"expression"`
+	Branch     string `json:"branch"`
+}
+
+// BranchHit describes a branch of an if or match expression that was taken
+// during the execution of a mapping, along with the location of the expression
+// and all of its branches, which allows the coverage of branches to be
+// recorded.
+type BranchHit struct {
+	// Input is a slice of the mapping pointing to the expression, and is nil
+	// when the expression wasn't created by a parser.
+	Input      []rune
+	Expression string
+	Branches   []string
+	Index      int
+}
+
+// Tracer records events that occur during the execution of a mapping in order
+// to aid with debugging. A Tracer can be provided to a FunctionContext, and is
+// not safe for concurrent use.
+type Tracer struct {
+	branches []BranchTrace
+	hits     []BranchHit
+}
+
+// NewTracer creates a new empty tracer.
+func NewTracer() *Tracer {
+	return &Tracer{}
+}
+
+// TakeBranches returns the branches recorded by the tracer since the last call
+// and clears them.
+func (t *Tracer) TakeBranches() []BranchTrace {
+	if t == nil {
+		return nil
+	}
+	b := t.branches
+	t.branches = nil
+	return b
+}
+
+// TakeBranchHits returns the branch hits recorded by the tracer since the last
+// call and clears them.
+func (t *Tracer) TakeBranchHits() []BranchHit {
+	if t == nil {
+		return nil
+	}
+	h := t.hits
+	t.hits = nil
+	return h
+}
+
+// branchExpression describes an if or match expression along with the names
+// of all of its branches.
+type branchExpression struct {
+	input    []rune
+	name     string
+	branches []string
+}
+
+func newIfBranchExpression(input []rune, elseIfs int, hasElse bool) *branchExpression {
+	branches := []string{"if"}
+	for i := 0; i < elseIfs; i++ {
+		branches = append(branches, "else if "+strconv.Itoa(i+1))
+	}
+	if hasElse {
+		branches = append(branches, "else")
+	} else {
+		branches = append(branches, "none")
+	}
+	return &branchExpression{input: input, name: "if", branches: branches}
+}
+
+func newMatchBranchExpression(input []rune, cases []MatchCase) *branchExpression {
+	var branches []string
+	var exhaustive bool
+	for i, c := range cases {
+		branches = append(branches, "case "+strconv.Itoa(i+1))
+		if lit, ok := c.caseFn.(*Literal); ok && lit.Value == true {
+			exhaustive = true
+		}
+	}
+	if !exhaustive {
+		branches = append(branches, "none")
+	}
+	return &branchExpression{input: input, name: "match", branches: branches}
+}
+
+func (t *Tracer) recordBranch(expr *branchExpression, index int) {
+	if t == nil {
+		return
+	}
+	t.branches = append(t.branches, BranchTrace{
+		Expression: expr.name,
+		Branch:     expr.branches[index],
+	})
+	t.hits = append(t.hits, BranchHit{
+		Input:      expr.input,
+		Expression: expr.name,
+		Branches:   expr.branches,
+		Index:      index,
+	})
+}
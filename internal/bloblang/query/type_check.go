@@ -0,0 +1,125 @@
+package query
+
+import (
+	"fmt"
+)
+
+// typedFunction wraps a function along with the type of value it is known to
+// return, allowing the types of mappings to be checked without executing them.
+type typedFunction struct {
+	Function
+	valueType ValueType
+}
+
+// withType returns a function annotated with the type of value that it always
+// returns. Literals are returned as they are since their type is already known.
+func withType(fn Function, t ValueType) Function {
+	if _, isLit := fn.(*Literal); isLit || t == "" {
+		return fn
+	}
+	return &typedFunction{Function: fn, valueType: t}
+}
+
+// InferType attempts to determine the type of value returned by a function
+// without executing it, ValueUnknown is returned when the type can only be
+// known during execution.
+func InferType(fn Function) ValueType {
+	switch t := fn.(type) {
+	case *typedFunction:
+		return t.valueType
+	case *Literal:
+		return ITypeOf(t.Value)
+	case *mapLiteral:
+		return ValueObject
+	case *arrayLiteral:
+		return ValueArray
+	case *notMethod:
+		return ValueBool
+	}
+	return ValueUnknown
+}
+
+func normaliseType(t ValueType) ValueType {
+	if t == ValueInt || t == ValueFloat {
+		return ValueNumber
+	}
+	return t
+}
+
+// typeAccepted returns false only when a known type is definitely not one of a
+// list of accepted types.
+func typeAccepted(t ValueType, accepted ...ValueType) bool {
+	if len(accepted) == 0 || t == ValueUnknown || t == ValueQuery {
+		return true
+	}
+	t = normaliseType(t)
+	for _, a := range accepted {
+		if normaliseType(a) == t || a == ValueUnknown {
+			return true
+		}
+	}
+	return false
+}
+
+// paramAcceptedTypes returns the types of value that can be provided to a
+// parameter, which mirrors the conversions made by parseArgValue.
+func paramAcceptedTypes(def ParamDefinition) []ValueType {
+	switch def.ValueType {
+	case ValueString:
+		return []ValueType{ValueString, ValueBytes}
+	case ValueInt, ValueFloat, ValueNumber:
+		return []ValueType{ValueNumber}
+	case ValueBool:
+		return []ValueType{ValueBool, ValueNumber}
+	case ValueArray, ValueObject:
+		return []ValueType{def.ValueType}
+	}
+	return nil
+}
+
+func checkArgTypes(args *ParsedParams) error {
+	if args == nil {
+		return nil
+	}
+	for _, dyn := range args.dynArgs {
+		if len(args.source.Definitions) <= dyn.index {
+			continue
+		}
+		def := args.source.Definitions[dyn.index]
+		accepted := paramAcceptedTypes(def)
+		if argType := InferType(dyn.fn); !typeAccepted(argType, accepted...) {
+			return fmt.Errorf("field %v: %w", def.Name, &TypeError{
+				From:     dyn.fn.Annotation(),
+				Expected: accepted,
+				Actual:   argType,
+			})
+		}
+	}
+	return nil
+}
+
+// CheckTypes returns an error if the arguments of a function are statically
+// known to be of a type that the function does not support.
+func (f *FunctionSet) CheckTypes(name string, args *ParsedParams) error {
+	if _, exists := f.specs[name]; !exists {
+		return badFunctionErr(name)
+	}
+	return checkArgTypes(args)
+}
+
+// CheckTypes returns an error if either the target or arguments of a method are
+// statically known to be of a type that the method does not support.
+func (m *MethodSet) CheckTypes(name string, target Function, args *ParsedParams) error {
+	spec, exists := m.specs[name]
+	if !exists {
+		return badMethodErr(name)
+	}
+	if targetType := InferType(target); !typeAccepted(targetType, spec.InputTypes...) {
+		return fmt.Errorf("method %v: %w", name, &TypeError{
+			From:     target.Annotation(),
+			Expected: spec.InputTypes,
+			Actual:   targetType,
+		})
+	}
+	return checkArgTypes(args)
+}
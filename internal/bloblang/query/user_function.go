@@ -0,0 +1,77 @@
+package query
+
+import (
+	"errors"
+)
+
+// UserFunction is a function defined within a mapping, consisting of a list of
+// named parameters and a body that is executed with the values of those
+// parameters accessible as variables.
+type UserFunction struct {
+	name   string
+	params Params
+	body   Function
+}
+
+// NewUserFunction creates a user defined function with a name and a list of
+// parameter names. The body of the function must be set with SetBody before the
+// function is executed.
+func NewUserFunction(name string, paramNames ...string) *UserFunction {
+	params := NewParams()
+	for _, p := range paramNames {
+		params = params.Add(ParamAny(p, ""))
+	}
+	return &UserFunction{
+		name:   name,
+		params: params,
+	}
+}
+
+// Name returns the name of the function.
+func (u *UserFunction) Name() string {
+	return u.name
+}
+
+// Params returns the parameters expected by the function.
+func (u *UserFunction) Params() Params {
+	return u.params
+}
+
+// SetBody sets the body of the function. This is done separately from
+// construction so that the body is able to call the function recursively.
+func (u *UserFunction) SetBody(body Function) {
+	u.body = body
+}
+
+// Body returns the body of the function, which is nil if it hasn't been set.
+func (u *UserFunction) Body() Function {
+	return u.body
+}
+
+// Init returns a query function that calls the user function with a set of
+// parsed arguments.
+func (u *UserFunction) Init(args *ParsedParams) (Function, error) {
+	return ClosureFunction("function "+u.name, func(ctx FunctionContext) (interface{}, error) {
+		if u.body == nil {
+			return nil, errors.New("function body was undefined")
+		}
+
+		resolved, err := args.ResolveDynamic(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		// The function body only sees its own parameters as variables and has
+		// no context, so that it behaves the same regardless of where it's
+		// called from and can't read or modify the state of the caller.
+		vars := make(map[string]interface{}, len(u.params.Definitions))
+		for i, v := range resolved.Raw() {
+			vars[u.params.Definitions[i].Name] = v
+		}
+		ctx.Vars = vars
+		ctx.valueFn, ctx.value, ctx.nextValue = nil, nil, nil
+		ctx.namedValue = nil
+
+		return u.body.Exec(ctx)
+	}, aggregateTargetPaths(args.dynamic()...)), nil
+}
@@ -11,9 +11,11 @@ type Environment struct {
 	buffers    *BufferSet
 	caches     *CacheSet
 	inputs     *InputSet
+	metrics    *MetricsSet
 	outputs    *OutputSet
 	processors *ProcessorSet
 	rateLimits *RateLimitSet
+	tracers    *TracerSet
 }
 
 // NewEnvironment creates an empty environment.
@@ -22,9 +24,11 @@ func NewEnvironment() *Environment {
 		buffers:    &BufferSet{},
 		caches:     &CacheSet{},
 		inputs:     &InputSet{},
+		metrics:    &MetricsSet{},
 		outputs:    &OutputSet{},
 		processors: &ProcessorSet{},
 		rateLimits: &RateLimitSet{},
+		tracers:    &TracerSet{},
 	}
 }
 
@@ -41,6 +45,9 @@ func (e *Environment) Clone() *Environment {
 	for _, v := range e.inputs.specs {
 		_ = newEnv.inputs.Add(v.constructor, v.spec)
 	}
+	for _, v := range e.metrics.specs {
+		_ = newEnv.metrics.Add(v.constructor, v.spec)
+	}
 	for _, v := range e.outputs.specs {
 		_ = newEnv.outputs.Add(v.constructor, v.spec)
 	}
@@ -50,6 +57,9 @@ func (e *Environment) Clone() *Environment {
 	for _, v := range e.rateLimits.specs {
 		_ = newEnv.rateLimits.Add(v.constructor, v.spec)
 	}
+	for _, v := range e.tracers.specs {
+		_ = newEnv.tracers.Add(v.constructor, v.spec)
+	}
 	return newEnv
 }
 
@@ -65,12 +75,16 @@ func (e *Environment) GetDocs(name string, ctype docs.Type) (docs.ComponentSpec,
 		spec, ok = e.caches.DocsFor(name)
 	case docs.TypeInput:
 		spec, ok = e.inputs.DocsFor(name)
+	case docs.TypeMetrics:
+		spec, ok = e.metrics.DocsFor(name)
 	case docs.TypeOutput:
 		spec, ok = e.outputs.DocsFor(name)
 	case docs.TypeProcessor:
 		spec, ok = e.processors.DocsFor(name)
 	case docs.TypeRateLimit:
 		spec, ok = e.rateLimits.DocsFor(name)
+	case docs.TypeTracer:
+		spec, ok = e.tracers.DocsFor(name)
 	default:
 		return docs.GetDocs(nil, name, ctype)
 	}
@@ -83,7 +97,9 @@ var GlobalEnvironment = &Environment{
 	buffers:    AllBuffers,
 	caches:     AllCaches,
 	inputs:     AllInputs,
+	metrics:    AllMetrics,
 	outputs:    AllOutputs,
 	processors: AllProcessors,
 	rateLimits: AllRateLimits,
+	tracers:    AllTracers,
 }
@@ -17,6 +17,24 @@ var AllMetrics = &MetricsSet{
 
 //------------------------------------------------------------------------------
 
+// MetricsAdd adds a new metrics exporter to this environment by providing a
+// constructor and documentation.
+func (e *Environment) MetricsAdd(constructor MetricConstructor, spec docs.ComponentSpec) error {
+	return e.metrics.Add(constructor, spec)
+}
+
+// MetricsInit attempts to initialise a metrics exporter from a config.
+func (e *Environment) MetricsInit(conf metrics.Config, log log.Modular) (*metrics.Namespaced, error) {
+	return e.metrics.Init(conf, log)
+}
+
+// MetricsDocs returns a slice of metrics exporter specs.
+func (e *Environment) MetricsDocs() []docs.ComponentSpec {
+	return e.metrics.Docs()
+}
+
+//------------------------------------------------------------------------------
+
 // MetricConstructor constructs an metrics component.
 type MetricConstructor func(conf metrics.Config, log log.Modular) (metrics.Type, error)
 
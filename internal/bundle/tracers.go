@@ -16,6 +16,24 @@ var AllTracers = &TracerSet{
 
 //------------------------------------------------------------------------------
 
+// TracersAdd adds a new tracer to this environment by providing a constructor
+// and documentation.
+func (e *Environment) TracersAdd(constructor TracerConstructor, spec docs.ComponentSpec) error {
+	return e.tracers.Add(constructor, spec)
+}
+
+// TracersInit attempts to initialise a tracer from a config.
+func (e *Environment) TracersInit(conf tracer.Config) (tracer.Type, error) {
+	return e.tracers.Init(conf)
+}
+
+// TracersDocs returns a slice of tracer specs.
+func (e *Environment) TracersDocs() []docs.ComponentSpec {
+	return e.tracers.Docs()
+}
+
+//------------------------------------------------------------------------------
+
 // TracerConstructor constructs an tracer component.
 type TracerConstructor func(tracer.Config) (tracer.Type, error)
 
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"sync"
 
 	"github.com/Jeffail/gabs/v2"
@@ -55,6 +56,10 @@ Find out more about Bloblang at: https://benthos.dev/docs/guides/bloblang/about`
 				Aliases: []string{"f"},
 				Usage:   "execute a mapping from a file.",
 			},
+			&cli.BoolFlag{
+				Name:  "trace",
+				Usage: "print a trace of each assignment, variable and branch evaluated by the mapping to stderr.",
+			},
 			&cli.IntFlag{
 				Name:  "max-token-length",
 				Usage: "Set the buffer size for document lines.",
@@ -122,7 +127,7 @@ func newExecCache() *execCache {
 	}
 }
 
-func (e *execCache) executeMapping(exec *mapping.Executor, rawInput, prettyOutput bool, input []byte) (string, error) {
+func (e *execCache) executeMapping(exec *mapping.Executor, rawInput, prettyOutput, trace bool, input []byte) (string, []mapping.StatementTrace, error) {
 	e.msg.Get(0).Set(input)
 
 	var valuePtr *interface{}
@@ -153,22 +158,31 @@ func (e *execCache) executeMapping(exec *mapping.Executor, rawInput, prettyOutpu
 	}
 
 	var result interface{} = query.Nothing(nil)
-	err := exec.ExecOnto(query.FunctionContext{
+	ctx := query.FunctionContext{
 		Maps:     exec.Maps(),
 		Vars:     e.vars,
 		MsgBatch: e.msg,
 		NewMeta:  e.msg.Get(0),
 		NewValue: &result,
-	}.WithValueFunc(lazyValue), mapping.AssignmentContext{
+	}.WithValueFunc(lazyValue)
+	assignCtx := mapping.AssignmentContext{
 		Vars:  e.vars,
 		Meta:  e.msg.Get(0),
 		Value: &result,
-	})
+	}
+
+	var traces []mapping.StatementTrace
+	var err error
+	if trace {
+		traces, err = exec.ExecOntoWithTrace(ctx, assignCtx)
+	} else {
+		err = exec.ExecOnto(ctx, assignCtx)
+	}
 	if err != nil {
 		if parseErr != nil && errors.Is(err, query.ErrNoContext) {
 			err = fmt.Errorf("unable to reference message as structured (with 'this'): %w", parseErr)
 		}
-		return "", err
+		return "", traces, err
 	}
 
 	var resultStr string
@@ -178,7 +192,7 @@ func (e *execCache) executeMapping(exec *mapping.Executor, rawInput, prettyOutpu
 	case []byte:
 		resultStr = string(t)
 	case query.Delete:
-		return "", nil
+		return "", traces, nil
 	case query.Nothing:
 		// Do not change the original contents
 		if v := lazyValue(); v != nil {
@@ -201,7 +215,29 @@ func (e *execCache) executeMapping(exec *mapping.Executor, rawInput, prettyOutpu
 	}
 
 	// TODO: Return metadata as well?
-	return resultStr, nil
+	return resultStr, traces, nil
+}
+
+func formatTrace(traces []mapping.StatementTrace) string {
+	var buf strings.Builder
+	for _, t := range traces {
+		fmt.Fprintf(&buf, "line %v: %v", t.Line, t.Target)
+		switch {
+		case t.Error != "":
+			buf.WriteString(" failed")
+		case t.Deleted:
+			buf.WriteString(" deleted")
+		case t.Skipped:
+			buf.WriteString(" skipped")
+		default:
+			fmt.Fprintf(&buf, " = %v", gabs.Wrap(t.Value).String())
+		}
+		for _, b := range t.Branches {
+			fmt.Fprintf(&buf, " [%v: %v]", b.Expression, b.Branch)
+		}
+		buf.WriteByte('\n')
+	}
+	return buf.String()
 }
 
 func run(c *cli.Context) error {
@@ -211,6 +247,7 @@ func run(c *cli.Context) error {
 	}
 	raw := c.Bool("raw")
 	pretty := c.Bool("pretty")
+	trace := c.Bool("trace")
 	file := c.String("file")
 	m := c.Args().First()
 
@@ -257,6 +294,7 @@ func run(c *cli.Context) error {
 		}
 	}()
 
+	var traceMut sync.Mutex
 	wg := sync.WaitGroup{}
 	wg.Add(t)
 	resultsChan := make(chan string)
@@ -275,7 +313,12 @@ func run(c *cli.Context) error {
 					return
 				}
 
-				resultStr, err := execCache.executeMapping(exec, raw, pretty, input)
+				resultStr, traces, err := execCache.executeMapping(exec, raw, pretty, trace, input)
+				if trace {
+					traceMut.Lock()
+					fmt.Fprint(os.Stderr, formatTrace(traces))
+					traceMut.Unlock()
+				}
 				if err != nil {
 					fmt.Fprintln(os.Stderr, red(fmt.Sprintf("failed to execute map: %v", err)))
 					continue
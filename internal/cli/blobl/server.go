@@ -20,6 +20,7 @@ import (
 	"github.com/urfave/cli/v2"
 
 	"github.com/benthosdev/benthos/v4/internal/bloblang"
+	"github.com/benthosdev/benthos/v4/internal/bloblang/mapping"
 	"github.com/benthosdev/benthos/v4/internal/bloblang/parser"
 
 	_ "embed"
@@ -149,6 +150,7 @@ func runServer(c *cli.Context) error {
 		req := struct {
 			Mapping string `json:"mapping"`
 			Input   string `json:"input"`
+			Trace   bool   `json:"trace"`
 		}{}
 		dec := json.NewDecoder(r.Body)
 		if err := dec.Decode(&req); err != nil {
@@ -159,9 +161,10 @@ func runServer(c *cli.Context) error {
 		fSync.update(req.Input, req.Mapping)
 
 		res := struct {
-			ParseError   string `json:"parse_error"`
-			MappingError string `json:"mapping_error"`
-			Result       string `json:"result"`
+			ParseError   string                   `json:"parse_error"`
+			MappingError string                   `json:"mapping_error"`
+			Result       string                   `json:"result"`
+			Trace        []mapping.StatementTrace `json:"trace,omitempty"`
 		}{}
 		defer func() {
 			resBytes, err := json.Marshal(res)
@@ -172,7 +175,7 @@ func runServer(c *cli.Context) error {
 			w.Write(resBytes)
 		}()
 
-		exec, err := bloblang.GlobalEnvironment().NewMapping(req.Mapping)
+		exec, err := bloblang.GlobalEnvironment().WithTypeChecking().NewMapping(req.Mapping)
 		if err != nil {
 			if perr, ok := err.(*parser.Error); ok {
 				res.ParseError = fmt.Sprintf("failed to parse mapping: %v\n", perr.ErrorAtPositionStructured("", []rune(req.Mapping)))
@@ -182,7 +185,8 @@ func runServer(c *cli.Context) error {
 			return
 		}
 
-		output, err := execCache.executeMapping(exec, false, true, []byte(req.Input))
+		output, traces, err := execCache.executeMapping(exec, false, true, req.Trace, []byte(req.Input))
+		res.Trace = traces
 		if err != nil {
 			res.MappingError = err.Error()
 		} else {
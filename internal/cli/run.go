@@ -237,6 +237,7 @@ Either run Benthos as a stream processor or choose a command:
 				false,
 				false,
 				nil,
+				"", "",
 			))
 			return nil
 		},
@@ -303,6 +304,16 @@ https://benthos.dev/docs/guides/streams_mode/about`[1:],
 						Value: false,
 						Usage: "Disable the HTTP API for streams mode",
 					},
+					&cli.StringFlag{
+						Name:  "store-dir",
+						Value: "",
+						Usage: "Persist streams modified via the HTTP API as YAML files within a directory, these streams are loaded at startup",
+					},
+					&cli.StringFlag{
+						Name:  "store-cache",
+						Value: "",
+						Usage: "Persist streams modified via the HTTP API within a cache resource of this name, these streams are loaded at startup",
+					},
 				},
 				Action: func(c *cli.Context) error {
 					os.Exit(cmdService(
@@ -315,6 +326,8 @@ https://benthos.dev/docs/guides/streams_mode/about`[1:],
 						!c.Bool("no-api"),
 						true,
 						c.Args().Slice(),
+						c.String("store-dir"),
+						c.String("store-cache"),
 					))
 					return nil
 				},
@@ -66,16 +66,31 @@ func readConfig(path string, streamsMode bool, resourcesPaths, streamsPaths, ove
 
 func initStreamsMode(
 	strict, watching, enableAPI bool,
+	storeDir, storeCache string,
 	confReader *config.Reader,
 	strmAPITimeout time.Duration,
 	manager *manager.Type,
 	logger log.Modular,
 	stats *metrics.Namespaced,
 ) stoppable {
-	streamMgr := strmmgr.New(manager,
+	mgrOpts := []func(*strmmgr.Type){
 		strmmgr.OptSetAPITimeout(strmAPITimeout),
 		strmmgr.OptAPIEnabled(enableAPI),
-	)
+	}
+	switch {
+	case storeDir != "" && storeCache != "":
+		fmt.Fprintln(os.Stderr, "Only one of store-dir and store-cache can be specified")
+		os.Exit(1)
+	case storeDir != "":
+		mgrOpts = append(mgrOpts, strmmgr.OptSetStore(strmmgr.NewDirectoryStore(storeDir)))
+	case storeCache != "":
+		if !manager.ProbeCache(storeCache) {
+			fmt.Fprintf(os.Stderr, "Cache resource '%v' for storing streams was not found\n", storeCache)
+			os.Exit(1)
+		}
+		mgrOpts = append(mgrOpts, strmmgr.OptSetStore(strmmgr.NewCacheStore(manager, storeCache, "benthos_streams")))
+	}
+	streamMgr := strmmgr.New(manager, mgrOpts...)
 
 	streamConfs := map[string]stream.Config{}
 	lints, err := confReader.ReadStreams(streamConfs)
@@ -100,6 +115,14 @@ func initStreamsMode(
 			os.Exit(1)
 		}
 	}
+
+	storeCtx, done := context.WithTimeout(context.Background(), time.Second*30)
+	err = streamMgr.LoadStored(storeCtx)
+	done()
+	if err != nil {
+		logger.Errorf("Failed to load stored streams: %v\n", err)
+		os.Exit(1)
+	}
 	logger.Infoln("Launching benthos in streams mode, use CTRL+C to close.")
 
 	if err := confReader.SubscribeStreamChanges(func(id string, newStreamConf stream.Config) bool {
@@ -177,7 +200,7 @@ func initNormalMode(
 	stoppedChan = make(chan struct{})
 
 	streamInit := func() (stoppable, error) {
-		return stream.New(
+		strm, err := stream.New(
 			conf.Config, manager,
 			stream.OptOnClose(func() {
 				if !watching {
@@ -185,6 +208,19 @@ func initNormalMode(
 				}
 			}),
 		)
+		if err != nil {
+			return nil, err
+		}
+		// Tapping exposes message contents and is therefore only available
+		// alongside the other debug endpoints.
+		if conf.HTTP.DebugEndpoints {
+			manager.RegisterEndpoint(
+				"/tap",
+				"DEBUG: Streams a sample of the messages passing through a point of the pipeline as server-sent events, or over a websocket.",
+				strm.HandleTap,
+			)
+		}
+		return strm, nil
 	}
 
 	var stoppableStream swappableStopper
@@ -231,6 +267,7 @@ func cmdService(
 	strict, watching, enableStreamsAPI bool,
 	streamsMode bool,
 	streamsPaths []string,
+	streamsStoreDir, streamsStoreCache string,
 ) int {
 	confReader := readConfig(confPath, streamsMode, resourcesPaths, streamsPaths, confOverrides)
 	conf := config.New()
@@ -273,7 +310,7 @@ func cmdService(
 
 	// Create our metrics type.
 	var stats *metrics.Namespaced
-	stats, err = bundle.AllMetrics.Init(conf.Metrics, logger)
+	stats, err = bundle.GlobalEnvironment.MetricsInit(conf.Metrics, logger)
 	for err != nil {
 		logger.Errorf("Failed to connect to metrics aggregator: %v\n", err)
 		return 1
@@ -286,7 +323,7 @@ func cmdService(
 
 	// Create our tracer type.
 	var trac tracer.Type
-	if trac, err = bundle.AllTracers.Init(conf.Tracer); err != nil {
+	if trac, err = bundle.GlobalEnvironment.TracersInit(conf.Tracer); err != nil {
 		logger.Errorf("Failed to initialise tracer: %v\n", err)
 		return 1
 	}
@@ -310,7 +347,10 @@ func cmdService(
 	}
 
 	// Create resource manager.
-	manager, err := manager.NewV2(conf.ResourceConfig, httpServer, logger, stats)
+	manager, err := manager.NewV2(
+		conf.ResourceConfig, httpServer, logger, stats,
+		manager.OptSetTracer(trac.TracerProvider()),
+	)
 	if err != nil {
 		logger.Errorf("Failed to create resource: %v\n", err)
 		return 1
@@ -328,7 +368,7 @@ func cmdService(
 
 	// Create data streams.
 	if streamsMode {
-		stoppableStream = initStreamsMode(strict, watching, enableStreamsAPI, confReader, strmAPITimeout, manager, logger, stats)
+		stoppableStream = initStreamsMode(strict, watching, enableStreamsAPI, streamsStoreDir, streamsStoreCache, confReader, strmAPITimeout, manager, logger, stats)
 	} else {
 		stoppableStream, dataStreamClosedChan = initNormalMode(conf, strict, watching, confReader, manager, logger, stats)
 	}
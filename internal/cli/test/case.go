@@ -1,6 +1,7 @@
 package test
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -76,7 +77,13 @@ type Case struct {
 	InputBatch       []InputPart          `yaml:"input_batch"`
 	OutputBatches    [][]ConditionsMap    `yaml:"output_batches"`
 
-	line int
+	// When set the full stream of the config is executed and the batches
+	// received by each output are checked.
+	Outputs        map[string][][]ConditionsMap `yaml:"outputs"`
+	FailingOutputs []string                     `yaml:"failing_outputs"`
+
+	line            int
+	updateSnapshots bool
 }
 
 // AtLine returns a test case at a given line.
@@ -137,12 +144,20 @@ type mockedProcProvider interface {
 	ProvideMocked(jsonPtr string, environment map[string]string, mocks map[string]yaml.Node) ([]iprocessor.V1, error)
 }
 
+type streamProvider interface {
+	provideStream(environment map[string]string, mocks map[string]yaml.Node) (*streamRunner, error)
+}
+
 // Execute attempts to execute a test case against a Benthos configuration.
 func (c *Case) Execute(provider ProcProvider) (failures []CaseFailure, err error) {
 	return c.executeFrom("", provider)
 }
 
 func (c *Case) executeFrom(dir string, provider ProcProvider) (failures []CaseFailure, err error) {
+	if c.Outputs != nil {
+		return c.executeStreamFrom(dir, provider)
+	}
+
 	var procSet []iprocessor.V1
 	if c.TargetMapping != "" {
 		if procSet, err = provider.ProvideBloblang(c.TargetMapping); err != nil {
@@ -156,20 +171,81 @@ func (c *Case) executeFrom(dir string, provider ProcProvider) (failures []CaseFa
 		return nil, fmt.Errorf("failed to initialise processors '%v': %v", c.TargetProcessors, err)
 	}
 
-	reportFailure := func(reason string) {
-		failures = append(failures, CaseFailure{
-			Name:     c.Name,
-			TestLine: c.line,
-			Reason:   reason,
-		})
+	inputMsg, err := c.inputBatch(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	outputBatches, result := processor.ExecuteAll(procSet, inputMsg)
+	if result != nil {
+		failures = append(failures, c.failure(fmt.Sprintf("processors resulted in error: %v", result)))
+	}
+	failures = append(failures, c.checkBatches(dir, "", c.OutputBatches, outputBatches)...)
+	return
+}
+
+func (c *Case) executeStreamFrom(dir string, provider ProcProvider) (failures []CaseFailure, err error) {
+	sProv, ok := provider.(streamProvider)
+	if !ok {
+		return nil, errors.New("the provider does not support executing streams")
+	}
+
+	runner, err := sProv.provideStream(c.Environment, c.Mocks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialise stream: %v", err)
+	}
+
+	inputMsg, err := c.inputBatch(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := runner.run(inputMsg, c.FailingOutputs)
+	if err != nil {
+		return nil, err
+	}
+	if res.ackErr != nil {
+		failures = append(failures, c.failure(fmt.Sprintf("input batch was rejected: %v", res.ackErr)))
+	}
+
+	expectedOutputs := map[string][][]ConditionsMap{}
+	for k, v := range c.Outputs {
+		name, err := res.outputName(k)
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := res.outputs[name]; !exists {
+			failures = append(failures, c.failure(fmt.Sprintf("output '%v' was not found in the stream", k)))
+			continue
+		}
+		expectedOutputs[name] = v
+	}
+
+	for _, name := range res.outputNames() {
+		expected, exists := expectedOutputs[name]
+		if !exists && len(res.outputs[name]) > 0 {
+			failures = append(failures, c.failure(fmt.Sprintf("output '%v' received unexpected batches: %s", name, batchesToString(res.outputs[name]))))
+			continue
+		}
+		failures = append(failures, c.checkBatches(dir, fmt.Sprintf("output '%v' ", name), expected, res.outputs[name])...)
+	}
+	return
+}
+
+func (c *Case) failure(reason string) CaseFailure {
+	return CaseFailure{
+		Name:     c.Name,
+		TestLine: c.line,
+		Reason:   reason,
 	}
+}
 
+func (c *Case) inputBatch(dir string) (*message.Batch, error) {
 	parts := make([]*message.Part, len(c.InputBatch))
 	for i, v := range c.InputBatch {
-		var content string
-		if content, err = v.getContent(dir); err != nil {
-			err = fmt.Errorf("failed to create mock input %v: %w", i, err)
-			return
+		content, err := v.getContent(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create mock input %v: %w", i, err)
 		}
 		part := message.NewPart([]byte(content))
 		for k, v := range v.Metadata {
@@ -180,21 +256,34 @@ func (c *Case) executeFrom(dir string, provider ProcProvider) (failures []CaseFa
 
 	inputMsg := message.QuickBatch(nil)
 	inputMsg.SetAll(parts)
-	outputBatches, result := processor.ExecuteAll(procSet, inputMsg)
-	if result != nil {
-		reportFailure(fmt.Sprintf("processors resulted in error: %v", result))
+	return inputMsg, nil
+}
+
+func batchesToString(batches []*message.Batch) string {
+	contents := make([][][]byte, len(batches))
+	for i, b := range batches {
+		contents[i] = message.GetAllBytes(b)
+	}
+	return fmt.Sprintf("%s", contents)
+}
+
+// checkBatches compares batches against the conditions of expected batches,
+// where the prefix is added to the reason of each failure.
+func (c *Case) checkBatches(dir, prefix string, expected [][]ConditionsMap, actual []*message.Batch) (failures []CaseFailure) {
+	reportFailure := func(reason string) {
+		failures = append(failures, c.failure(prefix+reason))
 	}
 
-	if lExp, lAct := len(c.OutputBatches), len(outputBatches); lAct < lExp {
+	if lExp, lAct := len(expected), len(actual); lAct < lExp {
 		reportFailure(fmt.Sprintf("wrong batch count, expected %v, got %v", lExp, lAct))
 	}
 
-	for i, v := range outputBatches {
-		if len(c.OutputBatches) <= i {
+	for i, v := range actual {
+		if len(expected) <= i {
 			reportFailure(fmt.Sprintf("unexpected batch: %s", message.GetAllBytes(v)))
 			continue
 		}
-		expectedBatch := c.OutputBatches[i]
+		expectedBatch := expected[i]
 		if lExp, lAct := len(expectedBatch), v.Len(); lExp != lAct {
 			reportFailure(fmt.Sprintf("mismatch of output batch %v message counts, expected %v, got %v", i, lExp, lAct))
 		}
@@ -203,7 +292,7 @@ func (c *Case) executeFrom(dir string, provider ProcProvider) (failures []CaseFa
 				reportFailure(fmt.Sprintf("unexpected message from batch %v: %s", i, part.Get()))
 				return nil
 			}
-			condErrs := expectedBatch[i2].checkAllFrom(dir, part)
+			condErrs := expectedBatch[i2].checkAllFrom(dir, c.updateSnapshots, part)
 			for _, condErr := range condErrs {
 				reportFailure(fmt.Sprintf("batch %v message %v: %v", i, i2, condErr))
 			}
@@ -29,7 +29,25 @@ https://benthos.dev/docs/configuration/unit_testing`[1:],
 			&cli.StringFlag{
 				Name:  "log",
 				Value: "",
-				Usage: "allow components to write logs at a provided level to stdout.",
+				Usage: "allow components to write logs at a provided level to stdout, or stderr when the format is not text.",
+			},
+			&cli.StringFlag{
+				Name:  "format",
+				Value: FormatText,
+				Usage: "print test results in a specific format. Options are text, junit, json or tap.",
+			},
+			&cli.BoolFlag{
+				Name:  "update-snapshots",
+				Usage: "write the outputs of test cases to the files of their snapshot conditions rather than checking them.",
+			},
+			&cli.BoolFlag{
+				Name:  "coverage",
+				Usage: "report which processors, switch cases, branches and Bloblang mapping statements were executed by the tests.",
+			},
+			&cli.StringFlag{
+				Name:  "coverage-output",
+				Value: "",
+				Usage: "write a JSON coverage report to a file path, implies --coverage.",
 			},
 		},
 		Action: func(c *cli.Context) error {
@@ -37,24 +55,58 @@ https://benthos.dev/docs/configuration/unit_testing`[1:],
 				fmt.Fprintln(os.Stderr, "Cannot override fields with --set (-s) during unit tests")
 				os.Exit(1)
 			}
+			format := c.String("format")
+			switch format {
+			case FormatText, FormatJUnit, FormatJSON, FormatTAP:
+			default:
+				fmt.Fprintf(os.Stderr, "Format not recognised: %v\n", format)
+				os.Exit(1)
+			}
 			resourcesPaths := c.StringSlice("resources")
 			var err error
 			if resourcesPaths, err = filepath.Globs(resourcesPaths); err != nil {
 				fmt.Printf("Failed to resolve resource glob pattern: %v\n", err)
 				os.Exit(1)
 			}
+			logger := log.Noop()
 			if logLevel := c.String("log"); len(logLevel) > 0 {
 				logConf := log.NewConfig()
 				logConf.LogLevel = logLevel
-				logger, err := log.NewV2(os.Stdout, logConf)
-				if err != nil {
+				// Structured formats are written to stdout and therefore logs
+				// are written to stderr in order to keep them parseable.
+				logOut := os.Stdout
+				if format != FormatText {
+					logOut = os.Stderr
+				}
+				if logger, err = log.NewV2(logOut, logConf); err != nil {
 					fmt.Printf("Failed to init logger: %v\n", err)
 					os.Exit(1)
 				}
-				if runAll(c.Args().Slice(), testSuffix, true, logger, resourcesPaths) {
-					os.Exit(0)
+			}
+			var coverage *Coverage
+			coverageOutput := c.String("coverage-output")
+			if c.Bool("coverage") || coverageOutput != "" {
+				coverage = NewCoverage()
+			}
+			success := runAllWithFormat(c.Args().Slice(), testSuffix, format, true, c.Bool("update-snapshots"), logger, resourcesPaths, coverage)
+			if coverage != nil {
+				report := coverage.Report()
+				// Structured formats are written to stdout and therefore the
+				// coverage summary is printed to stderr in order to keep them
+				// parseable.
+				coverageOut := os.Stdout
+				if format != FormatText {
+					coverageOut = os.Stderr
+				}
+				fmt.Fprintf(coverageOut, "\nCoverage:\n\n%v", report)
+				if coverageOutput != "" {
+					if err := report.WriteFile(coverageOutput); err != nil {
+						fmt.Fprintf(os.Stderr, "Failed to write coverage report: %v\n", err)
+						os.Exit(1)
+					}
 				}
-			} else if runAll(c.Args().Slice(), testSuffix, true, log.Noop(), resourcesPaths) {
+			}
+			if success {
 				os.Exit(0)
 			}
 			os.Exit(1)
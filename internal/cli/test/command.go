@@ -110,92 +110,126 @@ func Run(path, testSuffix string, lint bool) bool {
 // a config file, a config files test definition file, a directory, or the
 // wildcard pattern './...'.
 func RunAll(paths []string, testSuffix string, lint bool) bool {
-	return runAll(paths, testSuffix, lint, log.Noop(), nil)
+	return runAll(paths, testSuffix, lint, log.Noop(), nil, nil)
 }
 
 // RunAllWithLogger executes the test command for a slice of paths. The path can
 // either be a config file, a config files test definition file, a directory, or
 // the wildcard pattern './...'.
 func RunAllWithLogger(paths []string, testSuffix string, lint bool, logger log.Modular) bool {
-	return runAll(paths, testSuffix, lint, logger, nil)
+	return runAll(paths, testSuffix, lint, logger, nil, nil)
 }
 
-func runAll(paths []string, testSuffix string, lint bool, logger log.Modular, resourcesPaths []string) bool {
+func runAll(paths []string, testSuffix string, lint bool, logger log.Modular, resourcesPaths []string, coverage *Coverage) bool {
+	return runAllWithFormat(paths, testSuffix, FormatText, lint, false, logger, resourcesPaths, coverage)
+}
+
+// runAllWithFormat executes the test command and reports the results in a
+// given format. The text format prints the outcome of each target as it is
+// executed, whereas all other formats write a single report to stdout once all
+// targets have been executed. When updateSnapshots is true snapshot files are
+// rewritten with the outputs of test cases rather than checked.
+func runAllWithFormat(paths []string, testSuffix, format string, lint, updateSnapshots bool, logger log.Modular, resourcesPaths []string, coverage *Coverage) bool {
 	targets, err := GetTestTargets(paths, testSuffix)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to obtain test targets: %v\n", err)
 		return false
 	}
 	if len(targets) == 0 {
-		fmt.Printf("%v\n", yellow("No tests were found"))
+		if format == FormatText {
+			fmt.Printf("%v\n", yellow("No tests were found"))
+		} else {
+			fmt.Fprintln(os.Stderr, "No tests were found")
+		}
 		return false
 	}
 
-	type failedTarget struct {
-		target string
-		lints  []string
-		cases  []CaseFailure
-	}
-	fails := []failedTarget{}
-
 	targetPaths := make([]string, 0, len(targets))
 	for k := range targets {
 		targetPaths = append(targetPaths, k)
 	}
 	sort.Strings(targetPaths)
 
+	var report Report
 	for _, target := range targetPaths {
-		var lints []string
-		var failCases []CaseFailure
+		res := TargetResult{Path: target}
 		if lint {
-			if lints, err = lintTarget(target, testSuffix); err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to execute test target '%v': %v\n", target, err)
-				return false
+			if res.Lints, err = lintTarget(target, testSuffix); err != nil {
+				res.Error = err.Error()
 			}
 		}
-		if failCases, err = targets[target].execute(target, resourcesPaths, logger); err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to execute test target '%v': %v\n", target, err)
-			return false
+		if res.Error == "" {
+			if res.Cases, err = targets[target].executeResults(target, resourcesPaths, logger, coverage, updateSnapshots); err != nil {
+				res.Error = err.Error()
+			}
+		}
+		report.Targets = append(report.Targets, res)
+		if format != FormatText {
+			continue
 		}
-		if len(lints) > 0 || len(failCases) > 0 {
-			fails = append(fails, failedTarget{
-				target: target,
-				lints:  lints,
-				cases:  failCases,
-			})
+		if res.Failed() {
 			fmt.Printf("Test '%v' %v\n", target, red("failed"))
 		} else {
 			fmt.Printf("Test '%v' %v\n", target, green("succeeded"))
 		}
 	}
-	if len(fails) > 0 {
-		fmt.Printf("\nFailures:\n\n")
-		for i, fail := range fails {
-			if i > 0 {
+
+	passed := true
+	for _, t := range report.Targets {
+		if t.Failed() {
+			passed = false
+		}
+	}
+	if format != FormatText {
+		if err := report.Write(os.Stdout, format); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write test report: %v\n", err)
+			return false
+		}
+		return passed
+	}
+	if !passed {
+		printFailures(report)
+	}
+	return passed
+}
+
+func printFailures(report Report) {
+	fmt.Printf("\nFailures:\n\n")
+	first := true
+	for _, target := range report.Targets {
+		if !target.Failed() {
+			continue
+		}
+		if !first {
+			fmt.Println("")
+		}
+		first = false
+		fmt.Printf("--- %v ---\n\n", target.Path)
+		if target.Error != "" {
+			fmt.Printf("Error: %v\n", target.Error)
+		}
+		for _, lint := range target.Lints {
+			fmt.Printf("Lint: %v\n", lint)
+		}
+		var failCases []CaseFailure
+		for _, c := range target.Cases {
+			failCases = append(failCases, c.Failures...)
+		}
+		if len(failCases) > 0 {
+			if target.Error != "" || len(target.Lints) > 0 {
 				fmt.Println("")
 			}
-			fmt.Printf("--- %v ---\n\n", fail.target)
-			for _, lint := range fail.lints {
-				fmt.Printf("Lint: %v\n", lint)
-			}
-			if len(fail.cases) > 0 {
-				if len(fail.lints) > 0 {
-					fmt.Println("")
-				}
-				var namePrev string
-				for i, fail := range fail.cases {
-					if namePrev != fail.Name {
-						if i > 0 {
-							fmt.Println("")
-						}
-						fmt.Printf("%v [line %v]:\n", fail.Name, fail.TestLine)
-						namePrev = fail.Name
+			var namePrev string
+			for i, fail := range failCases {
+				if namePrev != fail.Name {
+					if i > 0 {
+						fmt.Println("")
 					}
-					fmt.Println(fail.Reason)
+					fmt.Printf("%v [line %v]:\n", fail.Name, fail.TestLine)
+					namePrev = fail.Name
 				}
+				fmt.Println(fail.Reason)
 			}
 		}
-		return false
 	}
-	return true
 }
@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"sort"
+	"sync"
 
 	"github.com/nsf/jsondiff"
 	yaml "gopkg.in/yaml.v3"
@@ -78,6 +79,12 @@ func (c *ConditionsMap) UnmarshalYAML(value *yaml.Node) error {
 				return fmt.Errorf("line %v: %v", v.Line, err)
 			}
 			cond = val
+		case "snapshot":
+			val := SnapshotCondition("")
+			if err := v.Decode(&val); err != nil {
+				return fmt.Errorf("line %v: %v", v.Line, err)
+			}
+			cond = val
 		case "metadata_equals":
 			val := MetadataEqualsCondition{}
 			if err := v.Decode(&val); err != nil {
@@ -95,17 +102,24 @@ func (c *ConditionsMap) UnmarshalYAML(value *yaml.Node) error {
 // CheckAll checks all conditions against a message part. Conditions are
 // executed in alphabetical order.
 func (c ConditionsMap) CheckAll(part *message.Part) (errs []error) {
-	return c.checkAllFrom("", part)
+	return c.checkAllFrom("", false, part)
 }
 
-func (c ConditionsMap) checkAllFrom(dir string, part *message.Part) (errs []error) {
+// checkAllFrom checks all conditions against a message part, where file paths
+// are relative to a directory. When updateSnapshots is true snapshot conditions
+// are rewritten with the contents of the message part rather than checked.
+func (c ConditionsMap) checkAllFrom(dir string, updateSnapshots bool, part *message.Part) (errs []error) {
 	condTypes := []string{}
 	for k := range c {
 		condTypes = append(condTypes, k)
 	}
 	sort.Strings(condTypes)
 	for _, k := range condTypes {
-		if relCheck, ok := c[k].(interface {
+		if snapshot, ok := c[k].(SnapshotCondition); ok && updateSnapshots {
+			if err := snapshot.updateFrom(dir, part); err != nil {
+				errs = append(errs, fmt.Errorf("%v: %v", k, err))
+			}
+		} else if relCheck, ok := c[k].(interface {
 			checkFrom(string, *message.Part) error
 		}); ok {
 			if err := relCheck.checkFrom(dir, part); err != nil {
@@ -243,6 +257,80 @@ func (c FileEqualsCondition) checkFrom(dir string, p *message.Part) error {
 
 //------------------------------------------------------------------------------
 
+// SnapshotCondition is a string condition that reads a snapshot file at the
+// string path and compares it against the contents of a message. When both the
+// snapshot and the message are valid JSON documents they are compared
+// structurally. Snapshot files are written when tests are executed with the
+// flag --update-snapshots.
+type SnapshotCondition string
+
+// Check this condition against a message part.
+func (c SnapshotCondition) Check(p *message.Part) error {
+	return c.checkFrom("", p)
+}
+
+func (c SnapshotCondition) checkFrom(dir string, p *message.Part) error {
+	relPath := filepath.Join(dir, string(c))
+
+	snapshot, err := os.ReadFile(relPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("snapshot file '%v' does not exist, run the tests with --update-snapshots in order to create it", string(c))
+		}
+		return fmt.Errorf("failed to read snapshot file: %w", err)
+	}
+
+	act := p.Get()
+	if json.Valid(snapshot) && json.Valid(act) {
+		jdopts := jsondiff.DefaultConsoleOptions()
+		diff, explanation := jsondiff.Compare(act, snapshot, &jdopts)
+		if diff != jsondiff.FullMatch {
+			return fmt.Errorf("JSON snapshot mismatch\n%v", explanation)
+		}
+		return nil
+	}
+
+	if exp, act := string(snapshot), string(act); exp != act {
+		return fmt.Errorf("snapshot mismatch\n  expected: %v\n  received: %v", blue(exp), red(act))
+	}
+	return nil
+}
+
+// snapshotMuts serialises the writes of each snapshot file, as test cases that
+// share a snapshot file can be executed in parallel.
+var snapshotMuts sync.Map
+
+// updateFrom writes the contents of a message part to the snapshot file, where
+// JSON documents are indented in order to keep snapshots readable.
+func (c SnapshotCondition) updateFrom(dir string, p *message.Part) error {
+	relPath := filepath.Join(dir, string(c))
+
+	muKey := relPath
+	if absPath, err := filepath.Abs(relPath); err == nil {
+		muKey = absPath
+	}
+	mu, _ := snapshotMuts.LoadOrStore(muKey, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	defer mu.(*sync.Mutex).Unlock()
+
+	content := p.Get()
+	var indented bytes.Buffer
+	if json.Indent(&indented, content, "", "  ") == nil {
+		indented.WriteByte('\n')
+		content = indented.Bytes()
+	}
+
+	if err := os.MkdirAll(filepath.Dir(relPath), 0o755); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	if err := os.WriteFile(relPath, content, 0o644); err != nil {
+		return fmt.Errorf("failed to write snapshot file: %w", err)
+	}
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
 // MetadataEqualsCondition checks whether a metadata keys contents matches a
 // value.
 type MetadataEqualsCondition map[string]string
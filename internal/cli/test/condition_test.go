@@ -1,11 +1,14 @@
 package test
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/fatih/color"
@@ -22,7 +25,8 @@ func TestConditionUnmarshal(t *testing.T) {
 tests:
   content_equals: "foo bar"
   metadata_equals:
-    foo: bar`
+    foo: bar
+  snapshot: ./snapshots/foo.json`
 
 	tests := struct {
 		Tests ConditionsMap
@@ -39,6 +43,7 @@ tests:
 		"metadata_equals": MetadataEqualsCondition{
 			"foo": "bar",
 		},
+		"snapshot": SnapshotCondition("./snapshots/foo.json"),
 	}
 
 	if act := tests.Tests; !reflect.DeepEqual(exp, act) {
@@ -476,3 +481,140 @@ func TestFileEqualsCondition(t *testing.T) {
 		})
 	}
 }
+
+func TestSnapshotCondition(t *testing.T) {
+	color.NoColor = true
+
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "text.txt"), []byte(`FOO BAR`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "doc.json"), []byte(`{
+  "a": "b",
+  "c": [1, 2, {"d/e": true}],
+  "f": "g"
+}`), 0o644))
+
+	tests := []struct {
+		name         string
+		path         string
+		input        string
+		err          string
+		jsonMismatch bool
+	}{
+		{
+			name:  "text matches",
+			path:  "text.txt",
+			input: "FOO BAR",
+		},
+		{
+			name:  "text mismatch",
+			path:  "text.txt",
+			input: "foo bar",
+			err:   "snapshot mismatch\n  expected: FOO BAR\n  received: foo bar",
+		},
+		{
+			name:  "json matches with different formatting",
+			path:  "doc.json",
+			input: `{"f":"g","c":[1,2,{"d/e":true}],"a":"b"}`,
+		},
+		{
+			name:         "json mismatch",
+			path:         "doc.json",
+			input:        `{"a":"b","c":[1,3],"h":"i"}`,
+			jsonMismatch: true,
+		},
+		{
+			name:         "json root mismatch",
+			path:         "doc.json",
+			input:        `"foo"`,
+			jsonMismatch: true,
+		},
+		{
+			name:  "missing snapshot",
+			path:  "nope.json",
+			input: `{}`,
+			err:   "snapshot file 'nope.json' does not exist, run the tests with --update-snapshots in order to create it",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			if test.jsonMismatch {
+				snapshot, err := os.ReadFile(filepath.Join(tmpDir, test.path))
+				require.NoError(t, err)
+
+				jdopts := jsondiff.DefaultConsoleOptions()
+				diff, explanation := jsondiff.Compare([]byte(test.input), snapshot, &jdopts)
+				require.NotEqual(t, jsondiff.FullMatch, diff)
+				test.err = fmt.Sprintf("JSON snapshot mismatch\n%v", explanation)
+			}
+
+			err := SnapshotCondition(test.path).checkFrom(tmpDir, message.NewPart([]byte(test.input)))
+			if test.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestSnapshotConditionUpdate(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	conds := ConditionsMap{
+		"snapshot": SnapshotCondition("snapshots/doc.json"),
+	}
+
+	part := message.NewPart([]byte(`{"b":"c","a":[1,2]}`))
+	assert.NotEmpty(t, conds.checkAllFrom(tmpDir, false, part))
+	assert.Empty(t, conds.checkAllFrom(tmpDir, true, part))
+
+	snapshot, err := os.ReadFile(filepath.Join(tmpDir, "snapshots", "doc.json"))
+	require.NoError(t, err)
+	assert.Equal(t, `{
+  "b": "c",
+  "a": [
+    1,
+    2
+  ]
+}
+`, string(snapshot))
+
+	assert.Empty(t, conds.checkAllFrom(tmpDir, false, part))
+	assert.NotEmpty(t, conds.checkAllFrom(tmpDir, false, message.NewPart([]byte(`{"b":"d","a":[1,2]}`))))
+
+	conds = ConditionsMap{
+		"snapshot": SnapshotCondition("snapshots/text.txt"),
+	}
+	part = message.NewPart([]byte("not json"))
+	assert.Empty(t, conds.checkAllFrom(tmpDir, true, part))
+	assert.Empty(t, conds.checkAllFrom(tmpDir, false, part))
+
+	snapshot, err = os.ReadFile(filepath.Join(tmpDir, "snapshots", "text.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "not json", string(snapshot))
+}
+
+func TestSnapshotConditionUpdateConcurrent(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	conds := ConditionsMap{
+		"snapshot": SnapshotCondition("snapshots/shared.json"),
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			doc := fmt.Sprintf(`{"id":%v,"padding":"%v"}`, i, strings.Repeat("x", 1000*(20-i)))
+			assert.Empty(t, conds.checkAllFrom(tmpDir, true, message.NewPart([]byte(doc))))
+		}(i)
+	}
+	wg.Wait()
+
+	snapshot, err := os.ReadFile(filepath.Join(tmpDir, "snapshots", "shared.json"))
+	require.NoError(t, err)
+	assert.True(t, json.Valid(snapshot), string(snapshot))
+}
@@ -0,0 +1,471 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang"
+	"github.com/benthosdev/benthos/v4/internal/bloblang/mapping"
+	"github.com/benthosdev/benthos/v4/internal/bloblang/parser"
+	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	iprocessor "github.com/benthosdev/benthos/v4/internal/component/processor"
+	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/internal/old/processor"
+)
+
+// Kinds of components that are tracked within a coverage report.
+const (
+	CoverageKindProcessor      = "processor"
+	CoverageKindSwitchCase     = "switch_case"
+	CoverageKindBranch         = "branch"
+	CoverageKindWorkflowBranch = "workflow_branch"
+	CoverageKindMapping        = "mapping"
+)
+
+// ComponentCoverage describes the number of times a component of a config was
+// executed by a test suite. Switch cases and branches are considered executed
+// when their first child processor is executed, and mappings, including switch
+// checks and branch request and result maps, are counted per message.
+type ComponentCoverage struct {
+	Path       string                      `json:"path"`
+	Label      string                      `json:"label,omitempty"`
+	Kind       string                      `json:"kind"`
+	Type       string                      `json:"type,omitempty"`
+	Hits       uint64                      `json:"hits"`
+	Statements []mapping.StatementCoverage `json:"statements,omitempty"`
+}
+
+// FileCoverage describes the coverage of the components of a single config or
+// mapping file.
+type FileCoverage struct {
+	Path       string              `json:"path"`
+	Components []ComponentCoverage `json:"components"`
+}
+
+// CoverageReport describes the components and Bloblang mapping statements that
+// were executed by a test suite.
+type CoverageReport struct {
+	Files []FileCoverage `json:"files"`
+}
+
+//------------------------------------------------------------------------------
+
+type coverageKey struct {
+	path  string
+	label string
+}
+
+type coveredComponent struct {
+	hits  uint64
+	kind  string
+	ptype string
+
+	// For switch cases and branches, the path of the first child processor.
+	childPath string
+	mapping   *mapping.Coverage
+}
+
+type fileCoverage struct {
+	components map[coverageKey]*coveredComponent
+	env        *bundle.Environment
+}
+
+// Coverage records which components and Bloblang mapping statements of config
+// files are executed during the execution of test cases. A Coverage is safe for
+// concurrent use.
+type Coverage struct {
+	mut   sync.Mutex
+	files map[string]*fileCoverage
+}
+
+// NewCoverage returns an empty coverage record.
+func NewCoverage() *Coverage {
+	return &Coverage{
+		files: map[string]*fileCoverage{},
+	}
+}
+
+func pathString(path []string) string {
+	if len(path) == 0 {
+		return "root"
+	}
+	return "root." + query.SliceToDotPath(path...)
+}
+
+func (c *Coverage) file(filePath string) *fileCoverage {
+	f, exists := c.files[filePath]
+	if !exists {
+		f = &fileCoverage{
+			components: map[coverageKey]*coveredComponent{},
+		}
+		c.files[filePath] = f
+	}
+	return f
+}
+
+func (c *Coverage) register(filePath string, key coverageKey, comp *coveredComponent) *coveredComponent {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	f := c.file(filePath)
+	if existing, exists := f.components[key]; exists {
+		return existing
+	}
+	f.components[key] = comp
+	return comp
+}
+
+// registerMapping adds a mapping executor to the coverage of a file, where the
+// statement coverage is shared with any previous executors of the same
+// component, as test cases each construct their own processors.
+func (c *Coverage) registerMapping(filePath string, key coverageKey, kind string, exec *mapping.Executor) *coveredComponent {
+	comp := c.register(filePath, key, &coveredComponent{
+		kind:    kind,
+		ptype:   processor.TypeBloblang,
+		mapping: mapping.NewCoverage(exec),
+	})
+	// Environment variables can differ between test cases and therefore the
+	// statements of a mapping might not match, in which case coverage of the
+	// mapping is only recorded for the first variant.
+	_ = exec.SetCoverage(comp.mapping)
+	return comp
+}
+
+// mappingHookManagement overrides the Bloblang environment of a component in
+// order to instrument the mappings that it parses.
+type mappingHookManagement struct {
+	bundle.NewManagement
+	env *bloblang.Environment
+}
+
+func (m *mappingHookManagement) BloblEnvironment() *bloblang.Environment {
+	return m.env
+}
+
+// instrumentMappings returns a manager for a processor where the switch checks
+// and branch request and result maps that it parses are registered as coverage
+// components of a file.
+func (c *Coverage) instrumentMappings(filePath string, conf processor.Config, nm bundle.NewManagement) bundle.NewManagement {
+	type fieldMapping struct {
+		path  []string
+		blobl string
+	}
+
+	path := nm.Path()
+	withPath := func(segments ...string) []string {
+		p := make([]string, 0, len(path)+len(segments))
+		p = append(p, path...)
+		return append(p, segments...)
+	}
+
+	var fields []fieldMapping
+	switch conf.Type {
+	case processor.TypeSwitch:
+		for i, sCase := range conf.Switch {
+			if sCase.Check != "" {
+				fields = append(fields, fieldMapping{withPath("switch", strconv.Itoa(i), "check"), sCase.Check})
+			}
+		}
+	case processor.TypeBranch:
+		if conf.Branch.RequestMap != "" {
+			fields = append(fields, fieldMapping{withPath("branch", "request_map"), conf.Branch.RequestMap})
+		}
+		if conf.Branch.ResultMap != "" {
+			fields = append(fields, fieldMapping{withPath("branch", "result_map"), conf.Branch.ResultMap})
+		}
+	}
+	if len(fields) == 0 {
+		return nm
+	}
+
+	// Mappings are parsed in the order of the config fields, and therefore each
+	// parsed mapping is matched to the next field with the same source.
+	var mut sync.Mutex
+	return &mappingHookManagement{
+		NewManagement: nm,
+		env: nm.BloblEnvironment().WithMappingHook(func(blobl string, exec *mapping.Executor) {
+			mut.Lock()
+			defer mut.Unlock()
+			for i, f := range fields {
+				if f.blobl == blobl {
+					_ = c.registerMapping(filePath, coverageKey{path: pathString(f.path)}, CoverageKindMapping, exec)
+					fields = fields[i+1:]
+					return
+				}
+			}
+		}),
+	}
+}
+
+func (c *Coverage) registerProcessor(filePath string, conf processor.Config, nm bundle.NewManagement) *coveredComponent {
+	path := nm.Path()
+	withPath := func(segments ...string) []string {
+		p := make([]string, 0, len(path)+len(segments))
+		p = append(p, path...)
+		return append(p, segments...)
+	}
+
+	switch conf.Type {
+	case processor.TypeSwitch:
+		for i, sCase := range conf.Switch {
+			if len(sCase.Processors) == 0 {
+				continue
+			}
+			casePath := withPath("switch", strconv.Itoa(i))
+			c.register(filePath, coverageKey{path: pathString(casePath)}, &coveredComponent{
+				kind:      CoverageKindSwitchCase,
+				childPath: pathString(append(casePath, "processors", "0")),
+			})
+		}
+	case processor.TypeBranch:
+		branchPath := withPath("branch")
+		c.register(filePath, coverageKey{path: pathString(branchPath)}, &coveredComponent{
+			kind:      CoverageKindBranch,
+			childPath: pathString(append(branchPath, "processors", "0")),
+		})
+	case processor.TypeWorkflow:
+		for k := range conf.Workflow.Branches {
+			branchPath := withPath("workflow", "branches", k)
+			c.register(filePath, coverageKey{path: pathString(branchPath)}, &coveredComponent{
+				kind:      CoverageKindWorkflowBranch,
+				childPath: pathString(append(branchPath, "branch", "processors", "0")),
+			})
+		}
+	}
+
+	return c.register(filePath, coverageKey{
+		path:  pathString(path),
+		label: nm.Label(),
+	}, &coveredComponent{
+		kind:  CoverageKindProcessor,
+		ptype: conf.Type,
+	})
+}
+
+// bundle returns a bundle environment where processors are wrapped in order to
+// record their execution as coverage of a config file.
+func (c *Coverage) bundle(filePath string) *bundle.Environment {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	f := c.file(filePath)
+	if f.env != nil {
+		return f.env
+	}
+
+	b := bundle.GlobalEnvironment
+	f.env = b.Clone()
+	for _, spec := range b.ProcessorDocs() {
+		_ = f.env.ProcessorAdd(func(conf processor.Config, nm bundle.NewManagement) (iprocessor.V1, error) {
+			if conf.Type != processor.TypeBloblang {
+				p, err := b.ProcessorInit(conf, c.instrumentMappings(filePath, conf, nm))
+				if err != nil {
+					return nil, err
+				}
+				comp := c.registerProcessor(filePath, conf, nm)
+				return &coveredProcessor{hits: &comp.hits, wrapped: p}, nil
+			}
+
+			// Bloblang processors are constructed here in order to instrument
+			// the statements of the mapping.
+			exec, err := nm.BloblEnvironment().NewMapping(string(conf.Bloblang))
+			if err != nil {
+				if perr, ok := err.(*parser.Error); ok {
+					return nil, fmt.Errorf("%v", perr.ErrorAtPosition([]rune(conf.Bloblang)))
+				}
+				return nil, err
+			}
+			comp := c.registerMapping(filePath, coverageKey{
+				path:  pathString(nm.Path()),
+				label: nm.Label(),
+			}, CoverageKindProcessor, exec)
+
+			p := iprocessor.NewV2BatchedToV1Processor("bloblang", processor.NewBloblangFromExecutor(exec, nm.Logger()), nm.Metrics())
+			return &coveredProcessor{hits: &comp.hits, wrapped: p}, nil
+		}, spec)
+	}
+	return f.env
+}
+
+//------------------------------------------------------------------------------
+
+type coveredProcessor struct {
+	hits    *uint64
+	wrapped iprocessor.V1
+}
+
+func (c *coveredProcessor) ProcessMessage(m *message.Batch) ([]*message.Batch, error) {
+	if m.Len() > 0 {
+		atomic.AddUint64(c.hits, 1)
+	}
+	return c.wrapped.ProcessMessage(m)
+}
+
+func (c *coveredProcessor) CloseAsync() {
+	c.wrapped.CloseAsync()
+}
+
+func (c *coveredProcessor) WaitForClose(timeout time.Duration) error {
+	return c.wrapped.WaitForClose(timeout)
+}
+
+//------------------------------------------------------------------------------
+
+// Report returns a snapshot of the coverage recorded so far, with files and
+// components sorted by their paths.
+func (c *Coverage) Report() CoverageReport {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	filePaths := make([]string, 0, len(c.files))
+	for k, f := range c.files {
+		if len(f.components) > 0 {
+			filePaths = append(filePaths, k)
+		}
+	}
+	sort.Strings(filePaths)
+
+	report := CoverageReport{
+		Files: make([]FileCoverage, 0, len(filePaths)),
+	}
+	for _, filePath := range filePaths {
+		f := c.files[filePath]
+
+		procHits := map[string]uint64{}
+		for k, comp := range f.components {
+			if comp.kind == CoverageKindProcessor {
+				procHits[k.path] += atomic.LoadUint64(&comp.hits)
+			}
+		}
+
+		fc := FileCoverage{Path: filePath}
+		for k, comp := range f.components {
+			cc := ComponentCoverage{
+				Path:  k.path,
+				Label: k.label,
+				Kind:  comp.kind,
+				Type:  comp.ptype,
+				Hits:  atomic.LoadUint64(&comp.hits),
+			}
+			if comp.childPath != "" {
+				cc.Hits = procHits[comp.childPath]
+			}
+			if comp.kind == CoverageKindMapping {
+				cc.Hits = comp.mapping.Executions()
+			}
+			if comp.mapping != nil {
+				cc.Statements = comp.mapping.Statements()
+			}
+			fc.Components = append(fc.Components, cc)
+		}
+		sort.Slice(fc.Components, func(i, j int) bool {
+			if fc.Components[i].Path == fc.Components[j].Path {
+				return fc.Components[i].Label < fc.Components[j].Label
+			}
+			return fc.Components[i].Path < fc.Components[j].Path
+		})
+		report.Files = append(report.Files, fc)
+	}
+	return report
+}
+
+//------------------------------------------------------------------------------
+
+var coverageKindNames = []struct {
+	kind string
+	name string
+}{
+	{CoverageKindProcessor, "Processors"},
+	{CoverageKindSwitchCase, "Switch cases"},
+	{CoverageKindBranch, "Branches"},
+	{CoverageKindWorkflowBranch, "Workflow branches"},
+	{CoverageKindMapping, "Mappings"},
+}
+
+func coveragePercent(covered, total int) string {
+	return fmt.Sprintf("%v/%v (%.1f%%)", covered, total, float64(covered)/float64(total)*100)
+}
+
+func componentName(c ComponentCoverage) string {
+	name := c.Path
+	if c.Label != "" {
+		name = fmt.Sprintf("%v (%v)", name, c.Label)
+	}
+	return name
+}
+
+// String returns a human readable summary of the coverage report, listing the
+// components and mapping statements of each file that were not executed.
+func (r CoverageReport) String() string {
+	var buf strings.Builder
+	for i, f := range r.Files {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(&buf, "--- %v ---\n\n", f.Path)
+
+		covered, totals := map[string]int{}, map[string]int{}
+		var stmtsCovered, stmtsTotal, branchesCovered, branchesTotal int
+		var missed []string
+		for _, c := range f.Components {
+			totals[c.Kind]++
+			if c.Hits > 0 {
+				covered[c.Kind]++
+			} else {
+				missed = append(missed, fmt.Sprintf("%v: %v", strings.ReplaceAll(c.Kind, "_", " "), componentName(c)))
+			}
+			for _, s := range c.Statements {
+				stmtsTotal++
+				if s.Hits > 0 {
+					stmtsCovered++
+				} else if c.Hits > 0 {
+					missed = append(missed, fmt.Sprintf("mapping statement: %v line %v (%v)", componentName(c), s.Line, s.Target))
+				}
+				for _, b := range s.Branches {
+					branchesTotal++
+					if b.Hits > 0 {
+						branchesCovered++
+					} else {
+						missed = append(missed, fmt.Sprintf("mapping branch: %v line %v column %v (%v: %v)", componentName(c), b.Line, b.Column, b.Expression, b.Branch))
+					}
+				}
+			}
+		}
+
+		for _, k := range coverageKindNames {
+			if totals[k.kind] > 0 {
+				fmt.Fprintf(&buf, "%v: %v\n", k.name, coveragePercent(covered[k.kind], totals[k.kind]))
+			}
+		}
+		if stmtsTotal > 0 {
+			fmt.Fprintf(&buf, "Mapping statements: %v\n", coveragePercent(stmtsCovered, stmtsTotal))
+		}
+		if branchesTotal > 0 {
+			fmt.Fprintf(&buf, "Mapping branches: %v\n", coveragePercent(branchesCovered, branchesTotal))
+		}
+		if len(missed) > 0 {
+			buf.WriteString("\nNot executed:\n")
+			for _, m := range missed {
+				fmt.Fprintf(&buf, "  %v\n", m)
+			}
+		}
+	}
+	return buf.String()
+}
+
+// WriteFile writes the coverage report as a JSON document to a file path.
+func (r CoverageReport) WriteFile(path string) error {
+	reportBytes, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, reportBytes, 0o644)
+}
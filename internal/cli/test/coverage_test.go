@@ -0,0 +1,157 @@
+package test_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/mapping"
+	"github.com/benthosdev/benthos/v4/internal/cli/test"
+)
+
+func TestCoverageReport(t *testing.T) {
+	testDir, err := initTestFiles(t, map[string]string{
+		"config1.yaml": `
+pipeline:
+  processors:
+    - switch:
+        - check: this.type == "foo"
+          processors:
+            - bloblang: |
+                root.type = this.type
+                root.result = if this.value > 10 {
+                  "big"
+                } else {
+                  "small"
+                }
+        - check: this.type == "bar"
+          processors:
+            - bloblang: 'root = "bar"'
+    - branch:
+        request_map: 'root = if this.type == "baz" { this } else { deleted() }'
+        processors:
+          - bloblang: 'root = this'
+        result_map: 'root.branched = true'
+    - label: final
+      bloblang: |
+        root = this
+        meta nope = if this.type == "nope" { "yep" }
+`,
+		"mapping.blobl": `root.a = this.a
+root.b = this.b.uppercase()`,
+	})
+	require.NoError(t, err)
+
+	cov := test.NewCoverage()
+	provider := test.NewProcessorsProvider(
+		filepath.Join(testDir, "config1.yaml"),
+		test.OptProcessorsProviderSetCoverage(cov),
+	)
+
+	for _, input := range []string{`{"type":"foo","value":5}`, `{"type":"foo","value":15}`} {
+		tCase := test.NewCase()
+		tCase.InputBatch = []test.InputPart{{Content: input}}
+		_, err := tCase.Execute(provider)
+		require.NoError(t, err)
+	}
+
+	tCase := test.NewCase()
+	tCase.TargetMapping = "mapping.blobl"
+	tCase.InputBatch = []test.InputPart{{Content: `{"a":"foo"}`}}
+	_, err = tCase.Execute(provider)
+	require.NoError(t, err)
+
+	report := cov.Report()
+	require.Len(t, report.Files, 2)
+
+	assert.Equal(t, filepath.Join(testDir, "config1.yaml"), report.Files[0].Path)
+
+	type compSummary struct {
+		Kind string
+		Hits uint64
+	}
+	comps := map[string]compSummary{}
+	for _, c := range report.Files[0].Components {
+		comps[c.Path] = compSummary{Kind: c.Kind, Hits: c.Hits}
+	}
+	assert.Equal(t, map[string]compSummary{
+		"root.pipeline.processors.0":                       {Kind: "processor", Hits: 2},
+		"root.pipeline.processors.0.switch.0":              {Kind: "switch_case", Hits: 2},
+		"root.pipeline.processors.0.switch.0.check":        {Kind: "mapping", Hits: 2},
+		"root.pipeline.processors.0.switch.0.processors.0": {Kind: "processor", Hits: 2},
+		"root.pipeline.processors.0.switch.1":              {Kind: "switch_case", Hits: 0},
+		"root.pipeline.processors.0.switch.1.check":        {Kind: "mapping", Hits: 0},
+		"root.pipeline.processors.0.switch.1.processors.0": {Kind: "processor", Hits: 0},
+		"root.pipeline.processors.1":                       {Kind: "processor", Hits: 2},
+		"root.pipeline.processors.1.branch":                {Kind: "branch", Hits: 0},
+		"root.pipeline.processors.1.branch.processors.0":   {Kind: "processor", Hits: 0},
+		"root.pipeline.processors.1.branch.request_map":    {Kind: "mapping", Hits: 2},
+		"root.pipeline.processors.1.branch.result_map":     {Kind: "mapping", Hits: 0},
+		"root.pipeline.processors.2":                       {Kind: "processor", Hits: 2},
+	}, comps)
+
+	var switchMapping, requestMap test.ComponentCoverage
+	for _, c := range report.Files[0].Components {
+		switch c.Path {
+		case "root.pipeline.processors.0.switch.0.processors.0":
+			switchMapping = c
+		case "root.pipeline.processors.1.branch.request_map":
+			requestMap = c
+		}
+	}
+
+	// Each input takes a different branch of the if expression.
+	require.Len(t, switchMapping.Statements, 2)
+	assert.Empty(t, switchMapping.Statements[0].Branches)
+	assert.Equal(t, []mapping.BranchCoverage{
+		{Line: 2, Column: 15, Expression: "if", Branch: "if", Hits: 1},
+		{Line: 2, Column: 15, Expression: "if", Branch: "else", Hits: 1},
+	}, switchMapping.Statements[1].Branches)
+
+	require.Len(t, requestMap.Statements, 1)
+	assert.Equal(t, []mapping.BranchCoverage{
+		{Line: 1, Column: 8, Expression: "if", Branch: "if", Hits: 0},
+		{Line: 1, Column: 8, Expression: "if", Branch: "else", Hits: 2},
+	}, requestMap.Statements[0].Branches)
+
+	var final test.ComponentCoverage
+	for _, c := range report.Files[0].Components {
+		if c.Label == "final" {
+			final = c
+		}
+	}
+	require.Len(t, final.Statements, 2)
+	assert.Equal(t, 1, final.Statements[0].Line)
+	assert.Equal(t, "root", final.Statements[0].Target)
+	assert.Equal(t, uint64(2), final.Statements[0].Hits)
+	assert.Equal(t, "meta nope", final.Statements[1].Target)
+	assert.Equal(t, uint64(2), final.Statements[1].Hits)
+	assert.Equal(t, []mapping.BranchCoverage{
+		{Line: 2, Column: 13, Expression: "if", Branch: "if", Hits: 0},
+		{Line: 2, Column: 13, Expression: "if", Branch: "none", Hits: 2},
+	}, final.Statements[1].Branches)
+
+	assert.Equal(t, filepath.Join(testDir, "mapping.blobl"), report.Files[1].Path)
+	require.Len(t, report.Files[1].Components, 1)
+	assert.Equal(t, "mapping", report.Files[1].Components[0].Kind)
+	assert.Equal(t, uint64(1), report.Files[1].Components[0].Hits)
+
+	assert.Contains(t, report.String(), "Switch cases: 1/2 (50.0%)")
+	assert.Contains(t, report.String(), "switch case: root.pipeline.processors.0.switch.1")
+	assert.Contains(t, report.String(), "Mapping branches: 4/6 (66.7%)")
+	assert.Contains(t, report.String(), "mapping branch: root.pipeline.processors.2 (final) line 2 column 13 (if: if)")
+
+	reportPath := filepath.Join(testDir, "coverage.json")
+	require.NoError(t, report.WriteFile(reportPath))
+
+	reportBytes, err := os.ReadFile(reportPath)
+	require.NoError(t, err)
+
+	var readReport test.CoverageReport
+	require.NoError(t, json.Unmarshal(reportBytes, &readReport))
+	assert.Equal(t, report, readReport)
+}
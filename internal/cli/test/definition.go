@@ -3,6 +3,7 @@ package test
 import (
 	"fmt"
 	"path/filepath"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 
@@ -49,24 +50,51 @@ func ExampleDefinition() Definition {
 // ExecuteWithLogger attempts to run a test definition on a target config file,
 // with a logger. Returns an array of test failures or an error.
 func (d Definition) ExecuteWithLogger(filepath string, logger log.Modular) ([]CaseFailure, error) {
-	return d.execute(filepath, nil, logger)
+	return d.execute(filepath, nil, logger, nil)
 }
 
 // Execute attempts to run a test definition on a target config file. Returns
 // an array of test failures or an error.
 func (d Definition) Execute(filepath string) ([]CaseFailure, error) {
-	return d.execute(filepath, nil, log.Noop())
+	return d.execute(filepath, nil, log.Noop(), nil)
 }
 
-func (d Definition) execute(testFilePath string, resourcesPaths []string, logger log.Modular) ([]CaseFailure, error) {
+// ExecuteWithCoverage attempts to run a test definition on a target config
+// file, recording the components and Bloblang mapping statements that were
+// executed to a coverage record. Returns an array of test failures or an error.
+func (d Definition) ExecuteWithCoverage(filepath string, coverage *Coverage) ([]CaseFailure, error) {
+	return d.execute(filepath, nil, log.Noop(), coverage)
+}
+
+func (d Definition) execute(testFilePath string, resourcesPaths []string, logger log.Modular, coverage *Coverage) ([]CaseFailure, error) {
+	results, err := d.executeResults(testFilePath, resourcesPaths, logger, coverage, false)
+	if err != nil {
+		return nil, err
+	}
+	var totalFailures []CaseFailure
+	for _, r := range results {
+		totalFailures = append(totalFailures, r.Failures...)
+	}
+	return totalFailures, nil
+}
+
+// executeResults runs each test case of a definition and returns the outcome
+// of every case, including those that passed, along with how long they took.
+// When updateSnapshots is true the snapshot conditions of each case are
+// rewritten rather than checked.
+func (d Definition) executeResults(testFilePath string, resourcesPaths []string, logger log.Modular, coverage *Coverage, updateSnapshots bool) ([]CaseResult, error) {
 	procsProvider := NewProcessorsProvider(
 		testFilePath,
 		OptAddResourcesPaths(resourcesPaths),
 		OptProcessorsProviderSetLogger(logger),
+		OptProcessorsProviderSetCoverage(coverage),
 	)
 	if d.Parallel {
 		// Warm the cache of processor configs.
 		for _, c := range d.Cases {
+			if c.Outputs != nil {
+				continue
+			}
 			if _, err := procsProvider.getConfs(c.TargetProcessors, c.Environment, c.Mocks); err != nil {
 				return nil, err
 			}
@@ -75,31 +103,41 @@ func (d Definition) execute(testFilePath string, resourcesPaths []string, logger
 
 	dir := filepath.Dir(testFilePath)
 
-	var totalFailures []CaseFailure
+	executeCase := func(c Case) (CaseResult, error) {
+		c.updateSnapshots = updateSnapshots
+		started := time.Now()
+		failures, err := c.executeFrom(dir, procsProvider)
+		return CaseResult{
+			Name:     c.Name,
+			Line:     c.line,
+			Duration: time.Since(started),
+			Failures: failures,
+		}, err
+	}
+
+	results := make([]CaseResult, len(d.Cases))
 	if !d.Parallel {
 		for i, c := range d.Cases {
 			cleanupEnv := setEnvironment(c.Environment)
-			failures, err := c.executeFrom(dir, procsProvider)
+			res, err := executeCase(c)
+			cleanupEnv()
 			if err != nil {
-				cleanupEnv()
 				return nil, fmt.Errorf("test case %v failed: %v", i, err)
 			}
-			totalFailures = append(totalFailures, failures...)
-			cleanupEnv()
+			results[i] = res
 		}
 	} else {
 		var g errgroup.Group
 
-		failureSlices := make([][]CaseFailure, len(d.Cases))
 		for i, c := range d.Cases {
 			i := i
 			c := c
 			g.Go(func() error {
-				failures, err := c.executeFrom(dir, procsProvider)
+				res, err := executeCase(c)
 				if err != nil {
 					return fmt.Errorf("test case %v failed: %v", i, err)
 				}
-				failureSlices[i] = failures
+				results[i] = res
 				return nil
 			})
 		}
@@ -108,13 +146,9 @@ func (d Definition) execute(testFilePath string, resourcesPaths []string, logger
 		if err := g.Wait(); err != nil {
 			return nil, err
 		}
-
-		for _, fs := range failureSlices {
-			totalFailures = append(totalFailures, fs...)
-		}
 	}
 
-	return totalFailures, nil
+	return results, nil
 }
 
 //------------------------------------------------------------------------------
@@ -5,6 +5,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/Jeffail/gabs/v2"
@@ -26,6 +27,12 @@ import (
 type cachedConfig struct {
 	mgr   manager.ResourceConfig
 	procs []processor.Config
+
+	// The file and path within that file that the processors were extracted
+	// from.
+	filePath  string
+	procsPath []string
+	procsList bool
 }
 
 // ProcessorsProvider consumes a Benthos config and, given a JSON Pointer,
@@ -35,7 +42,8 @@ type ProcessorsProvider struct {
 	resourcesPaths []string
 	cachedConfigs  map[string]cachedConfig
 
-	logger log.Modular
+	logger   log.Modular
+	coverage *Coverage
 }
 
 // NewProcessorsProvider returns a new processors provider aimed at a filepath.
@@ -67,6 +75,14 @@ func OptProcessorsProviderSetLogger(logger log.Modular) func(*ProcessorsProvider
 	}
 }
 
+// OptProcessorsProviderSetCoverage sets a coverage record to which the
+// execution of provided processors and Bloblang mappings is recorded.
+func OptProcessorsProviderSetCoverage(coverage *Coverage) func(*ProcessorsProvider) {
+	return func(p *ProcessorsProvider) {
+		p.coverage = coverage
+	}
+}
+
 //------------------------------------------------------------------------------
 
 // Provide attempts to extract an array of processors from a Benthos config. If
@@ -106,6 +122,9 @@ func (p *ProcessorsProvider) ProvideBloblang(pathStr string) ([]iprocessor.V1, e
 		return nil, mapErr
 	}
 
+	if p.coverage != nil {
+		_ = p.coverage.registerMapping(filepath.Clean(pathStr), coverageKey{path: "root"}, CoverageKindMapping, exec)
+	}
 	return []iprocessor.V1{
 		iprocessor.NewV2BatchedToV1Processor("bloblang", processor.NewBloblangFromExecutor(exec, p.logger), metrics.Noop()),
 	}, nil
@@ -114,14 +133,25 @@ func (p *ProcessorsProvider) ProvideBloblang(pathStr string) ([]iprocessor.V1, e
 //------------------------------------------------------------------------------
 
 func (p *ProcessorsProvider) initProcs(confs cachedConfig) ([]iprocessor.V1, error) {
-	mgr, err := manager.NewV2(confs.mgr, mock.NewManager(), p.logger, metrics.Noop())
+	var opts []manager.OptFunc
+	if p.coverage != nil {
+		opts = append(opts, manager.OptSetEnvironment(p.coverage.bundle(confs.filePath)))
+	}
+
+	mgr, err := manager.NewV2(confs.mgr, mock.NewManager(), p.logger, metrics.Noop(), opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialise resources: %v", err)
 	}
 
 	procs := make([]iprocessor.V1, len(confs.procs))
 	for i, conf := range confs.procs {
-		if procs[i], err = processor.New(conf, mgr, p.logger, metrics.Noop()); err != nil {
+		// Processors are given the path they were extracted from so that
+		// coverage can be reported against the config.
+		pMgr := mgr.IntoPath(confs.procsPath...)
+		if confs.procsList {
+			pMgr = mgr.IntoPath(append(confs.procsPath[:len(confs.procsPath):len(confs.procsPath)], strconv.Itoa(i))...)
+		}
+		if procs[i], err = processor.New(conf, pMgr, p.logger, metrics.Noop()); err != nil {
 			return nil, fmt.Errorf("failed to initialise processor index '%v': %v", i, err)
 		}
 	}
@@ -182,29 +212,9 @@ func resolveProcessorsPointer(targetFile, jsonPtr string) (filePath, procPath st
 	return
 }
 
-func (p *ProcessorsProvider) getConfs(jsonPtr string, environment map[string]string, mocks map[string]yaml.Node) (cachedConfig, error) {
-	cacheKey := confTargetID(jsonPtr, environment, mocks)
-
-	confs, exists := p.cachedConfigs[cacheKey]
-	if exists {
-		return confs, nil
-	}
-
-	targetPath, procPath, err := resolveProcessorsPointer(p.targetPath, jsonPtr)
-	if err != nil {
-		return confs, err
-	}
-	if targetPath == "" {
-		targetPath = p.targetPath
-	}
-
-	// Set custom environment vars.
-	ogEnvVars := map[string]string{}
-	for k, v := range environment {
-		ogEnvVars[k] = os.Getenv(k)
-		os.Setenv(k, v)
-	}
-
+// readMockedConfig reads a config file and the resources files of the
+// provider, replacing any components of the config file with mocks.
+func (p *ProcessorsProvider) readMockedConfig(targetPath string, environment map[string]string, mocks map[string]yaml.Node) (*yaml.Node, manager.ResourceConfig, error) {
 	cleanupEnv := setEnvironment(environment)
 	defer cleanupEnv()
 
@@ -213,35 +223,16 @@ func (p *ProcessorsProvider) getConfs(jsonPtr string, environment map[string]str
 		remainingMocks[k] = v
 	}
 
-	configBytes, _, err := config.ReadFileEnvSwap(targetPath)
-	if err != nil {
-		return confs, fmt.Errorf("failed to parse config file '%v': %v", targetPath, err)
-	}
-
 	mgrWrapper := manager.NewResourceConfig()
-	if err = yaml.Unmarshal(configBytes, &mgrWrapper); err != nil {
-		return confs, fmt.Errorf("failed to parse config file '%v': %v", targetPath, err)
-	}
 
-	for _, path := range p.resourcesPaths {
-		resourceBytes, _, err := config.ReadFileEnvSwap(path)
-		if err != nil {
-			return confs, fmt.Errorf("failed to parse resources config file '%v': %v", path, err)
-		}
-		extraMgrWrapper := manager.NewResourceConfig()
-		if err = yaml.Unmarshal(resourceBytes, &extraMgrWrapper); err != nil {
-			return confs, fmt.Errorf("failed to parse resources config file '%v': %v", path, err)
-		}
-		if err = mgrWrapper.AddFrom(&extraMgrWrapper); err != nil {
-			return confs, fmt.Errorf("failed to merge resources from '%v': %v", path, err)
-		}
+	configBytes, _, err := config.ReadFileEnvSwap(targetPath)
+	if err != nil {
+		return nil, mgrWrapper, fmt.Errorf("failed to parse config file '%v': %v", targetPath, err)
 	}
 
-	confs.mgr = mgrWrapper
-
 	root := &yaml.Node{}
 	if err = yaml.Unmarshal(configBytes, root); err != nil {
-		return confs, fmt.Errorf("failed to parse config file '%v': %v", targetPath, err)
+		return nil, mgrWrapper, fmt.Errorf("failed to parse config file '%v': %v", targetPath, err)
 	}
 
 	// Replace mock components, starting with all absolute paths in JSON pointer
@@ -253,10 +244,10 @@ func (p *ProcessorsProvider) getConfs(jsonPtr string, environment map[string]str
 		}
 		mockPathSlice, err := gabs.JSONPointerToSlice(k)
 		if err != nil {
-			return confs, fmt.Errorf("failed to parse mock path '%v': %w", k, err)
+			return nil, mgrWrapper, fmt.Errorf("failed to parse mock path '%v': %w", k, err)
 		}
 		if err = confSpec.SetYAMLPath(nil, root, &v, mockPathSlice...); err != nil {
-			return confs, fmt.Errorf("failed to set mock '%v': %w", k, err)
+			return nil, mgrWrapper, fmt.Errorf("failed to set mock '%v': %w", k, err)
 		}
 		delete(remainingMocks, k)
 	}
@@ -267,15 +258,64 @@ func (p *ProcessorsProvider) getConfs(jsonPtr string, environment map[string]str
 		for k, v := range remainingMocks {
 			mockPathSlice, exists := labelsToPaths[k]
 			if !exists {
-				return confs, fmt.Errorf("mock for label '%v' could not be applied as the label was not found in the test target file, it is not currently possible to mock resources imported separate to the test file", k)
+				return nil, mgrWrapper, fmt.Errorf("mock for label '%v' could not be applied as the label was not found in the test target file, it is not currently possible to mock resources imported separate to the test file", k)
 			}
 			if err = confSpec.SetYAMLPath(nil, root, &v, mockPathSlice...); err != nil {
-				return confs, fmt.Errorf("failed to set mock '%v': %w", k, err)
+				return nil, mgrWrapper, fmt.Errorf("failed to set mock '%v': %w", k, err)
 			}
 			delete(remainingMocks, k)
 		}
 	}
 
+	if err = root.Decode(&mgrWrapper); err != nil {
+		return nil, mgrWrapper, fmt.Errorf("failed to parse config file '%v': %v", targetPath, err)
+	}
+
+	for _, path := range p.resourcesPaths {
+		resourceBytes, _, err := config.ReadFileEnvSwap(path)
+		if err != nil {
+			return nil, mgrWrapper, fmt.Errorf("failed to parse resources config file '%v': %v", path, err)
+		}
+		extraMgrWrapper := manager.NewResourceConfig()
+		if err = yaml.Unmarshal(resourceBytes, &extraMgrWrapper); err != nil {
+			return nil, mgrWrapper, fmt.Errorf("failed to parse resources config file '%v': %v", path, err)
+		}
+		if err = mgrWrapper.AddFrom(&extraMgrWrapper); err != nil {
+			return nil, mgrWrapper, fmt.Errorf("failed to merge resources from '%v': %v", path, err)
+		}
+	}
+	return root, mgrWrapper, nil
+}
+
+func (p *ProcessorsProvider) getConfs(jsonPtr string, environment map[string]string, mocks map[string]yaml.Node) (cachedConfig, error) {
+	cacheKey := confTargetID(jsonPtr, environment, mocks)
+
+	confs, exists := p.cachedConfigs[cacheKey]
+	if exists {
+		return confs, nil
+	}
+
+	targetPath, procPath, err := resolveProcessorsPointer(p.targetPath, jsonPtr)
+	if err != nil {
+		return confs, err
+	}
+	if targetPath == "" {
+		targetPath = p.targetPath
+	}
+
+	// Set custom environment vars.
+	ogEnvVars := map[string]string{}
+	for k, v := range environment {
+		ogEnvVars[k] = os.Getenv(k)
+		os.Setenv(k, v)
+	}
+
+	root, mgrWrapper, err := p.readMockedConfig(targetPath, environment, mocks)
+	if err != nil {
+		return confs, err
+	}
+	confs.mgr = mgrWrapper
+
 	pathSlice, err := gabs.JSONPointerToSlice(procPath)
 	if err != nil {
 		return confs, fmt.Errorf("failed to parse case processors path '%v': %w", procPath, err)
@@ -284,7 +324,10 @@ func (p *ProcessorsProvider) getConfs(jsonPtr string, environment map[string]str
 		return confs, fmt.Errorf("failed to resolve case processors from '%v': %v", targetPath, err)
 	}
 
+	confs.filePath = filepath.Clean(targetPath)
+	confs.procsPath = pathSlice
 	if root.Kind == yaml.SequenceNode {
+		confs.procsList = true
 		if err = root.Decode(&confs.procs); err != nil {
 			return confs, fmt.Errorf("failed to resolve case processors from '%v': %v", targetPath, err)
 		}
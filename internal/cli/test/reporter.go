@@ -0,0 +1,356 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// Formats in which the results of a test run can be reported.
+const (
+	FormatText  = "text"
+	FormatJUnit = "junit"
+	FormatJSON  = "json"
+	FormatTAP   = "tap"
+)
+
+// CaseResult describes the outcome of executing a single test case.
+type CaseResult struct {
+	Name     string
+	Line     int
+	Duration time.Duration
+	Failures []CaseFailure
+}
+
+// TargetResult describes the outcome of executing the test definition of a
+// config file. Error is set when the target could not be linted or executed,
+// in which case the results of its test cases are missing.
+type TargetResult struct {
+	Path  string
+	Error string
+	Lints []string
+	Cases []CaseResult
+}
+
+// Failed returns true if the target errored, has lint errors or failed test
+// cases.
+func (t TargetResult) Failed() bool {
+	if t.Error != "" || len(t.Lints) > 0 {
+		return true
+	}
+	for _, c := range t.Cases {
+		if len(c.Failures) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (t TargetResult) duration() (d time.Duration) {
+	for _, c := range t.Cases {
+		d += c.Duration
+	}
+	return
+}
+
+// Report contains the results of each test target executed during a test run.
+type Report struct {
+	Targets []TargetResult
+}
+
+// Write writes the report to a writer in the provided format, which must be
+// one of junit, json or tap.
+func (r Report) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatJUnit:
+		return r.WriteJUnit(w)
+	case FormatJSON:
+		return r.WriteJSON(w)
+	case FormatTAP:
+		return r.WriteTAP(w)
+	}
+	return fmt.Errorf("format not recognised: %v", format)
+}
+
+func failureReasons(failures []CaseFailure) []string {
+	reasons := make([]string, len(failures))
+	for i, f := range failures {
+		reasons[i] = f.Reason
+	}
+	return reasons
+}
+
+func durationMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+//------------------------------------------------------------------------------
+
+type jsonCaseResult struct {
+	Name       string   `json:"name"`
+	Line       int      `json:"line"`
+	Passed     bool     `json:"passed"`
+	DurationMS float64  `json:"duration_ms"`
+	Failures   []string `json:"failures,omitempty"`
+}
+
+type jsonTargetResult struct {
+	Path   string           `json:"path"`
+	Passed bool             `json:"passed"`
+	Error  string           `json:"error,omitempty"`
+	Lints  []string         `json:"lints,omitempty"`
+	Cases  []jsonCaseResult `json:"cases"`
+}
+
+type jsonReport struct {
+	Passed  bool               `json:"passed"`
+	Targets []jsonTargetResult `json:"targets"`
+}
+
+// WriteJSON writes the report as a JSON document.
+func (r Report) WriteJSON(w io.Writer) error {
+	report := jsonReport{
+		Passed:  true,
+		Targets: make([]jsonTargetResult, 0, len(r.Targets)),
+	}
+	for _, t := range r.Targets {
+		target := jsonTargetResult{
+			Path:   t.Path,
+			Passed: !t.Failed(),
+			Error:  t.Error,
+			Lints:  t.Lints,
+			Cases:  make([]jsonCaseResult, 0, len(t.Cases)),
+		}
+		if !target.Passed {
+			report.Passed = false
+		}
+		for _, c := range t.Cases {
+			target.Cases = append(target.Cases, jsonCaseResult{
+				Name:       c.Name,
+				Line:       c.Line,
+				Passed:     len(c.Failures) == 0,
+				DurationMS: durationMillis(c.Duration),
+				Failures:   failureReasons(c.Failures),
+			})
+		}
+		report.Targets = append(report.Targets, target)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+//------------------------------------------------------------------------------
+
+type junitFailure struct {
+	Message  string `xml:"message,attr"`
+	Contents string `xml:",cdata"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr"`
+	Line      int           `xml:"line,attr,omitempty"`
+	Time      string        `xml:"time,attr"`
+	Error     *junitFailure `xml:"error,omitempty"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Time      string          `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+func junitTime(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// WriteJUnit writes the report as a JUnit XML document, where each config file
+// is a test suite. Lint errors of a config file are reported as a failed test
+// case named "lint", and a config file that could not be executed is reported
+// as an errored test case named "execute".
+func (r Report) WriteJUnit(w io.Writer) error {
+	var total time.Duration
+	suites := junitTestSuites{}
+	for _, t := range r.Targets {
+		suite := junitTestSuite{
+			Name: t.Path,
+			Time: junitTime(t.duration()),
+		}
+		if t.Error != "" {
+			suite.TestCases = append(suite.TestCases, junitTestCase{
+				Name:      "execute",
+				ClassName: t.Path,
+				File:      t.Path,
+				Time:      junitTime(0),
+				Error: &junitFailure{
+					Message:  "failed to execute test target",
+					Contents: t.Error,
+				},
+			})
+			suite.Errors++
+		}
+		if len(t.Lints) > 0 {
+			suite.TestCases = append(suite.TestCases, junitTestCase{
+				Name:      "lint",
+				ClassName: t.Path,
+				File:      t.Path,
+				Time:      junitTime(0),
+				Failure: &junitFailure{
+					Message:  fmt.Sprintf("%v lint errors", len(t.Lints)),
+					Contents: strings.Join(t.Lints, "\n"),
+				},
+			})
+			suite.Failures++
+		}
+		for _, c := range t.Cases {
+			tc := junitTestCase{
+				Name:      c.Name,
+				ClassName: t.Path,
+				File:      t.Path,
+				Line:      c.Line,
+				Time:      junitTime(c.Duration),
+			}
+			if len(c.Failures) > 0 {
+				tc.Failure = &junitFailure{
+					Message:  fmt.Sprintf("%v failed conditions", len(c.Failures)),
+					Contents: strings.Join(failureReasons(c.Failures), "\n"),
+				}
+				suite.Failures++
+			}
+			suite.TestCases = append(suite.TestCases, tc)
+		}
+		suite.Tests = len(suite.TestCases)
+
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Errors += suite.Errors
+		total += t.duration()
+		suites.Suites = append(suites.Suites, suite)
+	}
+	suites.Time = junitTime(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+//------------------------------------------------------------------------------
+
+type tapDiagnostic struct {
+	Line       int      `yaml:"line,omitempty"`
+	DurationMS float64  `yaml:"duration_ms"`
+	Error      string   `yaml:"error,omitempty"`
+	Failures   []string `yaml:"failures,omitempty"`
+}
+
+func writeTAPDiagnostic(w io.Writer, diag tapDiagnostic) error {
+	var diagBuf bytes.Buffer
+	enc := yaml.NewEncoder(&diagBuf)
+	enc.SetIndent(2)
+	if err := enc.Encode(diag); err != nil {
+		return err
+	}
+
+	var buf strings.Builder
+	buf.WriteString("  ---\n")
+	for _, l := range strings.Split(strings.TrimSuffix(diagBuf.String(), "\n"), "\n") {
+		buf.WriteString("  " + l + "\n")
+	}
+	buf.WriteString("  ...\n")
+	_, err := io.WriteString(w, buf.String())
+	return err
+}
+
+// WriteTAP writes the report as a Test Anything Protocol (version 13) stream,
+// where each test case is a test point. Lint errors of a config file and
+// config files that could not be executed are reported as failed test points,
+// and the reasons for failures are included as YAML diagnostics.
+func (r Report) WriteTAP(w io.Writer) error {
+	total := 0
+	for _, t := range r.Targets {
+		total += len(t.Cases)
+		if t.Error != "" {
+			total++
+		}
+		if len(t.Lints) > 0 {
+			total++
+		}
+	}
+	if _, err := fmt.Fprintf(w, "TAP version 13\n1..%v\n", total); err != nil {
+		return err
+	}
+
+	n := 0
+	for _, t := range r.Targets {
+		if t.Error != "" {
+			n++
+			if _, err := fmt.Fprintf(w, "not ok %v - %v: execute\n", n, t.Path); err != nil {
+				return err
+			}
+			if err := writeTAPDiagnostic(w, tapDiagnostic{Error: t.Error}); err != nil {
+				return err
+			}
+		}
+		if len(t.Lints) > 0 {
+			n++
+			if _, err := fmt.Fprintf(w, "not ok %v - %v: lint\n", n, t.Path); err != nil {
+				return err
+			}
+			if err := writeTAPDiagnostic(w, tapDiagnostic{Failures: t.Lints}); err != nil {
+				return err
+			}
+		}
+		for _, c := range t.Cases {
+			n++
+			status := "ok"
+			if len(c.Failures) > 0 {
+				status = "not ok"
+			}
+			// A hash within a description would otherwise begin a directive.
+			desc := strings.ReplaceAll(fmt.Sprintf("%v: %v", t.Path, c.Name), "#", "\\#")
+			if _, err := fmt.Fprintf(w, "%v %v - %v\n", status, n, desc); err != nil {
+				return err
+			}
+			if len(c.Failures) == 0 {
+				continue
+			}
+			if err := writeTAPDiagnostic(w, tapDiagnostic{
+				Line:       c.Line,
+				DurationMS: durationMillis(c.Duration),
+				Failures:   failureReasons(c.Failures),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package test_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/cli/test"
+)
+
+func testReport() test.Report {
+	return test.Report{
+		Targets: []test.TargetResult{
+			{
+				Path: "foo.yaml",
+				Cases: []test.CaseResult{
+					{Name: "first", Line: 3, Duration: time.Millisecond * 2},
+					{
+						Name:     "second #2",
+						Line:     10,
+						Duration: time.Millisecond * 5,
+						Failures: []test.CaseFailure{
+							{Name: "second #2", TestLine: 10, Reason: "batch 0 message 0: content mismatch"},
+							{Name: "second #2", TestLine: 10, Reason: "batch 0 message 1: metadata mismatch"},
+						},
+					},
+				},
+			},
+			{
+				Path:  "bar.yaml",
+				Lints: []string{"line 5: field nope not recognised"},
+				Cases: []test.CaseResult{
+					{Name: "third", Line: 4, Duration: time.Millisecond},
+				},
+			},
+			{
+				Path:  "baz.yaml",
+				Error: "failed to init processors: nope",
+			},
+		},
+	}
+}
+
+func TestReportJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, testReport().Write(&buf, test.FormatJSON))
+
+	var report map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &report))
+
+	assert.Equal(t, map[string]interface{}{
+		"passed": false,
+		"targets": []interface{}{
+			map[string]interface{}{
+				"path":   "foo.yaml",
+				"passed": false,
+				"cases": []interface{}{
+					map[string]interface{}{"name": "first", "line": 3.0, "passed": true, "duration_ms": 2.0},
+					map[string]interface{}{
+						"name": "second #2", "line": 10.0, "passed": false, "duration_ms": 5.0,
+						"failures": []interface{}{
+							"batch 0 message 0: content mismatch",
+							"batch 0 message 1: metadata mismatch",
+						},
+					},
+				},
+			},
+			map[string]interface{}{
+				"path":   "bar.yaml",
+				"passed": false,
+				"lints":  []interface{}{"line 5: field nope not recognised"},
+				"cases": []interface{}{
+					map[string]interface{}{"name": "third", "line": 4.0, "passed": true, "duration_ms": 1.0},
+				},
+			},
+			map[string]interface{}{
+				"path":   "baz.yaml",
+				"passed": false,
+				"error":  "failed to init processors: nope",
+				"cases":  []interface{}{},
+			},
+		},
+	}, report)
+}
+
+func TestReportJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, testReport().Write(&buf, test.FormatJUnit))
+
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="5" failures="2" errors="1" time="0.008">
+  <testsuite name="foo.yaml" tests="2" failures="1" errors="0" time="0.007">
+    <testcase name="first" classname="foo.yaml" file="foo.yaml" line="3" time="0.002"></testcase>
+    <testcase name="second #2" classname="foo.yaml" file="foo.yaml" line="10" time="0.005">
+      <failure message="2 failed conditions"><![CDATA[batch 0 message 0: content mismatch
+batch 0 message 1: metadata mismatch]]></failure>
+    </testcase>
+  </testsuite>
+  <testsuite name="bar.yaml" tests="2" failures="1" errors="0" time="0.001">
+    <testcase name="lint" classname="bar.yaml" file="bar.yaml" time="0.000">
+      <failure message="1 lint errors"><![CDATA[line 5: field nope not recognised]]></failure>
+    </testcase>
+    <testcase name="third" classname="bar.yaml" file="bar.yaml" line="4" time="0.001"></testcase>
+  </testsuite>
+  <testsuite name="baz.yaml" tests="1" failures="0" errors="1" time="0.000">
+    <testcase name="execute" classname="baz.yaml" file="baz.yaml" time="0.000">
+      <error message="failed to execute test target"><![CDATA[failed to init processors: nope]]></error>
+    </testcase>
+  </testsuite>
+</testsuites>
+`, buf.String())
+
+	var doc struct {
+		Suites []struct {
+			Name string `xml:"name,attr"`
+		} `xml:"testsuite"`
+	}
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &doc))
+	require.Len(t, doc.Suites, 3)
+}
+
+func TestReportTAP(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, testReport().Write(&buf, test.FormatTAP))
+
+	assert.Equal(t, `TAP version 13
+1..5
+ok 1 - foo.yaml: first
+not ok 2 - foo.yaml: second \#2
+  ---
+  line: 10
+  duration_ms: 5
+  failures:
+    - 'batch 0 message 0: content mismatch'
+    - 'batch 0 message 1: metadata mismatch'
+  ...
+not ok 3 - bar.yaml: lint
+  ---
+  duration_ms: 0
+  failures:
+    - 'line 5: field nope not recognised'
+  ...
+ok 4 - bar.yaml: third
+not ok 5 - baz.yaml: execute
+  ---
+  duration_ms: 0
+  error: 'failed to init processors: nope'
+  ...
+`, buf.String())
+}
+
+func TestReportUnknownFormat(t *testing.T) {
+	var buf bytes.Buffer
+	require.EqualError(t, testReport().Write(&buf, "nope"), "format not recognised: nope")
+}
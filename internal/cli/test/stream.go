@@ -0,0 +1,332 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Jeffail/gabs/v2"
+	yaml "gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	iinput "github.com/benthosdev/benthos/v4/internal/component/input"
+	"github.com/benthosdev/benthos/v4/internal/component/metrics"
+	ioutput "github.com/benthosdev/benthos/v4/internal/component/output"
+	iprocessor "github.com/benthosdev/benthos/v4/internal/component/processor"
+	"github.com/benthosdev/benthos/v4/internal/config"
+	"github.com/benthosdev/benthos/v4/internal/docs"
+	"github.com/benthosdev/benthos/v4/internal/manager"
+	"github.com/benthosdev/benthos/v4/internal/manager/mock"
+	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/internal/old/input"
+	"github.com/benthosdev/benthos/v4/internal/old/output"
+	"github.com/benthosdev/benthos/v4/internal/stream"
+)
+
+// The maximum duration to wait for a test batch to be delivered through a
+// stream, and then for the stream to shut down.
+const streamTestTimeout = time.Second * 10
+
+var errMockOutputFailure = errors.New("mock output failure")
+
+//------------------------------------------------------------------------------
+
+// outputKeyName converts the name of an output used within a test case, which
+// is either a label or a JSON pointer, to either a label or a component path.
+func outputKeyName(key string) (string, error) {
+	if len(key) == 0 || key[0] != '/' {
+		return key, nil
+	}
+	path, err := gabs.JSONPointerToSlice(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse output path '%v': %w", key, err)
+	}
+	return pathString(path), nil
+}
+
+func hasOutputChildren(fields ...docs.FieldSpec) bool {
+	for _, f := range fields {
+		if f.Type == docs.FieldTypeOutput || hasOutputChildren(f.Children...) {
+			return true
+		}
+	}
+	return false
+}
+
+//------------------------------------------------------------------------------
+
+// capturedOutputs records the batches received by mocked outputs, where each
+// output is named by its label, or by its path when it has no label.
+type capturedOutputs struct {
+	mut     sync.Mutex
+	failing map[string]struct{}
+	batches map[string][]*message.Batch
+	paths   map[string]string
+}
+
+func (c *capturedOutputs) sink(nm bundle.NewManagement) *captureSink {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	path := pathString(nm.Path())
+	name := nm.Label()
+	if name == "" {
+		name = path
+	}
+	c.paths[path] = name
+	if _, exists := c.batches[name]; !exists {
+		c.batches[name] = nil
+	}
+
+	_, fail := c.failing[name]
+	if _, failPath := c.failing[path]; failPath {
+		fail = true
+	}
+	return &captureSink{name: name, fail: fail, outputs: c}
+}
+
+type captureSink struct {
+	name    string
+	fail    bool
+	outputs *capturedOutputs
+}
+
+func (c *captureSink) ConnectWithContext(ctx context.Context) error {
+	return nil
+}
+
+func (c *captureSink) WriteWithContext(ctx context.Context, msg *message.Batch) error {
+	if c.fail {
+		return errMockOutputFailure
+	}
+	c.outputs.mut.Lock()
+	c.outputs.batches[c.name] = append(c.outputs.batches[c.name], msg.DeepCopy())
+	c.outputs.mut.Unlock()
+	return nil
+}
+
+func (c *captureSink) CloseAsync() {}
+
+func (c *captureSink) WaitForClose(time.Duration) error {
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+// mockInput emits a single batch of messages, if provided, and reports whether
+// it was acknowledged before shutting down.
+type mockInput struct {
+	tranChan  chan message.Transaction
+	closeOnce sync.Once
+	closeChan chan struct{}
+	doneChan  chan struct{}
+}
+
+func newMockInput(batch *message.Batch, ackChan chan<- error) *mockInput {
+	i := &mockInput{
+		tranChan:  make(chan message.Transaction),
+		closeChan: make(chan struct{}),
+		doneChan:  make(chan struct{}),
+	}
+	go i.loop(batch, ackChan)
+	return i
+}
+
+func (i *mockInput) loop(batch *message.Batch, ackChan chan<- error) {
+	defer func() {
+		close(i.tranChan)
+		close(i.doneChan)
+	}()
+	if batch == nil {
+		<-i.closeChan
+		return
+	}
+
+	resChan := make(chan error)
+	select {
+	case i.tranChan <- message.NewTransaction(batch, resChan):
+	case <-i.closeChan:
+		return
+	}
+	select {
+	case err := <-resChan:
+		ackChan <- err
+	case <-i.closeChan:
+	}
+}
+
+func (i *mockInput) TransactionChan() <-chan message.Transaction {
+	return i.tranChan
+}
+
+func (i *mockInput) Connected() bool {
+	return true
+}
+
+func (i *mockInput) CloseAsync() {
+	i.closeOnce.Do(func() {
+		close(i.closeChan)
+	})
+}
+
+func (i *mockInput) WaitForClose(timeout time.Duration) error {
+	select {
+	case <-i.doneChan:
+	case <-time.After(timeout):
+		return errors.New("timed out")
+	}
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+// streamRunner executes a config as a full stream, where the input of the
+// stream is replaced with a test batch and outputs are replaced with mocks that
+// capture the messages they receive.
+type streamRunner struct {
+	conf    stream.Config
+	mgrConf manager.ResourceConfig
+	env     *bundle.Environment
+	p       *ProcessorsProvider
+}
+
+// provideStream attempts to parse the target config of the provider as a full
+// stream, with mocked components injected into the parsed config.
+func (p *ProcessorsProvider) provideStream(environment map[string]string, mocks map[string]yaml.Node) (*streamRunner, error) {
+	root, mgrConf, err := p.readMockedConfig(p.targetPath, environment, mocks)
+	if err != nil {
+		return nil, err
+	}
+
+	conf := config.New()
+	if err = root.Decode(&conf); err != nil {
+		return nil, fmt.Errorf("failed to parse config file '%v': %v", p.targetPath, err)
+	}
+
+	env := bundle.GlobalEnvironment
+	if p.coverage != nil {
+		env = p.coverage.bundle(filepath.Clean(p.targetPath))
+	}
+	return &streamRunner{
+		conf:    conf.Config,
+		mgrConf: mgrConf,
+		env:     env,
+		p:       p,
+	}, nil
+}
+
+func (s *streamRunner) mockedEnvironment(batch *message.Batch, ackChan chan<- error, outputs *capturedOutputs) *bundle.Environment {
+	env := s.env.Clone()
+
+	for _, spec := range s.env.InputDocs() {
+		_ = env.InputAdd(func(conf input.Config, nm bundle.NewManagement, pcf ...iprocessor.PipelineConstructorFunc) (iinput.Streamed, error) {
+			var inputBatch *message.Batch
+			if p := nm.Path(); len(p) == 1 && p[0] == "input" {
+				inputBatch = batch
+			}
+			pcf = input.AppendProcessorsFromConfig(conf, nm, pcf...)
+			return input.WrapWithPipelines(newMockInput(inputBatch, ackChan), pcf...)
+		}, spec)
+	}
+
+	for _, spec := range s.env.OutputDocs() {
+		// Outputs that route messages to other outputs are kept in order to
+		// test their behaviour.
+		if spec.Name == "resource" || hasOutputChildren(spec.Config) {
+			continue
+		}
+		_ = env.OutputAdd(func(conf output.Config, nm bundle.NewManagement, pcf ...iprocessor.PipelineConstructorFunc) (ioutput.Streamed, error) {
+			o, err := output.NewAsyncWriter("mock", 1, outputs.sink(nm), nm.Logger(), nm.Metrics())
+			if err != nil {
+				return nil, err
+			}
+			pcf = output.AppendProcessorsFromConfig(conf, nm, pcf...)
+			return output.WrapWithPipelines(o, pcf...)
+		}, spec)
+	}
+	return env
+}
+
+// streamResult contains the batches received by each mocked output of a
+// stream, and the acknowledgement of the input batch.
+type streamResult struct {
+	outputs map[string][]*message.Batch
+	paths   map[string]string
+	ackErr  error
+}
+
+// outputName resolves the name of an output used within a test case to the name
+// of a mocked output.
+func (r *streamResult) outputName(key string) (string, error) {
+	name, err := outputKeyName(key)
+	if err != nil {
+		return "", err
+	}
+	if n, exists := r.paths[name]; exists {
+		name = n
+	}
+	return name, nil
+}
+
+// run sends a batch through the stream and returns the result.
+func (s *streamRunner) run(batch *message.Batch, failingOutputs []string) (*streamResult, error) {
+	outputs := &capturedOutputs{
+		failing: map[string]struct{}{},
+		batches: map[string][]*message.Batch{},
+		paths:   map[string]string{},
+	}
+	for _, k := range failingOutputs {
+		name, err := outputKeyName(k)
+		if err != nil {
+			return nil, err
+		}
+		outputs.failing[name] = struct{}{}
+	}
+
+	ackChan := make(chan error, 1)
+	env := s.mockedEnvironment(batch, ackChan, outputs)
+
+	mgr, err := manager.NewV2(s.mgrConf, mock.NewManager(), s.p.logger, metrics.Noop(), manager.OptSetEnvironment(env))
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialise resources: %v", err)
+	}
+	defer func() {
+		mgr.CloseAsync()
+		_ = mgr.WaitForClose(streamTestTimeout)
+	}()
+
+	strm, err := stream.New(s.conf, mgr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialise stream: %v", err)
+	}
+
+	res := &streamResult{}
+	select {
+	case res.ackErr = <-ackChan:
+	case <-time.After(streamTestTimeout):
+		_ = strm.Stop(streamTestTimeout)
+		return nil, fmt.Errorf("timed out after %v waiting for the input batch to be acknowledged", streamTestTimeout)
+	}
+	if err := strm.Stop(streamTestTimeout); err != nil {
+		return nil, fmt.Errorf("failed to stop stream: %v", err)
+	}
+
+	outputs.mut.Lock()
+	res.outputs = outputs.batches
+	res.paths = outputs.paths
+	outputs.mut.Unlock()
+	return res, nil
+}
+
+func (r *streamResult) outputNames() []string {
+	names := make([]string, 0, len(r.outputs))
+	for k := range r.outputs {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,146 @@
+package test_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/cli/test"
+)
+
+func TestStreamCase(t *testing.T) {
+	testDir, err := initTestFiles(t, map[string]string{
+		"config1.yaml": `
+input:
+  kafka:
+    addresses: [ localhost:9092 ]
+    topics: [ foo ]
+    consumer_group: foo
+
+pipeline:
+  processors:
+    - bloblang: 'root = this.merge({"processed": true})'
+
+output:
+  switch:
+    cases:
+      - check: this.type == "foo"
+        output:
+          label: foo_out
+          http_client:
+            url: http://localhost:1234/foo
+      - check: this.type == "bar"
+        output:
+          broker:
+            pattern: fan_out
+            outputs:
+              - label: bar_a
+                drop: {}
+              - fallback:
+                  - label: bar_primary
+                    http_client:
+                      url: http://localhost:1234/bar
+                  - label: bar_dlq
+                    drop: {}
+      - output:
+          label: other
+          processors:
+            - bloblang: 'root = content().uppercase()'
+          drop: {}
+`,
+	})
+	require.NoError(t, err)
+
+	provider := test.NewProcessorsProvider(filepath.Join(testDir, "config1.yaml"))
+
+	var testCases []test.Case
+	require.NoError(t, yaml.Unmarshal([]byte(`
+- name: routes foo
+  input_batch:
+    - content: '{"type":"foo"}'
+  outputs:
+    foo_out:
+      - - json_equals: { "type": "foo", "processed": true }
+- name: routes bar to both outputs
+  input_batch:
+    - content: '{"type":"bar"}'
+  outputs:
+    bar_a:
+      - - json_equals: { "type": "bar", "processed": true }
+    bar_primary:
+      - - json_equals: { "type": "bar", "processed": true }
+- name: routes bar to the fallback
+  input_batch:
+    - content: '{"type":"bar"}'
+  failing_outputs: [ bar_primary ]
+  outputs:
+    /output/switch/cases/1/output/broker/outputs/0:
+      - - json_equals: { "type": "bar", "processed": true }
+    bar_dlq:
+      - - json_equals: { "type": "bar", "processed": true }
+- name: routes others with output processors
+  input_batch:
+    - content: '{"type":"baz"}'
+    - content: '{"type":"buz"}'
+  outputs:
+    other:
+      - - content_equals: '{"PROCESSED":TRUE,"TYPE":"BAZ"}'
+        - content_equals: '{"PROCESSED":TRUE,"TYPE":"BUZ"}'
+- name: wrong routes
+  input_batch:
+    - content: '{"type":"foo"}'
+  outputs:
+    other:
+      - - content_equals: 'nope'
+    nope: []
+`), &testCases))
+
+	var failures [][]test.CaseFailure
+	for _, c := range testCases {
+		c := c
+		fails, err := c.Execute(provider)
+		require.NoError(t, err, c.Name)
+		failures = append(failures, fails)
+	}
+
+	for i := 0; i < 4; i++ {
+		assert.Empty(t, failures[i], testCases[i].Name)
+	}
+
+	var reasons []string
+	for _, f := range failures[4] {
+		reasons = append(reasons, f.Reason)
+	}
+	assert.ElementsMatch(t, []string{
+		"output 'nope' was not found in the stream",
+		"output 'foo_out' received unexpected batches: [[{\"processed\":true,\"type\":\"foo\"}]]",
+		"output 'other' wrong batch count, expected 1, got 0",
+	}, reasons)
+}
+
+func TestStreamCaseRejected(t *testing.T) {
+	testDir, err := initTestFiles(t, map[string]string{
+		"config1.yaml": `
+output:
+  label: foo
+  http_client:
+    url: http://localhost:1234/foo
+`,
+	})
+	require.NoError(t, err)
+
+	provider := test.NewProcessorsProvider(filepath.Join(testDir, "config1.yaml"))
+
+	c := test.NewCase()
+	c.InputBatch = []test.InputPart{{Content: "hello world"}}
+	c.Outputs = map[string][][]test.ConditionsMap{}
+	c.FailingOutputs = []string{"/output"}
+
+	fails, err := c.Execute(provider)
+	require.NoError(t, err)
+	require.Len(t, fails, 1)
+	assert.Contains(t, fails[0].Reason, "input batch was rejected")
+}
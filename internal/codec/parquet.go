@@ -0,0 +1,305 @@
+package codec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/schema"
+	"github.com/xitongsys/parquet-go/writer"
+
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+type parquetReader struct {
+	pr        *reader.ParquetReader
+	r         io.ReadCloser
+	sourceAck ReaderAckFn
+
+	// When the source isn't a local file it is spooled to a temporary file in
+	// order to provide the random access required for reading the footer and
+	// columns.
+	tmpPath string
+
+	// Reads are serialised with readMut, which is held separately from mut
+	// so that acknowledgements aren't blocked whilst a row group is read.
+	readMut  sync.Mutex
+	rowGroup int
+
+	mut      sync.Mutex
+	finished bool
+	pending  int32
+}
+
+func newParquetReader(path string, r io.ReadCloser, ackFn ReaderAckFn) (Reader, error) {
+	p := &parquetReader{
+		r:         r,
+		sourceAck: ackOnce(ackFn),
+	}
+
+	var filePath string
+	if f, ok := r.(*os.File); ok {
+		filePath = f.Name()
+	} else {
+		tmpFile, err := os.CreateTemp("", "benthos-parquet-*")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create temporary file: %w", err)
+		}
+		p.tmpPath = tmpFile.Name()
+		_, err = io.Copy(tmpFile, r)
+		if cErr := tmpFile.Close(); err == nil {
+			err = cErr
+		}
+		if err != nil {
+			p.removeTmp()
+			return nil, fmt.Errorf("failed to spool parquet file: %w", err)
+		}
+		filePath = p.tmpPath
+	}
+
+	pf, err := local.NewLocalFileReader(filePath)
+	if err != nil {
+		p.removeTmp()
+		return nil, err
+	}
+
+	if p.pr, err = reader.NewParquetReader(pf, nil, 1); err != nil {
+		pf.Close()
+		p.removeTmp()
+		return nil, fmt.Errorf("failed to read parquet footer: %w", err)
+	}
+
+	rootType, err := p.pr.SchemaHandler.GetType(p.pr.SchemaHandler.GetRootInName())
+	if err != nil {
+		p.closeParquet()
+		return nil, err
+	}
+	p.pr.ObjType = newParquetTagger(p.pr.SchemaHandler).tag(rootType, 0)
+	return p, nil
+}
+
+func (a *parquetReader) removeTmp() {
+	if a.tmpPath != "" {
+		_ = os.Remove(a.tmpPath)
+		a.tmpPath = ""
+	}
+}
+
+func (a *parquetReader) closeParquet() {
+	a.pr.ReadStop()
+	_ = a.pr.PFile.Close()
+	a.removeTmp()
+}
+
+func (a *parquetReader) ack(ctx context.Context, err error) error {
+	a.mut.Lock()
+	a.pending--
+	doAck := a.pending == 0 && a.finished
+	a.mut.Unlock()
+
+	if err != nil {
+		return a.sourceAck(ctx, err)
+	}
+	if doAck {
+		return a.sourceAck(ctx, nil)
+	}
+	return nil
+}
+
+// Next reads an entire row group at a time and returns each row as a message
+// of the resulting batch, which is acknowledged as a whole.
+func (a *parquetReader) Next(ctx context.Context) ([]*message.Part, ReaderAckFn, error) {
+	a.readMut.Lock()
+	defer a.readMut.Unlock()
+
+	for {
+		if a.rowGroup >= len(a.pr.Footer.RowGroups) {
+			a.mut.Lock()
+			a.finished = true
+			a.mut.Unlock()
+			return nil, nil, io.EOF
+		}
+
+		numRows := a.pr.Footer.RowGroups[a.rowGroup].NumRows
+		a.rowGroup++
+		if numRows == 0 {
+			continue
+		}
+
+		rows, err := a.pr.ReadByNumber(int(numRows))
+		if err != nil {
+			err = fmt.Errorf("failed to read parquet row group: %w", err)
+			_ = a.sourceAck(ctx, err)
+			return nil, nil, err
+		}
+
+		parts := make([]*message.Part, len(rows))
+		for i, row := range rows {
+			parts[i] = message.NewPart(nil)
+			parts[i].SetJSON(row)
+		}
+
+		a.mut.Lock()
+		a.pending++
+		a.mut.Unlock()
+		return parts, a.ack, nil
+	}
+}
+
+func (a *parquetReader) Close(ctx context.Context) error {
+	a.readMut.Lock()
+	defer a.readMut.Unlock()
+
+	a.mut.Lock()
+	defer a.mut.Unlock()
+
+	if !a.finished {
+		_ = a.sourceAck(ctx, errors.New("service shutting down"))
+	}
+	if a.pending == 0 {
+		_ = a.sourceAck(ctx, nil)
+	}
+	a.closeParquet()
+	return a.r.Close()
+}
+
+//------------------------------------------------------------------------------
+
+// parquetTagger rebuilds the struct types derived from a parquet schema with
+// JSON tags matching the original column names, as the struct field names are
+// otherwise capitalised in order to be exported.
+type parquetTagger struct {
+	sh       *schema.SchemaHandler
+	children [][]int32
+}
+
+func newParquetTagger(sh *schema.SchemaHandler) *parquetTagger {
+	children := make([][]int32, len(sh.SchemaElements))
+
+	var pos int32
+	var walk func() int32
+	walk = func() int32 {
+		idx := pos
+		pos++
+		for i := int32(0); i < sh.SchemaElements[idx].GetNumChildren(); i++ {
+			children[idx] = append(children[idx], walk())
+		}
+		return idx
+	}
+	walk()
+
+	return &parquetTagger{sh: sh, children: children}
+}
+
+func (p *parquetTagger) convertedChild(idx int32, cType parquet.ConvertedType, names ...string) (int32, bool) {
+	cT := p.sh.SchemaElements[idx].ConvertedType
+	if cT == nil || *cT != cType || len(p.children[idx]) != 1 {
+		return 0, false
+	}
+	group := p.children[idx][0]
+	if p.sh.GetInName(int(group)) != names[0] || len(p.children[group]) != len(names)-1 {
+		return 0, false
+	}
+	for i, name := range names[1:] {
+		if p.sh.GetInName(int(p.children[group][i])) != name {
+			return 0, false
+		}
+	}
+	return p.children[group][len(names)-2], true
+}
+
+func (p *parquetTagger) tag(t reflect.Type, idx int32) reflect.Type {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return reflect.PtrTo(p.tag(t.Elem(), idx))
+	case reflect.Slice:
+		if elemIdx, ok := p.convertedChild(idx, parquet.ConvertedType_LIST, "List", "Element"); ok {
+			return reflect.SliceOf(p.tag(t.Elem(), elemIdx))
+		}
+		return reflect.SliceOf(p.tag(t.Elem(), idx))
+	case reflect.Map:
+		if valueIdx, ok := p.convertedChild(idx, parquet.ConvertedType_MAP, "Key_value", "Key", "Value"); ok {
+			return reflect.MapOf(t.Key(), p.tag(t.Elem(), valueIdx))
+		}
+		return t
+	case reflect.Struct:
+		if len(p.children[idx]) != t.NumField() {
+			return t
+		}
+		fields := make([]reflect.StructField, t.NumField())
+		for i := range fields {
+			child := p.children[idx][i]
+			fields[i] = t.Field(i)
+			fields[i].Type = p.tag(fields[i].Type, child)
+			fields[i].Tag = reflect.StructTag(fmt.Sprintf(`json:%q`, p.sh.Infos[child].ExName))
+		}
+		return reflect.StructOf(fields)
+	}
+	return t
+}
+
+//------------------------------------------------------------------------------
+
+var parquetWriterConfig = WriterConfig{
+	Truncate: true,
+}
+
+type parquetWriter struct {
+	w  io.WriteCloser
+	pw *writer.JSONWriter
+}
+
+func parquetSchemaFromCodec(schema string) (string, error) {
+	if strings.HasPrefix(schema, "file://") {
+		schemaBytes, err := os.ReadFile(strings.TrimPrefix(schema, "file://"))
+		if err != nil {
+			return "", fmt.Errorf("failed to read parquet schema file: %w", err)
+		}
+		schema = string(schemaBytes)
+	}
+	if schema == "" {
+		return "", errors.New("parquet codec requires a non-empty schema")
+	}
+	// Parse the schema upfront so that errors are surfaced at config time.
+	if _, err := writer.NewJSONWriterFromWriter(schema, io.Discard, 1); err != nil {
+		return "", fmt.Errorf("failed to parse parquet schema: %w", err)
+	}
+	return schema, nil
+}
+
+func newParquetWriter(w io.WriteCloser, schema string) (Writer, error) {
+	pw, err := writer.NewJSONWriterFromWriter(schema, w, 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create parquet writer: %w", err)
+	}
+	return &parquetWriter{w: w, pw: pw}, nil
+}
+
+// Write adds a document to the current row group, which is flushed to the
+// underlying writer once it reaches the row group size.
+func (p *parquetWriter) Write(ctx context.Context, msg *message.Part) error {
+	if err := p.pw.Write(msg.Get()); err != nil {
+		return fmt.Errorf("failed to write document to parquet file: %w", err)
+	}
+	return nil
+}
+
+func (p *parquetWriter) EndBatch() error {
+	return nil
+}
+
+func (p *parquetWriter) Close(ctx context.Context) error {
+	err := p.pw.WriteStop()
+	if cErr := p.w.Close(); err == nil {
+		err = cErr
+	}
+	return err
+}
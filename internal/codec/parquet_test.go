@@ -0,0 +1,207 @@
+package codec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+const testParquetSchema = `{
+  "Tag": "name=root, repetitiontype=REQUIRED",
+  "Fields": [
+    {"Tag": "name=name, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=REQUIRED"},
+    {"Tag": "name=age, type=INT32, repetitiontype=REQUIRED"},
+    {"Tag": "name=tags, type=LIST, repetitiontype=OPTIONAL", "Fields": [
+      {"Tag": "name=element, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=REQUIRED"}
+    ]}
+  ]
+}`
+
+func writeParquet(t *testing.T, rowGroupSize int64, docs ...string) []byte {
+	t.Helper()
+
+	var buf bufCloser
+	w, err := newParquetWriter(&buf, testParquetSchema)
+	require.NoError(t, err)
+
+	pw := w.(*parquetWriter).pw
+	pw.RowGroupSize = rowGroupSize
+	if rowGroupSize < pw.PageSize {
+		pw.PageSize = rowGroupSize
+	}
+	for _, d := range docs {
+		require.NoError(t, w.Write(context.Background(), message.NewPart([]byte(d))))
+	}
+	require.NoError(t, w.Close(context.Background()))
+	assert.True(t, buf.closed)
+	return buf.Bytes()
+}
+
+func TestParquetReaderRowGroups(t *testing.T) {
+	input := []string{
+		`{"name":"foo","age":20,"tags":["a","b"]}`,
+		`{"name":"bar","age":21}`,
+		`{"name":"baz","age":22,"tags":["c"]}`,
+	}
+
+	// A tiny row group size results in a row group per document.
+	for _, test := range []struct {
+		name         string
+		rowGroupSize int64
+		batches      [][]string
+	}{
+		{
+			name:         "single row group",
+			rowGroupSize: 128 * 1024 * 1024,
+			batches: [][]string{{
+				`{"name":"foo","age":20,"tags":["a","b"]}`,
+				`{"name":"bar","age":21,"tags":null}`,
+				`{"name":"baz","age":22,"tags":["c"]}`,
+			}},
+		},
+		{
+			name:         "row group per document",
+			rowGroupSize: 1,
+			batches: [][]string{
+				{`{"name":"foo","age":20,"tags":["a","b"]}`},
+				{`{"name":"bar","age":21,"tags":null}`},
+				{`{"name":"baz","age":22,"tags":["c"]}`},
+			},
+		},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			data := writeParquet(t, test.rowGroupSize, input...)
+
+			ctor, err := GetReader("parquet", NewReaderConfig())
+			require.NoError(t, err)
+
+			var ackErr error
+			ackCalled := false
+			r, err := ctor("", noopCloser{bytes.NewReader(data), false}, func(ctx context.Context, err error) error {
+				ackCalled = true
+				ackErr = err
+				return nil
+			})
+			require.NoError(t, err)
+
+			var ackFns []ReaderAckFn
+			for _, exp := range test.batches {
+				parts, ackFn, err := r.Next(context.Background())
+				require.NoError(t, err)
+
+				var strs []string
+				for _, p := range parts {
+					strs = append(strs, string(p.Get()))
+				}
+				assert.Equal(t, exp, strs)
+				ackFns = append(ackFns, ackFn)
+			}
+
+			_, _, err = r.Next(context.Background())
+			assert.EqualError(t, err, "EOF")
+
+			for _, fn := range ackFns {
+				assert.False(t, ackCalled)
+				require.NoError(t, fn(context.Background(), nil))
+			}
+			assert.True(t, ackCalled)
+			assert.NoError(t, ackErr)
+			require.NoError(t, r.Close(context.Background()))
+		})
+	}
+}
+
+func TestParquetReaderLocalFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "foo.parquet")
+	require.NoError(t, os.WriteFile(path, writeParquet(t, 1, `{"name":"foo","age":20}`), 0o644))
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+
+	ctor, err := GetReader("auto", NewReaderConfig())
+	require.NoError(t, err)
+
+	ackErr := errors.New("default err")
+	r, err := ctor(path, f, func(ctx context.Context, err error) error {
+		ackErr = err
+		return nil
+	})
+	require.NoError(t, err)
+
+	parts, ackFn, err := r.Next(context.Background())
+	require.NoError(t, err)
+	require.Len(t, parts, 1)
+	assert.Equal(t, `{"name":"foo","age":20,"tags":null}`, string(parts[0].Get()))
+	require.NoError(t, ackFn(context.Background(), nil))
+
+	_, _, err = r.Next(context.Background())
+	assert.EqualError(t, err, "EOF")
+	require.NoError(t, r.Close(context.Background()))
+	assert.NoError(t, ackErr)
+}
+
+func TestParquetReaderCloseEarly(t *testing.T) {
+	data := writeParquet(t, 1, `{"name":"foo","age":20}`, `{"name":"bar","age":21}`)
+
+	ctor, err := GetReader("parquet", NewReaderConfig())
+	require.NoError(t, err)
+
+	var ackErr error
+	r, err := ctor("", noopCloser{bytes.NewReader(data), false}, func(ctx context.Context, err error) error {
+		ackErr = err
+		return nil
+	})
+	require.NoError(t, err)
+
+	_, ackFn, err := r.Next(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, ackFn(context.Background(), nil))
+
+	require.NoError(t, r.Close(context.Background()))
+	assert.EqualError(t, ackErr, "service shutting down")
+}
+
+func TestParquetWriterCodec(t *testing.T) {
+	schemaPath := filepath.Join(t.TempDir(), "schema.json")
+	require.NoError(t, os.WriteFile(schemaPath, []byte(testParquetSchema), 0o644))
+
+	ctor, conf, err := GetWriter("parquet:file://" + schemaPath)
+	require.NoError(t, err)
+	assert.True(t, conf.Truncate)
+	assert.False(t, conf.Append)
+
+	var buf bufCloser
+	w, err := ctor(&buf)
+	require.NoError(t, err)
+	require.NoError(t, w.Write(context.Background(), message.NewPart([]byte(`{"name":"foo","age":20}`))))
+	require.NoError(t, w.Close(context.Background()))
+
+	ctor2, err := GetReader("parquet", NewReaderConfig())
+	require.NoError(t, err)
+
+	r, err := ctor2("", noopCloser{bytes.NewReader(buf.Bytes()), false}, func(ctx context.Context, err error) error {
+		return nil
+	})
+	require.NoError(t, err)
+
+	parts, _, err := r.Next(context.Background())
+	require.NoError(t, err)
+	require.Len(t, parts, 1)
+	assert.Equal(t, `{"name":"foo","age":20,"tags":null}`, string(parts[0].Get()))
+	require.NoError(t, r.Close(context.Background()))
+
+	_, _, err = GetWriter("parquet:")
+	assert.EqualError(t, err, "parquet codec requires a non-empty schema")
+
+	_, _, err = GetWriter("parquet:{nope")
+	assert.Error(t, err)
+}
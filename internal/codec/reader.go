@@ -6,6 +6,7 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"encoding/binary"
 	"encoding/csv"
 	"errors"
 	"fmt"
@@ -16,25 +17,33 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/klauspost/compress/zstd"
+	"github.com/linkedin/goavro/v2"
+
 	"github.com/benthosdev/benthos/v4/internal/docs"
 	"github.com/benthosdev/benthos/v4/internal/message"
 )
 
 // ReaderDocs is a static field documentation for input codecs.
 var ReaderDocs = docs.FieldCommon(
-	"codec", "The way in which the bytes of a data source should be converted into discrete messages, codecs are useful for specifying how large files or contiunous streams of data might be processed in small chunks rather than loading it all in memory. It's possible to consume lines using a custom delimiter with the `delim:x` codec, where x is the character sequence custom delimiter. Codecs can be chained with `/`, for example a gzip compressed CSV file can be consumed with the codec `gzip/csv`.", "lines", "delim:\t", "delim:foobar", "gzip/csv",
+	"codec", "The way in which the bytes of a data source should be converted into discrete messages, codecs are useful for specifying how large files or contiunous streams of data might be processed in small chunks rather than loading it all in memory. It's possible to consume lines using a custom delimiter with the `delim:x` codec, where x is the character sequence custom delimiter. Codecs can be chained with `/`, for example a gzip compressed CSV file can be consumed with the codec `gzip/csv`.", "lines", "delim:\t", "delim:foobar", "gzip/csv", "zstd/length-prefixed:uint32",
 ).HasAnnotatedOptions(
 	"auto", "EXPERIMENTAL: Attempts to derive a codec for each file based on information such as the extension. For example, a .tar.gz file would be consumed with the `gzip/tar` codec. Defaults to all-bytes.",
 	"all-bytes", "Consume the entire file as a single binary message.",
+	"avro-ocf", "Consume an [Avro Object Container File](https://avro.apache.org/docs/current/spec.html#Object+Container+Files), where each record of the file is consumed as a structured message. The schema of the records is read from the file header.",
 	"chunker:x", "Consume the file in chunks of a given number of bytes.",
 	"csv", "Consume structured rows as comma separated values, the first row must be a header row.",
 	"csv:x", "Consume structured rows as values separated by a custom delimiter, the first row must be a header row. The custom delimiter must be a single character, e.g. the codec `\"csv:\\t\"` would consume a tab delimited file.",
 	"delim:x", "Consume the file in segments divided by a custom delimiter.",
 	"gzip", "Decompress a gzip file, this codec should precede another codec, e.g. `gzip/all-bytes`, `gzip/tar`, `gzip/csv`, etc.",
+	"length-prefixed:uint32", "Consume the file in segments where each segment is preceded by its length as a 32-bit unsigned big-endian integer.",
+	"length-prefixed:varint", "Consume the file in segments where each segment is preceded by its length as an unsigned varint, as used by protobuf delimited streams.",
 	"lines", "Consume the file in segments divided by linebreaks.",
 	"multipart", "Consumes the output of another codec and batches messages together. A batch ends when an empty message is consumed. For example, the codec `lines/multipart` could be used to consume multipart messages where an empty line indicates the end of each batch.",
+	"parquet", "Consume a [Parquet file](https://parquet.apache.org/documentation/latest/) one row group at a time, where each row group is consumed as a batch of structured messages, one for each row. Since parquet files must be read with random access sources other than local files are first written to a temporary file on disk rather than being loaded into memory.",
 	"regex:(?m)^\\d\\d:\\d\\d:\\d\\d", "Consume the file in segments divided by regular expression.",
 	"tar", "Parse the file as a tar archive, and consume each file of the archive as a message.",
+	"zstd", "Decompress a zstd file, this codec should precede another codec, e.g. `zstd/all-bytes`, `zstd/lines`, `zstd/avro-ocf`, etc.",
 )
 
 //------------------------------------------------------------------------------
@@ -190,9 +199,33 @@ func ioReader(codec string, conf ReaderConfig) (ioReaderConstructor, bool) {
 			return g, nil
 		}, true
 	}
+	if codec == "zstd" {
+		return func(_ string, r io.ReadCloser) (io.ReadCloser, error) {
+			z, err := zstd.NewReader(r)
+			if err != nil {
+				r.Close()
+				return nil, err
+			}
+			return &zstdReadCloser{d: z, r: r}, nil
+		}, true
+	}
 	return nil, false
 }
 
+type zstdReadCloser struct {
+	d *zstd.Decoder
+	r io.ReadCloser
+}
+
+func (z *zstdReadCloser) Read(p []byte) (int, error) {
+	return z.d.Read(p)
+}
+
+func (z *zstdReadCloser) Close() error {
+	z.d.Close()
+	return z.r.Close()
+}
+
 func readerReader(codec string, conf ReaderConfig) (readerReaderConstructor, bool) {
 	if codec == "multipart" {
 		return func(_ string, r Reader) (Reader, error) {
@@ -218,6 +251,10 @@ func partReader(codec string, conf ReaderConfig) (ReaderConstructor, bool, error
 		}, true, nil
 	case "tar":
 		return newTarReader, true, nil
+	case "avro-ocf":
+		return newAvroOCFReader, true, nil
+	case "parquet":
+		return newParquetReader, true, nil
 	}
 	if strings.HasPrefix(codec, "delim:") {
 		by := strings.TrimPrefix(codec, "delim:")
@@ -251,6 +288,15 @@ func partReader(codec string, conf ReaderConfig) (ReaderConstructor, bool, error
 			return newChunkerReader(conf, r, chunkSize, fn)
 		}, true, nil
 	}
+	if strings.HasPrefix(codec, "length-prefixed:") {
+		readLength, err := getLengthPrefixReadFn(strings.TrimPrefix(codec, "length-prefixed:"))
+		if err != nil {
+			return nil, false, err
+		}
+		return func(path string, r io.ReadCloser, fn ReaderAckFn) (Reader, error) {
+			return newLengthPrefixedReader(conf, r, readLength, fn)
+		}, true, nil
+	}
 	if strings.HasPrefix(codec, "regex:") {
 		by := strings.TrimPrefix(codec, "regex:")
 		if by == "" {
@@ -294,11 +340,19 @@ func autoCodec(conf ReaderConfig) ReaderConstructor {
 			codec = "tar"
 		case ".tgz":
 			codec = "gzip/tar"
+		case ".avro":
+			codec = "avro-ocf"
+		case ".parquet":
+			codec = "parquet"
 		}
 		if strings.HasSuffix(path, ".tar.gzip") {
 			codec = "gzip/tar"
 		} else if strings.HasSuffix(path, ".tar.gz") {
 			codec = "gzip/tar"
+		} else if strings.HasSuffix(path, ".tar.zst") {
+			codec = "zstd/tar"
+		} else if strings.HasSuffix(path, ".csv.zst") {
+			codec = "zstd/csv"
 		}
 
 		ctor, err := GetReader(codec, conf)
@@ -923,3 +977,209 @@ func (a *regexReader) Close(ctx context.Context) error {
 	}
 	return a.r.Close()
 }
+
+//------------------------------------------------------------------------------
+
+type avroOCFReader struct {
+	ocf       *goavro.OCFReader
+	r         io.ReadCloser
+	sourceAck ReaderAckFn
+
+	mut      sync.Mutex
+	finished bool
+	pending  int32
+}
+
+func newAvroOCFReader(path string, r io.ReadCloser, ackFn ReaderAckFn) (Reader, error) {
+	// The OCF reader doesn't tolerate data being returned alongside io.EOF,
+	// which the buffered reader takes care of.
+	ocf, err := goavro.NewOCFReader(bufio.NewReader(r))
+	if err != nil {
+		return nil, err
+	}
+	return &avroOCFReader{
+		ocf:       ocf,
+		r:         r,
+		sourceAck: ackOnce(ackFn),
+	}, nil
+}
+
+func (a *avroOCFReader) ack(ctx context.Context, err error) error {
+	a.mut.Lock()
+	a.pending--
+	doAck := a.pending == 0 && a.finished
+	a.mut.Unlock()
+
+	if err != nil {
+		return a.sourceAck(ctx, err)
+	}
+	if doAck {
+		return a.sourceAck(ctx, nil)
+	}
+	return nil
+}
+
+func (a *avroOCFReader) Next(ctx context.Context) ([]*message.Part, ReaderAckFn, error) {
+	var record interface{}
+	err := io.EOF
+	if a.ocf.Scan() {
+		record, err = a.ocf.Read()
+	} else if scanErr := a.ocf.Err(); scanErr != nil {
+		err = scanErr
+	}
+
+	a.mut.Lock()
+	defer a.mut.Unlock()
+
+	if err != nil {
+		if err == io.EOF {
+			a.finished = true
+		} else {
+			_ = a.sourceAck(ctx, err)
+		}
+		return nil, nil, err
+	}
+
+	a.pending++
+
+	part := message.NewPart(nil)
+	part.SetJSON(record)
+
+	return []*message.Part{part}, a.ack, nil
+}
+
+func (a *avroOCFReader) Close(ctx context.Context) error {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+
+	if !a.finished {
+		_ = a.sourceAck(ctx, errors.New("service shutting down"))
+	}
+	if a.pending == 0 {
+		_ = a.sourceAck(ctx, nil)
+	}
+	return a.r.Close()
+}
+
+//------------------------------------------------------------------------------
+
+type lengthPrefixReadFn func(r *bufio.Reader) (uint64, error)
+
+func getLengthPrefixReadFn(kind string) (lengthPrefixReadFn, error) {
+	switch kind {
+	case "uint32":
+		return func(r *bufio.Reader) (uint64, error) {
+			var lenBytes [4]byte
+			if _, err := io.ReadFull(r, lenBytes[:]); err != nil {
+				return 0, err
+			}
+			return uint64(binary.BigEndian.Uint32(lenBytes[:])), nil
+		}, nil
+	case "varint":
+		return func(r *bufio.Reader) (uint64, error) {
+			return binary.ReadUvarint(r)
+		}, nil
+	}
+	return nil, fmt.Errorf("length prefix type '%v' was not recognised, expected uint32 or varint", kind)
+}
+
+type lengthPrefixedReader struct {
+	buf        *bufio.Reader
+	r          io.ReadCloser
+	readLength lengthPrefixReadFn
+	maxLength  uint64
+	sourceAck  ReaderAckFn
+
+	mut      sync.Mutex
+	finished bool
+	pending  int32
+}
+
+func newLengthPrefixedReader(conf ReaderConfig, r io.ReadCloser, readLength lengthPrefixReadFn, ackFn ReaderAckFn) (Reader, error) {
+	return &lengthPrefixedReader{
+		buf:        bufio.NewReader(r),
+		r:          r,
+		readLength: readLength,
+		maxLength:  uint64(conf.MaxScanTokenSize),
+		sourceAck:  ackOnce(ackFn),
+	}, nil
+}
+
+func (a *lengthPrefixedReader) ack(ctx context.Context, err error) error {
+	a.mut.Lock()
+	a.pending--
+	doAck := a.pending == 0 && a.finished
+	a.mut.Unlock()
+
+	if err != nil {
+		return a.sourceAck(ctx, err)
+	}
+	if doAck {
+		return a.sourceAck(ctx, nil)
+	}
+	return nil
+}
+
+func (a *lengthPrefixedReader) readSegment() ([]byte, error) {
+	length, err := a.readLength(a.buf)
+	if err != nil {
+		if err == io.EOF {
+			return nil, err
+		}
+		if err == io.ErrUnexpectedEOF {
+			return nil, errors.New("unexpected end of input whilst reading length prefix")
+		}
+		return nil, err
+	}
+	if length > a.maxLength {
+		return nil, fmt.Errorf("segment length %v exceeds the maximum buffer size of %v", length, a.maxLength)
+	}
+	segment := make([]byte, length)
+	if _, err := io.ReadFull(a.buf, segment); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, fmt.Errorf("unexpected end of input whilst reading segment of length %v", length)
+		}
+		return nil, err
+	}
+	return segment, nil
+}
+
+func (a *lengthPrefixedReader) Next(ctx context.Context) ([]*message.Part, ReaderAckFn, error) {
+	// The lock isn't held whilst reading in order to avoid blocking acks.
+	a.mut.Lock()
+	finished := a.finished
+	a.mut.Unlock()
+	if finished {
+		return nil, nil, io.EOF
+	}
+
+	segment, err := a.readSegment()
+
+	a.mut.Lock()
+	defer a.mut.Unlock()
+
+	if err != nil {
+		if err == io.EOF {
+			a.finished = true
+		} else {
+			_ = a.sourceAck(ctx, err)
+		}
+		return nil, nil, err
+	}
+
+	a.pending++
+	return []*message.Part{message.NewPart(segment)}, a.ack, nil
+}
+
+func (a *lengthPrefixedReader) Close(ctx context.Context) error {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+
+	if !a.finished {
+		_ = a.sourceAck(ctx, errors.New("service shutting down"))
+	}
+	if a.pending == 0 {
+		_ = a.sourceAck(ctx, nil)
+	}
+	return a.r.Close()
+}
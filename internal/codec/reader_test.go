@@ -5,12 +5,15 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
 	"sync"
 	"testing"
 
+	"github.com/klauspost/compress/zstd"
+	"github.com/linkedin/goavro/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -770,3 +773,92 @@ func TestRegexpSplitReader(t *testing.T) {
 	data = []byte("")
 	testReaderSuite(t, "regex:split", "", data)
 }
+
+func TestZstdLinesReader(t *testing.T) {
+	var zstdBuf bytes.Buffer
+	zw, err := zstd.NewWriter(&zstdBuf)
+	require.NoError(t, err)
+	_, err = zw.Write([]byte("foo\nbar\nbaz"))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	testReaderSuite(t, "zstd/lines", "", zstdBuf.Bytes(), "foo", "bar", "baz")
+}
+
+func TestLengthPrefixedReader(t *testing.T) {
+	input := []string{"foo", "", "bar baz", string(bytes.Repeat([]byte("x"), 300))}
+
+	var uint32Buf, varintBuf bytes.Buffer
+	for _, v := range input {
+		var lenBytes [binary.MaxVarintLen64]byte
+		binary.BigEndian.PutUint32(lenBytes[:], uint32(len(v)))
+		uint32Buf.Write(lenBytes[:4])
+		uint32Buf.WriteString(v)
+
+		varintBuf.Write(lenBytes[:binary.PutUvarint(lenBytes[:], uint64(len(v)))])
+		varintBuf.WriteString(v)
+	}
+
+	testReaderSuite(t, "length-prefixed:uint32", "", uint32Buf.Bytes(), input...)
+	testReaderSuite(t, "length-prefixed:varint", "", varintBuf.Bytes(), input...)
+	testReaderSuite(t, "length-prefixed:varint", "", nil)
+
+	_, err := GetReader("length-prefixed:int8", NewReaderConfig())
+	require.EqualError(t, err, "length prefix type 'int8' was not recognised, expected uint32 or varint")
+}
+
+func TestLengthPrefixedReaderTruncated(t *testing.T) {
+	ctor, err := GetReader("length-prefixed:uint32", NewReaderConfig())
+	require.NoError(t, err)
+
+	var ackErr error
+	r, err := ctor("", noopCloser{bytes.NewReader([]byte{0, 0, 0, 10, 'f', 'o', 'o'}), false}, func(ctx context.Context, err error) error {
+		ackErr = err
+		return nil
+	})
+	require.NoError(t, err)
+
+	_, _, err = r.Next(context.Background())
+	require.EqualError(t, err, "unexpected end of input whilst reading segment of length 10")
+	require.NoError(t, r.Close(context.Background()))
+	assert.EqualError(t, ackErr, err.Error())
+}
+
+func TestAvroOCFReader(t *testing.T) {
+	codec, err := goavro.NewCodec(`{
+  "type": "record",
+  "name": "person",
+  "fields": [
+    { "name": "name", "type": "string" },
+    { "name": "age", "type": "int" }
+  ]
+}`)
+	require.NoError(t, err)
+
+	var ocfBuf bytes.Buffer
+	ocfW, err := goavro.NewOCFWriter(goavro.OCFConfig{W: &ocfBuf, Codec: codec})
+	require.NoError(t, err)
+	require.NoError(t, ocfW.Append([]interface{}{
+		map[string]interface{}{"name": "foo", "age": 20},
+		map[string]interface{}{"name": "bar", "age": 21},
+	}))
+	require.NoError(t, ocfW.Append([]interface{}{
+		map[string]interface{}{"name": "baz", "age": 22},
+	}))
+
+	expected := []string{
+		`{"age":20,"name":"foo"}`,
+		`{"age":21,"name":"bar"}`,
+		`{"age":22,"name":"baz"}`,
+	}
+	testReaderSuite(t, "avro-ocf", "", ocfBuf.Bytes(), expected...)
+	testReaderSuite(t, "auto", "foo.avro", ocfBuf.Bytes(), expected...)
+
+	var gzipBuf bytes.Buffer
+	zw := gzip.NewWriter(&gzipBuf)
+	_, err = zw.Write(ocfBuf.Bytes())
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	testReaderSuite(t, "gzip/avro-ocf", "", gzipBuf.Bytes(), expected...)
+}
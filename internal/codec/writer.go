@@ -2,24 +2,36 @@ package codec
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
+	"os"
 	"strings"
 
+	"github.com/klauspost/compress/zstd"
+	"github.com/linkedin/goavro/v2"
+
 	"github.com/benthosdev/benthos/v4/internal/docs"
 	"github.com/benthosdev/benthos/v4/internal/message"
 )
 
 // WriterDocs is a static field documentation for output codecs.
 var WriterDocs = docs.FieldCommon(
-	"codec", "The way in which the bytes of messages should be written out into the output data stream. It's possible to write lines using a custom delimiter with the `delim:x` codec, where x is the character sequence custom delimiter.", "lines", "delim:\t", "delim:foobar",
+	"codec", "The way in which the bytes of messages should be written out into the output data stream. It's possible to write lines using a custom delimiter with the `delim:x` codec, where x is the character sequence custom delimiter. Codecs can be prefixed with a compression algorithm, for example gzip compressed lines can be written with the codec `gzip/lines`.", "lines", "delim:\t", "delim:foobar", "gzip/lines", "avro-ocf:file://./schema.avsc",
 ).HasAnnotatedOptions(
 	"all-bytes", "Only applicable to file based outputs. Writes each message to a file in full, if the file already exists the old content is deleted.",
 	"append", "Append each message to the output stream without any delimiter or special encoding.",
+	"avro-ocf:x", "Write each message, which must be a JSON document, as a record of an [Avro Object Container File](https://avro.apache.org/docs/current/spec.html#Object+Container+Files) with the schema x. The schema can either be provided inline or be loaded from a file with the prefix `file://`, e.g. `avro-ocf:file://./schema.avsc`. Records are buffered and written as a single block at the end of each batch. When writing to an existing uncompressed local file new records are appended using the schema of the file header. Appending to an existing file that is compressed, rolled with `max_file_size` or remote is not supported and results in an error.",
 	"lines", "Append each message to the output stream followed by a line break.",
+	"length-prefixed:uint32", "Append each message to the output stream preceded by its length as a 32-bit unsigned big-endian integer.",
+	"length-prefixed:varint", "Append each message to the output stream preceded by its length as an unsigned varint, as used by protobuf delimited streams.",
 	"delim:x", "Append each message to the output stream followed by a custom delimiter.",
+	"parquet:x", "Only applicable to file based outputs. Write each message, which must be a JSON document, as a row of a [Parquet file](https://parquet.apache.org/documentation/latest/) with the schema x, where rows are flushed to the file in row groups. The schema uses the format described in the [`parquet` processor](/docs/components/processors/parquet) and can either be provided inline or be loaded from a file with the prefix `file://`, e.g. `parquet:file://./schema.json`. The file is finalised when it is closed, if the file already exists the old content is deleted.",
+	"gzip/x", "Compress the output of codec x with gzip, e.g. `gzip/lines`. The compressed stream is flushed after each message.",
+	"zstd/x", "Compress the output of codec x with zstd, e.g. `zstd/lines`. The compressed stream is flushed after each message.",
 )
 
 //------------------------------------------------------------------------------
@@ -40,6 +52,11 @@ type WriterConfig struct {
 	Append     bool
 	Truncate   bool
 	CloseAfter bool
+
+	// EndEveryBatch indicates that the writer buffers messages until the end
+	// of a batch, and therefore EndBatch must be called after every batch,
+	// including those of a single message.
+	EndEveryBatch bool
 }
 
 // WriterConstructor creates a writer from an io.WriteCloser.
@@ -47,6 +64,16 @@ type WriterConstructor func(io.WriteCloser) (Writer, error)
 
 // GetWriter returns a constructor that creates write codecs.
 func GetWriter(codec string) (WriterConstructor, WriterConfig, error) {
+	for prefix, ctor := range compressors {
+		if strings.HasPrefix(codec, prefix+"/") {
+			child, conf, err := GetWriter(strings.TrimPrefix(codec, prefix+"/"))
+			if err != nil {
+				return nil, WriterConfig{}, err
+			}
+			return newCompressedWriterCtor(ctor, child), conf, nil
+		}
+	}
+
 	switch codec {
 	case "all-bytes":
 		return func(w io.WriteCloser) (Writer, error) {
@@ -68,6 +95,33 @@ func GetWriter(codec string) (WriterConstructor, WriterConfig, error) {
 			return newCustomDelimWriter(w, by)
 		}, customDelimConfig, nil
 	}
+	if strings.HasPrefix(codec, "length-prefixed:") {
+		putLength, err := getLengthPrefixPutFn(strings.TrimPrefix(codec, "length-prefixed:"))
+		if err != nil {
+			return nil, WriterConfig{}, err
+		}
+		return func(w io.WriteCloser) (Writer, error) {
+			return &lengthPrefixedWriter{w: w, putLength: putLength}, nil
+		}, lengthPrefixedConfig, nil
+	}
+	if strings.HasPrefix(codec, "avro-ocf:") {
+		avroCodec, err := avroCodecFromSchema(strings.TrimPrefix(codec, "avro-ocf:"))
+		if err != nil {
+			return nil, WriterConfig{}, err
+		}
+		return func(w io.WriteCloser) (Writer, error) {
+			return newAvroOCFWriter(w, avroCodec)
+		}, avroOCFConfig, nil
+	}
+	if strings.HasPrefix(codec, "parquet:") {
+		schema, err := parquetSchemaFromCodec(strings.TrimPrefix(codec, "parquet:"))
+		if err != nil {
+			return nil, WriterConfig{}, err
+		}
+		return func(w io.WriteCloser) (Writer, error) {
+			return newParquetWriter(w, schema)
+		}, parquetWriterConfig, nil
+	}
 	return nil, WriterConfig{}, fmt.Errorf("codec was not recognised: %v", codec)
 }
 
@@ -166,3 +220,222 @@ func (d *customDelimWriter) EndBatch() error {
 func (d *customDelimWriter) Close(ctx context.Context) error {
 	return d.w.Close()
 }
+
+//------------------------------------------------------------------------------
+
+type compressWriteCloser interface {
+	io.WriteCloser
+	Flush() error
+	Reset(io.Writer)
+}
+
+type compressorCtor func(io.Writer) (compressWriteCloser, error)
+
+var compressors = map[string]compressorCtor{
+	"gzip": func(w io.Writer) (compressWriteCloser, error) {
+		return gzip.NewWriter(w), nil
+	},
+	"zstd": func(w io.Writer) (compressWriteCloser, error) {
+		return zstd.NewWriter(w)
+	},
+}
+
+// compressedCloser closes both the compressor, flushing any remaining data,
+// and the underlying io.WriteCloser.
+type compressedCloser struct {
+	compressWriteCloser
+	w io.WriteCloser
+}
+
+func (c *compressedCloser) Close() error {
+	err := c.compressWriteCloser.Close()
+	if cErr := c.w.Close(); err == nil {
+		err = cErr
+	}
+	return err
+}
+
+// Stat returns the file info of the underlying writer when it is a file, which
+// allows codecs to detect whether they're appending to existing content.
+func (c *compressedCloser) Stat() (os.FileInfo, error) {
+	if s, ok := c.w.(fileStatter); ok {
+		return s.Stat()
+	}
+	return nil, errors.New("underlying writer is not a file")
+}
+
+func newCompressedWriterCtor(compressor compressorCtor, child WriterConstructor) WriterConstructor {
+	return func(w io.WriteCloser) (Writer, error) {
+		c, err := compressor(w)
+		if err != nil {
+			return nil, err
+		}
+		cc := &compressedCloser{compressWriteCloser: c, w: w}
+		inner, err := child(cc)
+		if err != nil {
+			// Discard the output of the compressor so that closing it doesn't
+			// write a trailer to the underlying writer.
+			c.Reset(io.Discard)
+			_ = cc.Close()
+			return nil, err
+		}
+		return &compressedWriter{inner: inner, c: c}, nil
+	}
+}
+
+// compressedWriter flushes the compressor after each write so that consumers
+// of the stream are able to decode messages as soon as they're written.
+type compressedWriter struct {
+	inner Writer
+	c     compressWriteCloser
+}
+
+func (c *compressedWriter) Write(ctx context.Context, p *message.Part) error {
+	if err := c.inner.Write(ctx, p); err != nil {
+		return err
+	}
+	return c.c.Flush()
+}
+
+func (c *compressedWriter) EndBatch() error {
+	if err := c.inner.EndBatch(); err != nil {
+		return err
+	}
+	return c.c.Flush()
+}
+
+func (c *compressedWriter) Close(ctx context.Context) error {
+	return c.inner.Close(ctx)
+}
+
+//------------------------------------------------------------------------------
+
+var lengthPrefixedConfig = WriterConfig{
+	Append: true,
+}
+
+type lengthPrefixPutFn func(b []byte, length uint64) []byte
+
+func getLengthPrefixPutFn(kind string) (lengthPrefixPutFn, error) {
+	switch kind {
+	case "uint32":
+		return func(b []byte, length uint64) []byte {
+			b = b[:4]
+			binary.BigEndian.PutUint32(b, uint32(length))
+			return b
+		}, nil
+	case "varint":
+		return func(b []byte, length uint64) []byte {
+			return b[:binary.PutUvarint(b, length)]
+		}, nil
+	}
+	return nil, fmt.Errorf("length prefix type '%v' was not recognised, expected uint32 or varint", kind)
+}
+
+type lengthPrefixedWriter struct {
+	w         io.WriteCloser
+	putLength lengthPrefixPutFn
+	lenBuf    [binary.MaxVarintLen64]byte
+}
+
+func (l *lengthPrefixedWriter) Write(ctx context.Context, p *message.Part) error {
+	partBytes := p.Get()
+	if _, err := l.w.Write(l.putLength(l.lenBuf[:], uint64(len(partBytes)))); err != nil {
+		return err
+	}
+	_, err := l.w.Write(partBytes)
+	return err
+}
+
+func (l *lengthPrefixedWriter) EndBatch() error {
+	return nil
+}
+
+func (l *lengthPrefixedWriter) Close(ctx context.Context) error {
+	return l.w.Close()
+}
+
+//------------------------------------------------------------------------------
+
+var avroOCFConfig = WriterConfig{
+	Append:        true,
+	EndEveryBatch: true,
+}
+
+func avroCodecFromSchema(schema string) (*goavro.Codec, error) {
+	if strings.HasPrefix(schema, "file://") {
+		schemaBytes, err := os.ReadFile(strings.TrimPrefix(schema, "file://"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read avro schema file: %w", err)
+		}
+		schema = string(schemaBytes)
+	}
+	if schema == "" {
+		return nil, errors.New("avro-ocf codec requires a non-empty schema")
+	}
+	c, err := goavro.NewCodec(schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse avro schema: %w", err)
+	}
+	return c, nil
+}
+
+type avroOCFWriter struct {
+	w   io.WriteCloser
+	ocf *goavro.OCFWriter
+
+	// Records are buffered until the end of a batch so that each batch is
+	// written as a single block.
+	pending []interface{}
+}
+
+type fileStatter interface {
+	Stat() (os.FileInfo, error)
+}
+
+func newAvroOCFWriter(w io.WriteCloser, codec *goavro.Codec) (Writer, error) {
+	// When w is an *os.File with existing content the OCF writer reads the
+	// header of the file and appends records using its schema. Any other
+	// writer results in a new header, which would corrupt an existing file.
+	if _, isFile := w.(*os.File); !isFile {
+		if s, ok := w.(fileStatter); ok {
+			if info, err := s.Stat(); err == nil && info.Size() > 0 {
+				return nil, errors.New("avro-ocf codec is unable to append to an existing file when compressed or rolled")
+			}
+		}
+	}
+	ocf, err := goavro.NewOCFWriter(goavro.OCFConfig{
+		W:     w,
+		Codec: codec,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &avroOCFWriter{w: w, ocf: ocf}, nil
+}
+
+func (a *avroOCFWriter) Write(ctx context.Context, p *message.Part) error {
+	record, _, err := a.ocf.Codec().NativeFromTextual(p.Get())
+	if err != nil {
+		return fmt.Errorf("failed to convert JSON document to avro record: %w", err)
+	}
+	a.pending = append(a.pending, record)
+	return nil
+}
+
+func (a *avroOCFWriter) EndBatch() error {
+	if len(a.pending) == 0 {
+		return nil
+	}
+	err := a.ocf.Append(a.pending)
+	a.pending = nil
+	return err
+}
+
+func (a *avroOCFWriter) Close(ctx context.Context) error {
+	err := a.EndBatch()
+	if cErr := a.w.Close(); err == nil {
+		err = cErr
+	}
+	return err
+}
@@ -0,0 +1,252 @@
+package codec
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+type bufCloser struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (b *bufCloser) Close() error {
+	b.closed = true
+	return nil
+}
+
+func writeAll(t *testing.T, codec string, w io.WriteCloser, msgs ...string) {
+	t.Helper()
+
+	ctor, _, err := GetWriter(codec)
+	require.NoError(t, err)
+
+	wtr, err := ctor(w)
+	require.NoError(t, err)
+
+	for _, m := range msgs {
+		require.NoError(t, wtr.Write(context.Background(), message.NewPart([]byte(m))))
+	}
+	require.NoError(t, wtr.Close(context.Background()))
+}
+
+func readAll(t *testing.T, codec string, data []byte) []string {
+	t.Helper()
+
+	ctor, err := GetReader(codec, NewReaderConfig())
+	require.NoError(t, err)
+
+	r, err := ctor("", noopCloser{bytes.NewReader(data), false}, func(ctx context.Context, err error) error {
+		return nil
+	})
+	require.NoError(t, err)
+
+	var results []string
+	for {
+		parts, ackFn, err := r.Next(context.Background())
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		require.NoError(t, ackFn(context.Background(), nil))
+		for _, p := range parts {
+			results = append(results, string(p.Get()))
+		}
+	}
+	require.NoError(t, r.Close(context.Background()))
+	return results
+}
+
+func TestWriterRoundTrip(t *testing.T) {
+	input := []string{"foo", "bar baz", "buz"}
+
+	for _, test := range []struct {
+		writeCodec string
+		readCodec  string
+	}{
+		{writeCodec: "lines", readCodec: "lines"},
+		{writeCodec: "gzip/lines", readCodec: "gzip/lines"},
+		{writeCodec: "zstd/lines", readCodec: "zstd/lines"},
+		{writeCodec: "delim:X", readCodec: "delim:X"},
+		{writeCodec: "zstd/delim:X", readCodec: "zstd/delim:X"},
+		{writeCodec: "length-prefixed:uint32", readCodec: "length-prefixed:uint32"},
+		{writeCodec: "length-prefixed:varint", readCodec: "length-prefixed:varint"},
+		{writeCodec: "gzip/length-prefixed:varint", readCodec: "gzip/length-prefixed:varint"},
+	} {
+		test := test
+		t.Run(test.writeCodec, func(t *testing.T) {
+			var buf bufCloser
+			writeAll(t, test.writeCodec, &buf, input...)
+			assert.True(t, buf.closed)
+			assert.Equal(t, input, readAll(t, test.readCodec, buf.Bytes()))
+		})
+	}
+}
+
+func TestWriterBadCodecs(t *testing.T) {
+	for codec, errStr := range map[string]string{
+		"nope":                   "codec was not recognised: nope",
+		"gzip/nope":              "codec was not recognised: nope",
+		"length-prefixed:int8":   "length prefix type 'int8' was not recognised, expected uint32 or varint",
+		"avro-ocf:":              "avro-ocf codec requires a non-empty schema",
+		"avro-ocf:{\"type\":123": "failed to parse avro schema",
+	} {
+		_, _, err := GetWriter(codec)
+		require.Error(t, err, codec)
+		assert.Contains(t, err.Error(), errStr, codec)
+	}
+}
+
+const testAvroSchema = `{
+  "type": "record",
+  "name": "person",
+  "fields": [
+    { "name": "name", "type": "string" },
+    { "name": "age", "type": "int" }
+  ]
+}`
+
+func TestAvroOCFWriter(t *testing.T) {
+	input := []string{
+		`{"age":20,"name":"foo"}`,
+		`{"age":21,"name":"bar"}`,
+	}
+
+	var buf bufCloser
+	writeAll(t, "avro-ocf:"+testAvroSchema, &buf, input...)
+	assert.Equal(t, input, readAll(t, "avro-ocf", buf.Bytes()))
+
+	var zbuf bufCloser
+	writeAll(t, "zstd/avro-ocf:"+testAvroSchema, &zbuf, input...)
+	assert.Equal(t, input, readAll(t, "zstd/avro-ocf", zbuf.Bytes()))
+
+	ctor, _, err := GetWriter("avro-ocf:" + testAvroSchema)
+	require.NoError(t, err)
+
+	w, err := ctor(&bufCloser{})
+	require.NoError(t, err)
+	assert.Error(t, w.Write(context.Background(), message.NewPart([]byte(`{"name":"foo"}`))))
+}
+
+func TestAvroOCFWriterAppendFile(t *testing.T) {
+	dir := t.TempDir()
+
+	schemaPath := filepath.Join(dir, "schema.avsc")
+	require.NoError(t, os.WriteFile(schemaPath, []byte(testAvroSchema), 0o644))
+
+	codec := "avro-ocf:file://" + schemaPath
+	_, conf, err := GetWriter(codec)
+	require.NoError(t, err)
+	assert.True(t, conf.Append)
+
+	filePath := filepath.Join(dir, "data.avro")
+	for _, doc := range []string{`{"age":20,"name":"foo"}`, `{"age":21,"name":"bar"}`} {
+		f, err := os.OpenFile(filePath, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+		require.NoError(t, err)
+		writeAll(t, codec, f, doc)
+	}
+
+	data, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`{"age":20,"name":"foo"}`,
+		`{"age":21,"name":"bar"}`,
+	}, readAll(t, "avro-ocf", data))
+}
+
+func TestAvroOCFWriterBlockPerBatch(t *testing.T) {
+	ctor, conf, err := GetWriter("avro-ocf:" + testAvroSchema)
+	require.NoError(t, err)
+	assert.True(t, conf.EndEveryBatch)
+
+	var buf bufCloser
+	w, err := ctor(&buf)
+	require.NoError(t, err)
+
+	headerLen := buf.Len()
+	for _, batch := range [][]string{
+		{`{"age":20,"name":"foo"}`, `{"age":21,"name":"bar"}`, `{"age":22,"name":"baz"}`},
+		{`{"age":23,"name":"buz"}`},
+	} {
+		for _, doc := range batch {
+			require.NoError(t, w.Write(context.Background(), message.NewPart([]byte(doc))))
+		}
+		assert.Equal(t, headerLen, buf.Len())
+		require.NoError(t, w.EndBatch())
+		assert.Greater(t, buf.Len(), headerLen)
+		headerLen = buf.Len()
+	}
+	require.NoError(t, w.Close(context.Background()))
+
+	ocfR, err := goavro.NewOCFReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	// Each batch is written as a single block, and therefore the remaining
+	// items of the current block only reaches zero at the end of a batch.
+	var remaining []int64
+	for ocfR.Scan() {
+		_, err := ocfR.Read()
+		require.NoError(t, err)
+		remaining = append(remaining, ocfR.RemainingBlockItems())
+	}
+	require.NoError(t, ocfR.Err())
+	assert.Equal(t, []int64{2, 1, 0, 0}, remaining)
+}
+
+type wrappedFile struct {
+	*os.File
+}
+
+func TestAvroOCFWriterAppendUnsupported(t *testing.T) {
+	dir := t.TempDir()
+
+	filePath := filepath.Join(dir, "data.avro")
+	f, err := os.Create(filePath)
+	require.NoError(t, err)
+	writeAll(t, "avro-ocf:"+testAvroSchema, f, `{"age":20,"name":"foo"}`)
+
+	for _, test := range []struct {
+		name  string
+		codec string
+		wrap  func(f *os.File) io.WriteCloser
+	}{
+		{
+			name:  "compressed",
+			codec: "gzip/avro-ocf:" + testAvroSchema,
+			wrap:  func(f *os.File) io.WriteCloser { return f },
+		},
+		{
+			name:  "wrapped file",
+			codec: "avro-ocf:" + testAvroSchema,
+			wrap:  func(f *os.File) io.WriteCloser { return wrappedFile{File: f} },
+		},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			ctor, _, err := GetWriter(test.codec)
+			require.NoError(t, err)
+
+			f, err := os.OpenFile(filePath, os.O_RDWR|os.O_APPEND, 0o644)
+			require.NoError(t, err)
+			defer f.Close()
+
+			_, err = ctor(test.wrap(f))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "unable to append to an existing file")
+		})
+	}
+
+	data, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`{"age":20,"name":"foo"}`}, readAll(t, "avro-ocf", data))
+}
@@ -77,7 +77,7 @@ func (s *Reader) ReadWithContext(ctx context.Context) (*message.Batch, reader.As
 		return m, afn, nil
 	}
 
-	if err := tracing.InitSpansFromParentTextMap("input_"+s.inputName, spanMap, m); err != nil {
+	if err := tracing.InitSpansFromParentTextMap(s.mgr.Tracer(), "input_"+s.inputName, spanMap, m); err != nil {
 		s.log.Errorf("Extraction of parent tracing span failed: %v", err)
 	}
 	return m, afn, nil
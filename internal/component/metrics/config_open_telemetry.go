@@ -0,0 +1,26 @@
+package metrics
+
+import (
+	"github.com/benthosdev/benthos/v4/internal/otlp"
+)
+
+// OpenTelemetryConfig is config for the OpenTelemetry metrics type.
+type OpenTelemetryConfig struct {
+	HTTP             []otlp.CollectorConfig `json:"http" yaml:"http"`
+	GRPC             []otlp.CollectorConfig `json:"grpc" yaml:"grpc"`
+	PushInterval     string                 `json:"push_interval" yaml:"push_interval"`
+	HistogramBuckets []float64              `json:"histogram_buckets" yaml:"histogram_buckets"`
+	Tags             map[string]string      `json:"tags" yaml:"tags"`
+}
+
+// NewOpenTelemetryConfig creates an OpenTelemetryConfig struct with default
+// values.
+func NewOpenTelemetryConfig() OpenTelemetryConfig {
+	return OpenTelemetryConfig{
+		HTTP:             []otlp.CollectorConfig{},
+		GRPC:             []otlp.CollectorConfig{},
+		PushInterval:     "10s",
+		HistogramBuckets: []float64{},
+		Tags:             map[string]string{},
+	}
+}
@@ -1,8 +1,17 @@
 package tracer
 
+import (
+	"go.opentelemetry.io/otel/trace"
+)
+
 // Noop is a no-operation implementation of a tracer.
 type Noop struct{}
 
+// TracerProvider returns a provider of spans that are never recorded.
+func (n Noop) TracerProvider() trace.TracerProvider {
+	return trace.NewNoopTracerProvider()
+}
+
 // Close does nothing.
 func (n Noop) Close() error {
 	return nil
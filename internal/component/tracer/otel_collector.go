@@ -0,0 +1,27 @@
+package tracer
+
+import (
+	"github.com/benthosdev/benthos/v4/internal/otlp"
+)
+
+// OpenTelemetryCollectorConfig is config for the OpenTelemetry collector tracer
+// type.
+type OpenTelemetryCollectorConfig struct {
+	HTTP          []otlp.CollectorConfig `json:"http" yaml:"http"`
+	GRPC          []otlp.CollectorConfig `json:"grpc" yaml:"grpc"`
+	SamplingRatio float64                `json:"sampling_ratio" yaml:"sampling_ratio"`
+	Tags          map[string]string      `json:"tags" yaml:"tags"`
+	FlushInterval string                 `json:"flush_interval" yaml:"flush_interval"`
+}
+
+// NewOpenTelemetryCollectorConfig creates an OpenTelemetryCollectorConfig
+// struct with default values.
+func NewOpenTelemetryCollectorConfig() OpenTelemetryCollectorConfig {
+	return OpenTelemetryCollectorConfig{
+		HTTP:          []otlp.CollectorConfig{},
+		GRPC:          []otlp.CollectorConfig{},
+		SamplingRatio: 1.0,
+		Tags:          map[string]string{},
+		FlushInterval: "",
+	}
+}
@@ -347,6 +347,7 @@ func BloblangMethodsMarkdown() ([]byte, error) {
 		query.MethodCategoryObjectAndArray,
 		query.MethodCategoryParsing,
 		query.MethodCategoryEncoding,
+		query.MethodCategoryJWT,
 		query.MethodCategoryGeoIP,
 		query.MethodCategoryDeprecated,
 	} {
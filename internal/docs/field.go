@@ -456,7 +456,7 @@ func NewLintContext() LintContext {
 	return LintContext{
 		LabelsToLine:     map[string]int{},
 		DocsProvider:     globalProvider,
-		BloblangEnv:      bloblang.GlobalEnvironment().Deactivated(),
+		BloblangEnv:      bloblang.GlobalEnvironment().Deactivated().WithTypeChecking(),
 		RejectDeprecated: false,
 	}
 }
@@ -1000,6 +1000,26 @@ func TestYAMLLinting(t *testing.T) {
 				docs.NewLintError(1, "field baz is required"),
 			},
 		},
+		{
+			name: "bloblang type mismatch",
+			inputSpec: docs.FieldCommon("foo", "").WithChildren(
+				docs.FieldBloblang("bar", ""),
+			),
+			inputConf: `bar: |
+  root.a = this.a
+  root.b = this.b.number().uppercase()`,
+			res: []docs.Lint{
+				{
+					Line:   3,
+					Column: 32,
+					Level:  docs.LintError,
+					What: `line 2 char 26: method uppercase: expected string or bytes value, got number from method number
+  |
+2 | root.b = this.b.number().uppercase()
+  |                          ^---`,
+				},
+			},
+		},
 	}
 
 	for _, test := range tests {
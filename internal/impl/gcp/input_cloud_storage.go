@@ -34,7 +34,7 @@ func init() {
 		return input.NewAsyncReader(
 			input.TypeGCPCloudStorage, true,
 			reader.NewAsyncPreserver(r),
-			nm.Logger(), nm.Metrics(),
+			nm,
 		)
 	}), docs.ComponentSpec{
 		Name:    input.TypeGCPCloudStorage,
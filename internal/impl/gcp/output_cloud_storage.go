@@ -13,6 +13,7 @@ import (
 	"github.com/benthosdev/benthos/v4/internal/batch/policy"
 	"github.com/benthosdev/benthos/v4/internal/bloblang/field"
 	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/codec"
 	"github.com/benthosdev/benthos/v4/internal/component"
 	"github.com/benthosdev/benthos/v4/internal/component/metrics"
 	ioutput "github.com/benthosdev/benthos/v4/internal/component/output"
@@ -22,6 +23,7 @@ import (
 	"github.com/benthosdev/benthos/v4/internal/old/input"
 	"github.com/benthosdev/benthos/v4/internal/old/output"
 	"github.com/benthosdev/benthos/v4/internal/old/output/writer"
+	"github.com/benthosdev/benthos/v4/internal/shutdown"
 )
 
 func init() {
@@ -101,7 +103,24 @@ output:
       processors:
         - archive:
             format: json_array
-`+"```"+``),
+`+"```"+`
+
+### Streaming Objects
+
+By default each message is uploaded as an individual object. When a `+"`codec`"+` other than `+"`all-bytes`"+` is set messages are instead streamed into objects with that codec, where an object is opened with the path and properties of its first message and is finalised once the path of a message changes, it reaches `+"`max_file_size`"+` bytes, or the output is closed. The `+"`collision_mode`"+` is applied when an object is opened, and the mode `+"`append`"+` is only supported by codecs that are able to append to existing data. When `+"`max_file_size`"+` is set the path is only resolved when a new object is opened and should therefore resolve to a unique object each time, e.g. `+"`${!count(\"files\")}-${!timestamp_unix_nano()}.parquet`"+`.
+
+This allows large files such as those written with the `+"`parquet:x`"+` codec to be uploaded without holding an entire file in memory, as rows are flushed to the upload one row group at a time:
+
+`+"```yaml"+`
+output:
+  gcp_cloud_storage:
+    bucket: TODO
+    path: ${!count("files")}-${!timestamp_unix_nano()}.parquet
+    codec: parquet:file://./schema.json
+    max_file_size: 1073741824
+`+"```"+`
+
+Messages are acknowledged once they're written to the stream of an object, and therefore messages written to an object that then fails to finalise are lost.`),
 		Config: docs.FieldComponent().WithChildren(
 			docs.FieldCommon("bucket", "The bucket to upload messages to."),
 			docs.FieldCommon(
@@ -121,6 +140,14 @@ output:
 				).AtVersion("3.53.0"),
 			docs.FieldAdvanced("content_encoding", "An optional content encoding to set for each object.").IsInterpolated(),
 			docs.FieldAdvanced("chunk_size", "An optional chunk size which controls the maximum number of bytes of the object that the Writer will attempt to send to the server in a single request. If ChunkSize is set to zero, chunking will be disabled."),
+			docs.FieldAdvanced(
+				"codec", "The codec used for writing messages to objects. With the default `all-bytes` each message is uploaded as an individual object, any other codec streams messages into objects as described in [streaming objects](#streaming-objects). The available codecs are the same as those of the [`file` output](/docs/components/outputs/file#codec).",
+				"lines", "gzip/lines", "parquet:file://./schema.json",
+			),
+			docs.FieldAdvanced(
+				"max_file_size", "An optional maximum size in bytes of each streamed object, once an object exceeds this size it is finalised and the path is resolved again for the next message. When set to zero objects are not rolled.",
+				1073741824,
+			),
 			docs.FieldCommon("max_in_flight", "The maximum number of messages to have in flight at a given time. Increase this to improve throughput."),
 			policy.FieldSpec(),
 		).ChildDefaultAndTypesFromStruct(output.NewGCPCloudStorageConfig()),
@@ -139,8 +166,13 @@ type gcpCloudStorageOutput struct {
 	client  *storage.Client
 	connMut sync.RWMutex
 
-	log   log.Modular
-	stats metrics.Type
+	// When a codec other than all-bytes is configured messages are streamed
+	// into objects rather than uploaded individually.
+	streamer *writer.ObjectStreamer
+
+	log     log.Modular
+	stats   metrics.Type
+	shutSig *shutdown.Signaller
 }
 
 // newGCPCloudStorageOutput creates a new GCP Cloud Storage bucket writer.Type.
@@ -151,9 +183,10 @@ func newGCPCloudStorageOutput(
 	stats metrics.Type,
 ) (*gcpCloudStorageOutput, error) {
 	g := &gcpCloudStorageOutput{
-		conf:  conf,
-		log:   log,
-		stats: stats,
+		conf:    conf,
+		log:     log,
+		stats:   stats,
+		shutSig: shutdown.NewSignaller(),
 	}
 
 	bEnv := mgr.BloblEnvironment()
@@ -169,6 +202,18 @@ func newGCPCloudStorageOutput(
 		return nil, fmt.Errorf("failed to parse content encoding expression: %v", err)
 	}
 
+	if conf.Codec != "all-bytes" {
+		_, codecConf, err := codec.GetWriter(conf.Codec)
+		if err != nil {
+			return nil, err
+		}
+		if conf.CollisionMode == output.GCPCloudStorageAppendCollisionMode && !codecConf.Append {
+			return nil, fmt.Errorf("collision_mode %v is not supported by codec %v", conf.CollisionMode, conf.Codec)
+		}
+		if g.streamer, err = writer.NewObjectStreamer(conf.Codec, conf.MaxFileSize, g.path, g.openUpload); err != nil {
+			return nil, err
+		}
+	}
 	return g, nil
 }
 
@@ -199,51 +244,18 @@ func (g *gcpCloudStorageOutput) WriteWithContext(ctx context.Context, msg *messa
 		return component.ErrNotConnected
 	}
 
-	return writer.IterateBatchedSend(msg, func(i int, p *message.Part) error {
-		metadata := map[string]string{}
-		_ = p.MetaIter(func(k, v string) error {
-			metadata[k] = v
-			return nil
-		})
+	if g.streamer != nil {
+		return g.streamer.WriteWithContext(ctx, msg)
+	}
 
+	return writer.IterateBatchedSend(msg, func(i int, p *message.Part) error {
 		outputPath := g.path.String(i, msg)
-		var err error
-		if g.conf.CollisionMode != output.GCPCloudStorageOverwriteCollisionMode {
-			_, err = client.Bucket(g.conf.Bucket).Object(outputPath).Attrs(ctx)
-		}
-
-		isMerge := false
-		var tempPath string
-		if err == storage.ErrObjectNotExist || g.conf.CollisionMode == output.GCPCloudStorageOverwriteCollisionMode {
-			tempPath = outputPath
-		} else {
-			isMerge = true
-
-			if g.conf.CollisionMode == output.GCPCloudStorageErrorIfExistsCollisionMode {
-				if err == nil {
-					err = fmt.Errorf("file at path already exists: %s", outputPath)
-				}
-				return err
-			} else if g.conf.CollisionMode == output.GCPCloudStorageIgnoreCollisionMode {
-				return nil
-			}
-
-			tempUUID, err := uuid.NewV4()
-			if err != nil {
-				return err
-			}
-
-			dir := path.Dir(outputPath)
-			tempFileName := fmt.Sprintf("%s.tmp", tempUUID.String())
-			tempPath = path.Join(dir, tempFileName)
+		tempPath, err := g.uploadPath(ctx, client, outputPath)
+		if err != nil || tempPath == "" {
+			return err
 		}
 
-		w := client.Bucket(g.conf.Bucket).Object(tempPath).NewWriter(ctx)
-
-		w.ChunkSize = g.conf.ChunkSize
-		w.ContentType = g.contentType.String(i, msg)
-		w.ContentEncoding = g.contentEncoding.String(i, msg)
-		w.Metadata = metadata
+		w := g.objectWriter(ctx, client, tempPath, i, msg)
 		if _, err = w.Write(p.Get()); err != nil {
 			return err
 		}
@@ -252,31 +264,150 @@ func (g *gcpCloudStorageOutput) WriteWithContext(ctx context.Context, msg *messa
 			return err
 		}
 
-		if isMerge {
+		if tempPath != outputPath {
 			if err := g.appendToFile(ctx, tempPath, outputPath); err != nil {
 				return err
 			}
 		}
+		return nil
+	})
+}
 
-		return err
+// uploadPath determines the path that an object should be uploaded to based
+// on the collision mode. When the path differs from the output path the object
+// must be appended to the output path once uploaded, and an empty path
+// indicates that the object should be dropped.
+func (g *gcpCloudStorageOutput) uploadPath(ctx context.Context, client *storage.Client, outputPath string) (string, error) {
+	var err error
+	if g.conf.CollisionMode != output.GCPCloudStorageOverwriteCollisionMode {
+		_, err = client.Bucket(g.conf.Bucket).Object(outputPath).Attrs(ctx)
+	}
+
+	if err == storage.ErrObjectNotExist || g.conf.CollisionMode == output.GCPCloudStorageOverwriteCollisionMode {
+		return outputPath, nil
+	}
+
+	if g.conf.CollisionMode == output.GCPCloudStorageErrorIfExistsCollisionMode {
+		if err == nil {
+			err = fmt.Errorf("file at path already exists: %s", outputPath)
+		}
+		return "", err
+	} else if g.conf.CollisionMode == output.GCPCloudStorageIgnoreCollisionMode {
+		return "", nil
+	}
+
+	tempUUID, err := uuid.NewV4()
+	if err != nil {
+		return "", err
+	}
+
+	dir := path.Dir(outputPath)
+	tempFileName := fmt.Sprintf("%s.tmp", tempUUID.String())
+	return path.Join(dir, tempFileName), nil
+}
+
+// objectWriter creates a writer for an object, where the properties of the
+// object are derived from the message at index i of a batch.
+func (g *gcpCloudStorageOutput) objectWriter(ctx context.Context, client *storage.Client, objectPath string, i int, msg *message.Batch) *storage.Writer {
+	metadata := map[string]string{}
+	_ = msg.Get(i).MetaIter(func(k, v string) error {
+		metadata[k] = v
+		return nil
 	})
+
+	w := client.Bucket(g.conf.Bucket).Object(objectPath).NewWriter(ctx)
+
+	w.ChunkSize = g.conf.ChunkSize
+	w.ContentType = g.contentType.String(i, msg)
+	w.ContentEncoding = g.contentEncoding.String(i, msg)
+	w.Metadata = metadata
+	return w
+}
+
+// openUpload starts a streamed upload of an object, which is sent to the
+// server in chunks of the configured chunk size.
+func (g *gcpCloudStorageOutput) openUpload(ctx context.Context, outputPath string, i int, msg *message.Batch) (writer.ObjectUpload, error) {
+	g.connMut.RLock()
+	client := g.client
+	g.connMut.RUnlock()
+
+	if client == nil {
+		return nil, component.ErrNotConnected
+	}
+
+	tempPath, err := g.uploadPath(ctx, client, outputPath)
+	if err != nil || tempPath == "" {
+		return nil, err
+	}
+
+	// The upload outlives the write that opened it and is therefore given its
+	// own context, which is cancelled in order to abort the upload.
+	uploadCtx, cancel := context.WithCancel(context.Background())
+	u := &gcsUpload{
+		w:      g.objectWriter(uploadCtx, client, tempPath, i, msg),
+		cancel: cancel,
+	}
+	if tempPath != outputPath {
+		u.finalise = func() error {
+			return g.appendToFile(context.Background(), tempPath, outputPath)
+		}
+	}
+	return u, nil
+}
+
+// gcsUpload streams the bytes written to it to an object, and appends the
+// object to an existing object once closed when required.
+type gcsUpload struct {
+	w        *storage.Writer
+	cancel   func()
+	finalise func() error
+}
+
+func (u *gcsUpload) Write(p []byte) (int, error) {
+	return u.w.Write(p)
+}
+
+func (u *gcsUpload) Close() error {
+	err := u.w.Close()
+	u.cancel()
+	if err == nil && u.finalise != nil {
+		err = u.finalise()
+	}
+	return err
+}
+
+func (u *gcsUpload) Abort() {
+	u.cancel()
+	_ = u.w.Close()
 }
 
 // CloseAsync begins cleaning up resources used by this reader asynchronously.
 func (g *gcpCloudStorageOutput) CloseAsync() {
 	go func() {
+		if g.streamer != nil {
+			if err := g.streamer.Close(context.Background()); err != nil {
+				g.log.Errorf("Failed to finalise object: %v\n", err)
+			}
+		}
+
 		g.connMut.Lock()
 		if g.client != nil {
 			g.client.Close()
 			g.client = nil
 		}
 		g.connMut.Unlock()
+		g.shutSig.ShutdownComplete()
 	}()
 }
 
 // WaitForClose will block until either the reader is closed or a specified
 // timeout occurs.
-func (g *gcpCloudStorageOutput) WaitForClose(time.Duration) error {
+func (g *gcpCloudStorageOutput) WaitForClose(timeout time.Duration) error {
+	select {
+	case <-g.shutSig.HasClosedChan():
+	case <-time.After(timeout):
+		return component.ErrTimeout
+	}
 	return nil
 }
 
@@ -0,0 +1,205 @@
+package generic
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/benthosdev/benthos/v4/internal/batch"
+	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func countWindowBufferConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Windowing").
+		Summary("Chops a stream of messages into tumbling or sliding windows of a fixed number of messages.").
+		Description(`
+A window is a grouping of a fixed ` + "[`count`](#count)" + ` of consecutive messages. In tumbling mode (default) the beginning of a window immediately follows the end of the prior window, and therefore each message belongs to exactly one window.
+
+## Sliding Windows
+
+Sliding windows begin a ` + "[`slide`](#slide)" + ` number of messages after the beginning of the prior window rather than its end, and therefore messages may belong to multiple windows. In order to produce sliding windows specify a slide smaller than the count.
+
+## Delivery Guarantees
+
+This buffer honours the transaction model within Benthos in order to ensure that messages are not acknowledged until they are successfully delivered to outputs.
+
+When this buffer is configured with a slide it is possible for messages to belong to multiple windows, and therefore be delivered multiple times. In this case the first time the message is delivered it will be acked (or nacked) and subsequent deliveries of the same message will be a "best attempt".
+
+During graceful termination if the current window is partially populated with messages they will be nacked such that they are re-consumed the next time the service starts.
+`).
+		Field(service.NewIntField("count").
+			Description("The number of messages within each window.").
+			Example(10).Example(100)).
+		Field(service.NewIntField("slide").
+			Description("An optional number of messages describing how many messages the beginning of each window should be offset from the beginning of the previous, and therefore creates sliding windows instead of tumbling. When specified this number must be smaller than the `count` of the window.").
+			Default(0).
+			Example(1).Example(5))
+}
+
+func init() {
+	err := service.RegisterBatchBuffer(
+		"count_window", countWindowBufferConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchBuffer, error) {
+			count, err := conf.FieldInt("count")
+			if err != nil {
+				return nil, err
+			}
+			if count <= 0 {
+				return nil, fmt.Errorf("invalid window count '%v' must be greater than zero", count)
+			}
+			slide, err := conf.FieldInt("slide")
+			if err != nil {
+				return nil, err
+			}
+			if slide < 0 || slide >= count {
+				return nil, fmt.Errorf("invalid window slide '%v' must be lower than the count '%v'", slide, count)
+			}
+			return newCountWindowBuffer(count, slide), nil
+		})
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type countWindowBuffer struct {
+	count, slide int
+
+	pending    []*tsMessage
+	cond       *sync.Cond
+	endOfInput bool
+	closed     bool
+}
+
+func newCountWindowBuffer(count, slide int) *countWindowBuffer {
+	if slide == 0 {
+		slide = count
+	}
+	return &countWindowBuffer{
+		count: count,
+		slide: slide,
+		cond:  sync.NewCond(&sync.Mutex{}),
+	}
+}
+
+func (w *countWindowBuffer) WriteBatch(ctx context.Context, msgBatch service.MessageBatch, aFn service.AckFunc) error {
+	w.cond.L.Lock()
+	defer w.cond.L.Unlock()
+
+	// Apply back pressure whilst a complete window is waiting to be read.
+	for len(w.pending) >= w.count {
+		if w.closed {
+			return component.ErrTypeClosed
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		w.waitWithContext(ctx)
+	}
+	if w.closed {
+		return component.ErrTypeClosed
+	}
+
+	aggregatedAck := batch.NewCombinedAcker(batch.AckFunc(aFn))
+	for _, msg := range msgBatch {
+		w.pending = append(w.pending, &tsMessage{
+			m: msg, ackFn: service.AckFunc(aggregatedAck.Derive()),
+		})
+	}
+
+	w.cond.Broadcast()
+	return nil
+}
+
+// waitWithContext waits for the condition to be broadcast, or for the context
+// to be cancelled, in which case a broadcast is triggered in order to wake the
+// waiting caller. Must be called whilst holding the condition lock.
+func (w *countWindowBuffer) waitWithContext(ctx context.Context) {
+	waitDone := make(chan struct{})
+	defer close(waitDone)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			w.cond.L.Lock()
+			w.cond.Broadcast()
+			w.cond.L.Unlock()
+		case <-waitDone:
+		}
+	}()
+	w.cond.Wait()
+}
+
+func (w *countWindowBuffer) ReadBatch(ctx context.Context) (service.MessageBatch, service.AckFunc, error) {
+	ctx, done := context.WithCancel(ctx)
+	defer done()
+
+	go func() {
+		<-ctx.Done()
+		w.cond.L.Lock()
+		w.cond.Broadcast()
+		w.cond.L.Unlock()
+	}()
+
+	w.cond.L.Lock()
+	defer w.cond.L.Unlock()
+
+	for len(w.pending) < w.count {
+		if w.closed {
+			return nil, nil, service.ErrEndOfBuffer
+		}
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+		if w.endOfInput {
+			// Nack all pending messages so that we re-consume them on the next
+			// start up.
+			for _, pending := range w.pending {
+				_ = pending.ackFn(ctx, errWindowClosed)
+			}
+			w.pending = nil
+			return nil, nil, service.ErrEndOfBuffer
+		}
+		w.cond.Wait()
+	}
+
+	flushBatch := make(service.MessageBatch, 0, w.count)
+	flushAcks := make([]service.AckFunc, 0, w.count)
+	for _, pending := range w.pending[:w.count] {
+		flushBatch = append(flushBatch, pending.m.Copy())
+		flushAcks = append(flushAcks, pending.ackFn)
+	}
+
+	for i := 0; i < w.slide; i++ {
+		w.pending[i] = nil
+	}
+	w.pending = w.pending[w.slide:]
+	w.cond.Broadcast()
+
+	return flushBatch, func(ctx context.Context, err error) error {
+		for _, aFn := range flushAcks {
+			_ = aFn(ctx, err)
+		}
+		return nil
+	}, nil
+}
+
+func (w *countWindowBuffer) EndOfInput() {
+	w.cond.L.Lock()
+	w.endOfInput = true
+	w.cond.Broadcast()
+	w.cond.L.Unlock()
+}
+
+func (w *countWindowBuffer) Close(ctx context.Context) error {
+	w.cond.L.Lock()
+	w.closed = true
+	w.cond.Broadcast()
+	w.cond.L.Unlock()
+	return nil
+}
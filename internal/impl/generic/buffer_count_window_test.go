@@ -0,0 +1,143 @@
+package generic
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func countWindowFromConf(t *testing.T, conf string) *countWindowBuffer {
+	t.Helper()
+
+	parsedConf, err := countWindowBufferConfig().ParseYAML(conf, nil)
+	require.NoError(t, err)
+
+	count, err := parsedConf.FieldInt("count")
+	require.NoError(t, err)
+
+	slide, err := parsedConf.FieldInt("slide")
+	require.NoError(t, err)
+
+	return newCountWindowBuffer(count, slide)
+}
+
+func countWindowContents(t *testing.T, batch service.MessageBatch) []string {
+	t.Helper()
+
+	var contents []string
+	for _, m := range batch {
+		mBytes, err := m.AsBytes()
+		require.NoError(t, err)
+		contents = append(contents, string(mBytes))
+	}
+	return contents
+}
+
+func TestCountWindowTumbling(t *testing.T) {
+	w := countWindowFromConf(t, `count: 3`)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	acked := map[int]error{}
+	go func() {
+		for i := 0; i < 7; i++ {
+			i := i
+			assert.NoError(t, w.WriteBatch(ctx, service.MessageBatch{
+				service.NewMessage([]byte(fmt.Sprintf("m%v", i))),
+			}, func(ctx context.Context, err error) error {
+				acked[i] = err
+				return nil
+			}))
+		}
+		w.EndOfInput()
+	}()
+
+	b, aFn, err := w.ReadBatch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"m0", "m1", "m2"}, countWindowContents(t, b))
+	require.NoError(t, aFn(ctx, nil))
+
+	b, aFn, err = w.ReadBatch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"m3", "m4", "m5"}, countWindowContents(t, b))
+	require.NoError(t, aFn(ctx, nil))
+
+	_, _, err = w.ReadBatch(ctx)
+	require.Equal(t, service.ErrEndOfBuffer, err)
+
+	assert.Equal(t, map[int]error{
+		0: nil, 1: nil, 2: nil, 3: nil, 4: nil, 5: nil, 6: errWindowClosed,
+	}, acked)
+}
+
+func TestCountWindowSliding(t *testing.T) {
+	w := countWindowFromConf(t, `
+count: 3
+slide: 1
+`)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	go func() {
+		for i := 0; i < 5; i++ {
+			assert.NoError(t, w.WriteBatch(ctx, service.MessageBatch{
+				service.NewMessage([]byte(fmt.Sprintf("m%v", i))),
+			}, func(ctx context.Context, err error) error { return nil }))
+		}
+		w.EndOfInput()
+	}()
+
+	for _, exp := range [][]string{
+		{"m0", "m1", "m2"},
+		{"m1", "m2", "m3"},
+		{"m2", "m3", "m4"},
+	} {
+		b, aFn, err := w.ReadBatch(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, exp, countWindowContents(t, b))
+		require.NoError(t, aFn(ctx, nil))
+	}
+
+	_, _, err := w.ReadBatch(ctx)
+	require.Equal(t, service.ErrEndOfBuffer, err)
+}
+
+func TestCountWindowWriteCancelled(t *testing.T) {
+	w := countWindowFromConf(t, `count: 1`)
+
+	noopAck := func(ctx context.Context, err error) error { return nil }
+	require.NoError(t, w.WriteBatch(context.Background(), service.MessageBatch{
+		service.NewMessage([]byte("m0")),
+	}, noopAck))
+
+	// The window is full and therefore the next write blocks until cancelled.
+	ctx, done := context.WithCancel(context.Background())
+	errChan := make(chan error)
+	go func() {
+		errChan <- w.WriteBatch(ctx, service.MessageBatch{
+			service.NewMessage([]byte("m1")),
+		}, noopAck)
+	}()
+
+	select {
+	case err := <-errChan:
+		t.Fatalf("write returned early: %v", err)
+	case <-time.After(time.Millisecond * 50):
+	}
+
+	done()
+	select {
+	case err := <-errChan:
+		assert.Equal(t, context.Canceled, err)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for cancelled write")
+	}
+}
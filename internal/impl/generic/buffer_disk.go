@@ -0,0 +1,754 @@
+package generic
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+const (
+	diskSyncAlways   = "always"
+	diskSyncInterval = "interval"
+	diskSyncNever    = "never"
+
+	diskSegmentExt = ".wal"
+	diskAckExt     = ".ack"
+
+	// Each record is prefixed with a big endian uint32 length followed by a
+	// big endian uint32 CRC-32 (IEEE) checksum of the payload.
+	diskRecordHeaderLen = 8
+)
+
+func diskBufferConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Utility").
+		Summary("Stores consumed message batches within a write-ahead log on disk and acknowledges them at the input level. Batches that have not been acknowledged downstream are replayed when Benthos restarts.").
+		Description(`
+This buffer is appropriate when consuming messages from inputs that do not gracefully handle back pressure, or when the input needs to be decoupled from the rest of the pipeline, but where messages must survive a crash or restart of Benthos.
+
+Each batch written to the buffer is appended to a segment file within the configured directory. Once a segment reaches the configured ` + "`segment_size`" + ` a new segment is started, and segments are deleted from disk once all batches within them have been acknowledged downstream.
+
+## Delivery Guarantees
+
+Batches are acknowledged at the input level once they have been written to the log, and are only removed from the log once they have been acknowledged downstream. When Benthos is restarted any batches that remain within the log are read again, which means this buffer offers at-least-once delivery and batches may be duplicated when acknowledgements arrive out of order before a restart.
+
+The field ` + "`sync`" + ` determines when data written to the log is flushed to the underlying storage device. With the policy ` + "`always`" + ` batches are flushed before they are acknowledged at the input level, and therefore no data is lost even when the host machine itself crashes. The policies ` + "`interval`" + ` and ` + "`never`" + ` protect against the Benthos process crashing but may lose recently written batches when the host machine crashes.`).
+		Field(service.NewStringField("directory").
+			Description("A directory within which to store the log segments. The directory is created if it does not already exist, and must not be shared with any other disk buffer.").
+			Example("/var/lib/benthos/buffer")).
+		Field(service.NewIntField("segment_size").
+			Description("The maximum size (in bytes) of each segment file before a new segment is started. Segments are only deleted once every batch within them has been acknowledged, and therefore smaller segments reclaim disk space sooner at the cost of more files.").
+			Default(67108864).
+			Advanced()).
+		Field(service.NewStringAnnotatedEnumField("sync", map[string]string{
+			diskSyncAlways:   "Flush the log to disk after every write and before acknowledging the batch at the input level.",
+			diskSyncInterval: "Flush the log to disk periodically according to `sync_interval`.",
+			diskSyncNever:    "Never explicitly flush the log, leaving it to the operating system.",
+		}).
+			Description("The policy that determines when writes to the log are flushed to disk.").
+			Default(diskSyncInterval)).
+		Field(service.NewDurationField("sync_interval").
+			Description("The period at which the log is flushed to disk when `sync` is set to `interval`.").
+			Default("1s").
+			Advanced())
+}
+
+func init() {
+	err := service.RegisterBatchBuffer(
+		"disk", diskBufferConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchBuffer, error) {
+			return newDiskBufferFromConfig(conf, mgr.Logger())
+		})
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+func newDiskBufferFromConfig(conf *service.ParsedConfig, log *service.Logger) (*diskBuffer, error) {
+	dir, err := conf.FieldString("directory")
+	if err != nil {
+		return nil, err
+	}
+	segmentSize, err := conf.FieldInt("segment_size")
+	if err != nil {
+		return nil, err
+	}
+	if segmentSize <= 0 {
+		return nil, fmt.Errorf("invalid segment_size: %v", segmentSize)
+	}
+	syncPolicy, err := conf.FieldString("sync")
+	if err != nil {
+		return nil, err
+	}
+	syncInterval, err := conf.FieldDuration("sync_interval")
+	if err != nil {
+		return nil, err
+	}
+	if syncPolicy == diskSyncInterval && syncInterval <= 0 {
+		return nil, fmt.Errorf("invalid sync_interval: %v", syncInterval)
+	}
+	return newDiskBuffer(dir, int64(segmentSize), syncPolicy, syncInterval, log)
+}
+
+//------------------------------------------------------------------------------
+
+// diskSegment tracks the state of a single log segment file.
+type diskSegment struct {
+	id   uint64
+	size int64
+
+	// committed is the offset of the first record within the segment that has
+	// not yet been acknowledged, and ackedAhead tracks records beyond that
+	// offset that were acknowledged out of order (start offset to end offset).
+	committed  int64
+	ackedAhead map[int64]int64
+	ackDirty   bool
+	sealed     bool
+}
+
+func (s *diskSegment) ack(from, to int64) {
+	if from != s.committed {
+		s.ackedAhead[from] = to
+		return
+	}
+	s.committed = to
+	for {
+		next, exists := s.ackedAhead[s.committed]
+		if !exists {
+			break
+		}
+		delete(s.ackedAhead, s.committed)
+		s.committed = next
+	}
+	s.ackDirty = true
+}
+
+func (s *diskSegment) done() bool {
+	return s.sealed && s.committed >= s.size
+}
+
+type diskRetry struct {
+	seg      *diskSegment
+	from, to int64
+	payload  []byte
+}
+
+type diskBuffer struct {
+	log          *service.Logger
+	dir          string
+	segmentSize  int64
+	syncPolicy   string
+	syncInterval time.Duration
+
+	cond     *sync.Cond
+	segments []*diskSegment
+
+	writeSeg   *diskSegment
+	writeFile  *os.File
+	writeDirty bool
+
+	readSeg    *diskSegment
+	readFile   *os.File
+	readOffset int64
+
+	retries []diskRetry
+	pending int
+
+	endOfInput bool
+	closed     bool
+	closeChan  chan struct{}
+}
+
+func newDiskBuffer(dir string, segmentSize int64, syncPolicy string, syncInterval time.Duration, log *service.Logger) (*diskBuffer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	d := &diskBuffer{
+		log:          log,
+		dir:          dir,
+		segmentSize:  segmentSize,
+		syncPolicy:   syncPolicy,
+		syncInterval: syncInterval,
+		cond:         sync.NewCond(&sync.Mutex{}),
+		closeChan:    make(chan struct{}),
+	}
+
+	if err := d.recover(); err != nil {
+		return nil, err
+	}
+	if err := d.rotate(); err != nil {
+		return nil, err
+	}
+	if err := d.openReader(d.segments[0]); err != nil {
+		return nil, err
+	}
+
+	if syncPolicy == diskSyncInterval {
+		go d.syncLoop()
+	}
+	return d, nil
+}
+
+func (d *diskBuffer) segmentPath(id uint64, ext string) string {
+	return filepath.Join(d.dir, fmt.Sprintf("%020d%v", id, ext))
+}
+
+// recover scans the directory for segments left over from a previous run,
+// truncating any partially written records at the tail of each.
+func (d *diskBuffer) recover() error {
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return err
+	}
+
+	var ids []uint64
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, diskSegmentExt) {
+			continue
+		}
+		id, err := strconv.ParseUint(strings.TrimSuffix(name, diskSegmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	for _, id := range ids {
+		seg, err := d.recoverSegment(id)
+		if err != nil {
+			return fmt.Errorf("failed to recover segment %v: %w", id, err)
+		}
+		if seg.done() {
+			d.removeSegmentFiles(seg)
+			continue
+		}
+		if remaining := seg.size - seg.committed; remaining > 0 {
+			d.log.Infof("Replaying %v bytes of unacknowledged data from buffer segment %v", remaining, id)
+		}
+		d.segments = append(d.segments, seg)
+	}
+	return nil
+}
+
+func (d *diskBuffer) recoverSegment(id uint64) (*diskSegment, error) {
+	f, err := os.OpenFile(d.segmentPath(id, diskSegmentExt), os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	seg := &diskSegment{
+		id:         id,
+		ackedAhead: map[int64]int64{},
+		sealed:     true,
+	}
+
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	var offset int64
+	for {
+		_, n, err := readDiskRecord(f, offset, stat.Size())
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				d.log.Warnf("Truncating buffer segment %v at offset %v: %v", id, offset, err)
+			}
+			break
+		}
+		offset += n
+	}
+
+	if stat.Size() != offset {
+		if err := f.Truncate(offset); err != nil {
+			return nil, err
+		}
+	}
+	seg.size = offset
+
+	if ackBytes, err := os.ReadFile(d.segmentPath(id, diskAckExt)); err == nil && len(ackBytes) == 8 {
+		seg.committed = int64(binary.BigEndian.Uint64(ackBytes))
+		if seg.committed > seg.size {
+			seg.committed = seg.size
+		}
+	}
+	return seg, nil
+}
+
+func (d *diskBuffer) removeSegmentFiles(seg *diskSegment) {
+	for _, ext := range []string{diskSegmentExt, diskAckExt} {
+		if err := os.Remove(d.segmentPath(seg.id, ext)); err != nil && !os.IsNotExist(err) {
+			d.log.Errorf("Failed to remove buffer segment file: %v", err)
+		}
+	}
+}
+
+// rotate seals the current write segment (if any) and starts a new one.
+func (d *diskBuffer) rotate() error {
+	var nextID uint64
+	if l := len(d.segments); l > 0 {
+		nextID = d.segments[l-1].id + 1
+	}
+
+	if d.writeFile != nil {
+		if d.syncPolicy != diskSyncNever {
+			if err := d.writeFile.Sync(); err != nil {
+				return err
+			}
+		}
+		if err := d.writeFile.Close(); err != nil {
+			return err
+		}
+		d.writeFile = nil
+		d.writeDirty = false
+		d.writeSeg.sealed = true
+		d.flushAcks()
+	}
+
+	f, err := os.OpenFile(d.segmentPath(nextID, diskSegmentExt), os.O_WRONLY|os.O_CREATE|os.O_EXCL|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	seg := &diskSegment{
+		id:         nextID,
+		ackedAhead: map[int64]int64{},
+	}
+	d.segments = append(d.segments, seg)
+	d.writeSeg, d.writeFile = seg, f
+	d.compact()
+	return nil
+}
+
+func (d *diskBuffer) openReader(seg *diskSegment) error {
+	if d.readFile != nil {
+		_ = d.readFile.Close()
+		d.readFile = nil
+	}
+	f, err := os.Open(d.segmentPath(seg.id, diskSegmentExt))
+	if err != nil {
+		return err
+	}
+	d.readSeg, d.readFile, d.readOffset = seg, f, seg.committed
+	return nil
+}
+
+// compact removes all segments that have been both fully read and fully
+// acknowledged.
+func (d *diskBuffer) compact() {
+	remaining := d.segments[:0]
+	for _, seg := range d.segments {
+		if seg != d.readSeg && seg.done() {
+			d.removeSegmentFiles(seg)
+			continue
+		}
+		remaining = append(remaining, seg)
+	}
+	for i := len(remaining); i < len(d.segments); i++ {
+		d.segments[i] = nil
+	}
+	d.segments = remaining
+}
+
+// flushAcks persists the acknowledged offset of each segment that has changed
+// since the last flush.
+func (d *diskBuffer) flushAcks() {
+	for _, seg := range d.segments {
+		if !seg.ackDirty || seg.done() {
+			continue
+		}
+		var ackBytes [8]byte
+		binary.BigEndian.PutUint64(ackBytes[:], uint64(seg.committed))
+
+		tmpPath := d.segmentPath(seg.id, diskAckExt+".tmp")
+		if err := os.WriteFile(tmpPath, ackBytes[:], 0o644); err != nil {
+			d.log.Errorf("Failed to write buffer acknowledgement file: %v", err)
+			continue
+		}
+		if err := os.Rename(tmpPath, d.segmentPath(seg.id, diskAckExt)); err != nil {
+			d.log.Errorf("Failed to write buffer acknowledgement file: %v", err)
+			continue
+		}
+		seg.ackDirty = false
+	}
+}
+
+func (d *diskBuffer) syncLoop() {
+	ticker := time.NewTicker(d.syncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-d.closeChan:
+			return
+		}
+		d.cond.L.Lock()
+		if d.writeDirty && d.writeFile != nil {
+			if err := d.writeFile.Sync(); err != nil {
+				d.log.Errorf("Failed to sync buffer segment: %v", err)
+			} else {
+				d.writeDirty = false
+			}
+		}
+		d.flushAcks()
+		d.cond.L.Unlock()
+	}
+}
+
+//------------------------------------------------------------------------------
+
+func (d *diskBuffer) ReadBatch(ctx context.Context) (service.MessageBatch, service.AckFunc, error) {
+	ctx, done := context.WithCancel(ctx)
+	defer done()
+
+	go func() {
+		<-ctx.Done()
+		d.cond.L.Lock()
+		d.cond.Broadcast()
+		d.cond.L.Unlock()
+	}()
+
+	d.cond.L.Lock()
+	defer d.cond.L.Unlock()
+
+	var next diskRetry
+	for {
+		if d.closed {
+			return nil, nil, service.ErrEndOfBuffer
+		}
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+
+		if len(d.retries) > 0 {
+			next = d.retries[0]
+			d.retries[0] = diskRetry{}
+			d.retries = d.retries[1:]
+			break
+		}
+
+		if d.readOffset < d.readSeg.size {
+			payload, n, err := readDiskRecord(d.readFile, d.readOffset, d.readSeg.size)
+			if err != nil {
+				return nil, nil, err
+			}
+			next = diskRetry{
+				seg:     d.readSeg,
+				from:    d.readOffset,
+				to:      d.readOffset + n,
+				payload: payload,
+			}
+			d.readOffset += n
+			break
+		}
+
+		if d.readSeg.sealed {
+			var nextSeg *diskSegment
+			for i, seg := range d.segments {
+				if seg == d.readSeg && i+1 < len(d.segments) {
+					nextSeg = d.segments[i+1]
+					break
+				}
+			}
+			if nextSeg != nil {
+				if err := d.openReader(nextSeg); err != nil {
+					return nil, nil, err
+				}
+				d.compact()
+				continue
+			}
+		}
+
+		if d.endOfInput && d.pending == 0 {
+			return nil, nil, service.ErrEndOfBuffer
+		}
+		d.cond.Wait()
+	}
+
+	batch, err := decodeDiskBatch(next.payload)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode buffered batch: %w", err)
+	}
+	d.pending++
+
+	return batch, func(ctx context.Context, err error) error {
+		d.cond.L.Lock()
+		defer d.cond.L.Unlock()
+
+		d.pending--
+		if err != nil {
+			d.retries = append(d.retries, next)
+		} else {
+			next.seg.ack(next.from, next.to)
+			if d.syncPolicy == diskSyncAlways {
+				d.flushAcks()
+			}
+			d.compact()
+		}
+		d.cond.Broadcast()
+		return nil
+	}, nil
+}
+
+func (d *diskBuffer) WriteBatch(ctx context.Context, msgBatch service.MessageBatch, aFn service.AckFunc) error {
+	payload, err := encodeDiskBatch(msgBatch)
+	if err != nil {
+		return err
+	}
+	record := make([]byte, diskRecordHeaderLen+len(payload))
+	binary.BigEndian.PutUint32(record[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(record[4:8], crc32.ChecksumIEEE(payload))
+	copy(record[diskRecordHeaderLen:], payload)
+
+	if int64(len(record)) > d.segmentSize {
+		return component.ErrMessageTooLarge
+	}
+
+	d.cond.L.Lock()
+	if d.closed {
+		d.cond.L.Unlock()
+		return component.ErrTypeClosed
+	}
+
+	if d.writeFile == nil || (d.writeSeg.size > 0 && d.writeSeg.size+int64(len(record)) > d.segmentSize) {
+		if err := d.rotate(); err != nil {
+			d.cond.L.Unlock()
+			return err
+		}
+	}
+
+	if _, err := d.writeFile.Write(record); err != nil {
+		d.discardTail()
+		d.cond.L.Unlock()
+		return err
+	}
+	if d.syncPolicy == diskSyncAlways {
+		if err := d.writeFile.Sync(); err != nil {
+			d.discardTail()
+			d.cond.L.Unlock()
+			return err
+		}
+	} else {
+		d.writeDirty = true
+	}
+	d.writeSeg.size += int64(len(record))
+
+	d.cond.Broadcast()
+	d.cond.L.Unlock()
+
+	return aFn(ctx, nil)
+}
+
+// discardTail removes a record that failed to be written or synced from the
+// write segment, as otherwise the segment size and the file would disagree and
+// subsequent records would be written after the unaccounted bytes. When the
+// file can't be truncated the segment is sealed at its last known good size
+// instead, and the next write starts a new segment. Must be called whilst
+// holding the lock.
+func (d *diskBuffer) discardTail() {
+	err := d.writeFile.Truncate(d.writeSeg.size)
+	if err == nil {
+		return
+	}
+	d.log.Errorf("Failed to truncate buffer segment %v, sealing it: %v", d.writeSeg.id, err)
+
+	_ = d.writeFile.Close()
+	d.writeFile = nil
+	d.writeDirty = false
+	d.writeSeg.sealed = true
+	d.flushAcks()
+}
+
+func (d *diskBuffer) EndOfInput() {
+	d.cond.L.Lock()
+	d.endOfInput = true
+	d.cond.Broadcast()
+	d.cond.L.Unlock()
+}
+
+func (d *diskBuffer) Close(ctx context.Context) error {
+	d.cond.L.Lock()
+	defer d.cond.L.Unlock()
+
+	if d.closed {
+		return nil
+	}
+	d.closed = true
+	close(d.closeChan)
+	d.cond.Broadcast()
+
+	d.flushAcks()
+
+	var err error
+	if d.writeFile != nil {
+		if d.syncPolicy != diskSyncNever {
+			err = d.writeFile.Sync()
+		}
+		if cerr := d.writeFile.Close(); err == nil {
+			err = cerr
+		}
+		d.writeFile = nil
+	}
+	if d.readFile != nil {
+		_ = d.readFile.Close()
+		d.readFile = nil
+	}
+	return err
+}
+
+//------------------------------------------------------------------------------
+
+var (
+	errDiskRecordCorrupt  = errors.New("record checksum mismatch")
+	errDiskRecordTooLarge = errors.New("record length exceeds the remaining segment size")
+)
+
+// readDiskRecord reads the record at a given offset of a segment file of a
+// given size, returning the payload and the total number of bytes consumed.
+//
+// A header of zeroes, which is left behind when a file system extends a file
+// without persisting its contents, is treated as the end of the segment. This
+// is safe as encoded batches are never empty.
+func readDiskRecord(f io.ReaderAt, offset, size int64) ([]byte, int64, error) {
+	var header [diskRecordHeaderLen]byte
+	if _, err := f.ReadAt(header[:], offset); err != nil {
+		if errors.Is(err, io.EOF) {
+			if _, err := f.ReadAt(header[:1], offset); err == nil {
+				return nil, 0, io.ErrUnexpectedEOF
+			}
+			return nil, 0, io.EOF
+		}
+		return nil, 0, err
+	}
+
+	length := binary.BigEndian.Uint32(header[0:4])
+	if length == 0 && binary.BigEndian.Uint32(header[4:8]) == 0 {
+		return nil, 0, io.EOF
+	}
+
+	// Check the length against what remains of the segment before allocating,
+	// as a corrupted length could otherwise claim up to 4GB of memory.
+	if int64(length) > size-offset-diskRecordHeaderLen {
+		return nil, 0, errDiskRecordTooLarge
+	}
+
+	payload := make([]byte, length)
+	if _, err := f.ReadAt(payload, offset+diskRecordHeaderLen); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, 0, err
+	}
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:8]) {
+		return nil, 0, errDiskRecordCorrupt
+	}
+	return payload, diskRecordHeaderLen + int64(len(payload)), nil
+}
+
+func appendDiskBytes(buf *bytes.Buffer, b []byte) {
+	var lenBytes [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(lenBytes[:], uint64(len(b)))
+	buf.Write(lenBytes[:n])
+	buf.Write(b)
+}
+
+func encodeDiskBatch(batch service.MessageBatch) ([]byte, error) {
+	var buf bytes.Buffer
+
+	var lenBytes [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(lenBytes[:], uint64(len(batch)))
+	buf.Write(lenBytes[:n])
+
+	for _, msg := range batch {
+		var meta [][2]string
+		_ = msg.MetaWalk(func(k, v string) error {
+			meta = append(meta, [2]string{k, v})
+			return nil
+		})
+
+		n := binary.PutUvarint(lenBytes[:], uint64(len(meta)))
+		buf.Write(lenBytes[:n])
+		for _, kv := range meta {
+			appendDiskBytes(&buf, []byte(kv[0]))
+			appendDiskBytes(&buf, []byte(kv[1]))
+		}
+
+		mBytes, err := msg.AsBytes()
+		if err != nil {
+			return nil, err
+		}
+		appendDiskBytes(&buf, mBytes)
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeDiskBatch(payload []byte) (service.MessageBatch, error) {
+	r := bytes.NewReader(payload)
+
+	readBytes := func() ([]byte, error) {
+		l, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		if l > uint64(r.Len()) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		b := make([]byte, l)
+		_, err = io.ReadFull(r, b)
+		return b, err
+	}
+
+	count, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := make(service.MessageBatch, 0, count)
+	for i := uint64(0); i < count; i++ {
+		metaCount, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		meta := make([][2]string, 0, metaCount)
+		for j := uint64(0); j < metaCount; j++ {
+			k, err := readBytes()
+			if err != nil {
+				return nil, err
+			}
+			v, err := readBytes()
+			if err != nil {
+				return nil, err
+			}
+			meta = append(meta, [2]string{string(k), string(v)})
+		}
+		content, err := readBytes()
+		if err != nil {
+			return nil, err
+		}
+		msg := service.NewMessage(content)
+		for _, kv := range meta {
+			msg.MetaSet(kv[0], kv[1])
+		}
+		batch = append(batch, msg)
+	}
+	return batch, nil
+}
@@ -0,0 +1,359 @@
+package generic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func diskBufFromConf(t *testing.T, conf string) *diskBuffer {
+	t.Helper()
+
+	parsedConf, err := diskBufferConfig().ParseYAML(conf, nil)
+	require.NoError(t, err)
+
+	buf, err := newDiskBufferFromConfig(parsedConf, nil)
+	require.NoError(t, err)
+
+	return buf
+}
+
+func diskSegmentFiles(t *testing.T, dir string) []string {
+	t.Helper()
+
+	matches, err := filepath.Glob(filepath.Join(dir, "*"+diskSegmentExt))
+	require.NoError(t, err)
+	return matches
+}
+
+func TestDiskBasic(t *testing.T) {
+	n := 100
+	dir := t.TempDir()
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	block := diskBufFromConf(t, fmt.Sprintf(`
+directory: %v
+sync: always
+`, dir))
+	defer block.Close(ctx)
+
+	for i := 0; i < n; i++ {
+		msg := service.NewMessage([]byte(fmt.Sprintf("test%v", i)))
+		msg.MetaSet("foo", fmt.Sprintf("bar%v", i))
+		require.NoError(t, block.WriteBatch(ctx, service.MessageBatch{
+			service.NewMessage([]byte("hello")),
+			service.NewMessage([]byte("world")),
+			msg,
+		}, func(ctx context.Context, err error) error { return nil }))
+	}
+
+	for i := 0; i < n; i++ {
+		m, ackFunc, err := block.ReadBatch(ctx)
+		require.NoError(t, err)
+		require.Len(t, m, 3)
+		msgEqual(t, "hello", m[0])
+		msgEqual(t, fmt.Sprintf("test%v", i), m[2])
+
+		v, exists := m[2].MetaGet("foo")
+		assert.True(t, exists)
+		assert.Equal(t, fmt.Sprintf("bar%v", i), v)
+
+		require.NoError(t, ackFunc(ctx, nil))
+	}
+
+	block.EndOfInput()
+	_, _, err := block.ReadBatch(ctx)
+	assert.Equal(t, service.ErrEndOfBuffer, err)
+}
+
+func TestDiskNackRedelivers(t *testing.T) {
+	dir := t.TempDir()
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	block := diskBufFromConf(t, fmt.Sprintf(`
+directory: %v
+`, dir))
+	defer block.Close(ctx)
+
+	for _, s := range []string{"first", "second"} {
+		require.NoError(t, block.WriteBatch(ctx, service.MessageBatch{
+			service.NewMessage([]byte(s)),
+		}, func(ctx context.Context, err error) error { return nil }))
+	}
+
+	m, ackFunc, err := block.ReadBatch(ctx)
+	require.NoError(t, err)
+	msgEqual(t, "first", m[0])
+	require.NoError(t, ackFunc(ctx, errors.New("nope")))
+
+	m, ackFunc, err = block.ReadBatch(ctx)
+	require.NoError(t, err)
+	msgEqual(t, "first", m[0])
+	require.NoError(t, ackFunc(ctx, nil))
+
+	m, ackFunc, err = block.ReadBatch(ctx)
+	require.NoError(t, err)
+	msgEqual(t, "second", m[0])
+	require.NoError(t, ackFunc(ctx, nil))
+}
+
+func TestDiskReplayUnacked(t *testing.T) {
+	dir := t.TempDir()
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	block := diskBufFromConf(t, fmt.Sprintf(`
+directory: %v
+segment_size: 100
+`, dir))
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, block.WriteBatch(ctx, service.MessageBatch{
+			service.NewMessage([]byte(fmt.Sprintf("test%v", i))),
+		}, func(ctx context.Context, err error) error { return nil }))
+	}
+
+	for i := 0; i < 5; i++ {
+		m, ackFunc, err := block.ReadBatch(ctx)
+		require.NoError(t, err)
+		msgEqual(t, fmt.Sprintf("test%v", i), m[0])
+		require.NoError(t, ackFunc(ctx, nil))
+	}
+
+	// Read a batch but never acknowledge it.
+	m, _, err := block.ReadBatch(ctx)
+	require.NoError(t, err)
+	msgEqual(t, "test5", m[0])
+
+	require.NoError(t, block.Close(ctx))
+
+	block = diskBufFromConf(t, fmt.Sprintf(`
+directory: %v
+segment_size: 100
+`, dir))
+	defer block.Close(ctx)
+
+	for i := 5; i < 10; i++ {
+		m, ackFunc, err := block.ReadBatch(ctx)
+		require.NoError(t, err)
+		msgEqual(t, fmt.Sprintf("test%v", i), m[0])
+		require.NoError(t, ackFunc(ctx, nil))
+	}
+
+	block.EndOfInput()
+	_, _, err = block.ReadBatch(ctx)
+	assert.Equal(t, service.ErrEndOfBuffer, err)
+}
+
+func TestDiskCompaction(t *testing.T) {
+	dir := t.TempDir()
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	block := diskBufFromConf(t, fmt.Sprintf(`
+directory: %v
+segment_size: 40
+`, dir))
+	defer block.Close(ctx)
+
+	// Each record is 16 bytes and therefore each segment holds two.
+	var acks []service.AckFunc
+	for i := 0; i < 5; i++ {
+		require.NoError(t, block.WriteBatch(ctx, service.MessageBatch{
+			service.NewMessage([]byte(fmt.Sprintf("test%v", i))),
+		}, func(ctx context.Context, err error) error { return nil }))
+	}
+	assert.Len(t, diskSegmentFiles(t, dir), 3)
+
+	for i := 0; i < 5; i++ {
+		m, ackFunc, err := block.ReadBatch(ctx)
+		require.NoError(t, err)
+		msgEqual(t, fmt.Sprintf("test%v", i), m[0])
+		acks = append(acks, ackFunc)
+	}
+	assert.Len(t, diskSegmentFiles(t, dir), 3)
+
+	// Acknowledge out of order, segments are only removed once all of their
+	// records have been acknowledged.
+	require.NoError(t, acks[3](ctx, nil))
+	require.NoError(t, acks[0](ctx, nil))
+	assert.Len(t, diskSegmentFiles(t, dir), 3)
+
+	require.NoError(t, acks[1](ctx, nil))
+	assert.Len(t, diskSegmentFiles(t, dir), 2)
+
+	require.NoError(t, acks[2](ctx, nil))
+	assert.Len(t, diskSegmentFiles(t, dir), 1)
+
+	// The segment currently being written to is never removed.
+	require.NoError(t, acks[4](ctx, nil))
+	assert.Len(t, diskSegmentFiles(t, dir), 1)
+}
+
+func TestDiskTruncatesTornWrite(t *testing.T) {
+	dir := t.TempDir()
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	block := diskBufFromConf(t, fmt.Sprintf(`
+directory: %v
+`, dir))
+	for i := 0; i < 2; i++ {
+		require.NoError(t, block.WriteBatch(ctx, service.MessageBatch{
+			service.NewMessage([]byte(fmt.Sprintf("test%v", i))),
+		}, func(ctx context.Context, err error) error { return nil }))
+	}
+	require.NoError(t, block.Close(ctx))
+
+	segFiles := diskSegmentFiles(t, dir)
+	require.Len(t, segFiles, 1)
+
+	f, err := os.OpenFile(segFiles[0], os.O_WRONLY|os.O_APPEND, 0o644)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{0, 0, 0, 20, 1, 2})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	block = diskBufFromConf(t, fmt.Sprintf(`
+directory: %v
+`, dir))
+	defer block.Close(ctx)
+
+	for i := 0; i < 2; i++ {
+		m, ackFunc, err := block.ReadBatch(ctx)
+		require.NoError(t, err)
+		msgEqual(t, fmt.Sprintf("test%v", i), m[0])
+		require.NoError(t, ackFunc(ctx, nil))
+	}
+
+	block.EndOfInput()
+	_, _, err = block.ReadBatch(ctx)
+	assert.Equal(t, service.ErrEndOfBuffer, err)
+}
+
+func TestDiskSealsSegmentOnFailedWrite(t *testing.T) {
+	dir := t.TempDir()
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	block := diskBufFromConf(t, fmt.Sprintf(`
+directory: %v
+sync: always
+`, dir))
+	defer block.Close(ctx)
+
+	noopAck := func(ctx context.Context, err error) error { return nil }
+	require.NoError(t, block.WriteBatch(ctx, service.MessageBatch{
+		service.NewMessage([]byte("test0")),
+	}, noopAck))
+
+	// Closing the underlying file results in both the write and the truncation
+	// of the failed record failing, and therefore the segment is sealed.
+	block.cond.L.Lock()
+	require.NoError(t, block.writeFile.Close())
+	block.cond.L.Unlock()
+
+	require.Error(t, block.WriteBatch(ctx, service.MessageBatch{
+		service.NewMessage([]byte("nope")),
+	}, noopAck))
+	require.NoError(t, block.WriteBatch(ctx, service.MessageBatch{
+		service.NewMessage([]byte("test1")),
+	}, noopAck))
+	assert.Len(t, diskSegmentFiles(t, dir), 2)
+
+	for i := 0; i < 2; i++ {
+		m, ackFunc, err := block.ReadBatch(ctx)
+		require.NoError(t, err)
+		msgEqual(t, fmt.Sprintf("test%v", i), m[0])
+		require.NoError(t, ackFunc(ctx, nil))
+	}
+}
+
+func TestDiskRecoversCorruptedTail(t *testing.T) {
+	tests := []struct {
+		name string
+		tail []byte
+	}{
+		{
+			name: "zero filled",
+			tail: make([]byte, 4096),
+		},
+		{
+			name: "length exceeds segment",
+			tail: []byte{0xff, 0xff, 0xff, 0xff, 1, 2, 3, 4, 5, 6},
+		},
+		{
+			name: "checksum mismatch",
+			tail: []byte{0, 0, 0, 2, 1, 2, 3, 4, 5, 6},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+			defer done()
+
+			block := diskBufFromConf(t, fmt.Sprintf(`
+directory: %v
+`, dir))
+			for i := 0; i < 2; i++ {
+				require.NoError(t, block.WriteBatch(ctx, service.MessageBatch{
+					service.NewMessage([]byte(fmt.Sprintf("test%v", i))),
+				}, func(ctx context.Context, err error) error { return nil }))
+			}
+			require.NoError(t, block.Close(ctx))
+
+			segFiles := diskSegmentFiles(t, dir)
+			require.Len(t, segFiles, 1)
+
+			stat, err := os.Stat(segFiles[0])
+			require.NoError(t, err)
+
+			f, err := os.OpenFile(segFiles[0], os.O_WRONLY|os.O_APPEND, 0o644)
+			require.NoError(t, err)
+			_, err = f.Write(test.tail)
+			require.NoError(t, err)
+			require.NoError(t, f.Close())
+
+			block = diskBufFromConf(t, fmt.Sprintf(`
+directory: %v
+`, dir))
+			defer block.Close(ctx)
+
+			recovered, err := os.Stat(segFiles[0])
+			require.NoError(t, err)
+			assert.Equal(t, stat.Size(), recovered.Size())
+
+			for i := 0; i < 2; i++ {
+				m, ackFunc, err := block.ReadBatch(ctx)
+				require.NoError(t, err)
+				msgEqual(t, fmt.Sprintf("test%v", i), m[0])
+				require.NoError(t, ackFunc(ctx, nil))
+			}
+
+			block.EndOfInput()
+			_, _, err = block.ReadBatch(ctx)
+			assert.Equal(t, service.ErrEndOfBuffer, err)
+		})
+	}
+}
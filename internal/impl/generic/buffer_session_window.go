@@ -0,0 +1,290 @@
+package generic
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/benthosdev/benthos/v4/internal/batch"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func sessionWindowBufferConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Categories("Windowing").
+		Summary("Groups messages into session windows by a key, where a session is closed once no messages of that key have been received for a period of inactivity.").
+		Description(`
+A session window is a grouping of messages that share a common key (such as a user ID) and arrive without a gap in activity larger than the configured `+"[`gap`](#gap)"+`. Each key has its own session, and therefore sessions of different keys are opened and closed independently. When a session closes its messages are flushed as a single batch.
+
+Messages are allocated to sessions by the processing time (the time at which they're ingested), following the system clock. In order to prevent long running sessions from accumulating messages indefinitely a `+"[`max_duration`](#max_duration)"+` can be specified, after which a session is closed even when it remains active.
+
+When a session is flushed each message has the metadata fields `+"`window_key`"+`, containing the key of the session, `+"`window_start_timestamp`"+` and `+"`window_end_timestamp`"+`, containing the timestamps of the first and last message of the session as RFC3339 strings, added to it.
+
+## Delivery Guarantees
+
+This buffer honours the transaction model within Benthos in order to ensure that messages are not acknowledged until they are successfully delivered to outputs.
+
+During graceful termination any open sessions are nacked such that their messages are re-consumed the next time the service starts.
+`).
+		Field(service.NewInterpolatedStringField("key").
+			Description("An [interpolated string](/docs/configuration/interpolation#bloblang-queries) resolved for each message that determines which session it belongs to.").
+			Example(`${! json("user_id") }`).Example(`${! meta("kafka_key") }`)).
+		Field(service.NewStringField("gap").
+			Description("A duration string describing the period of inactivity after which a session is closed.").
+			Example("30s").Example("10m")).
+		Field(service.NewStringField("max_duration").
+			Description("An optional duration string describing the maximum length of time a session can remain open from its first message before it is closed, regardless of activity.").
+			Default("").
+			Example("1h")).
+		Example("Clickstream Sessions", `Given a stream of page view events of the form:
+
+`+"```json"+`
+{
+  "user_id": "2d9c4d4a",
+  "page": "/products/cats",
+  "viewed_at": "2021-08-07T09:49:35Z"
+}
+`+"```"+`
+
+We can use a session window buffer in order to emit a summary of each browsing session once a user has been inactive for thirty minutes:`,
+			`
+buffer:
+  session_window:
+    key: ${! json("user_id") }
+    gap: 30m
+    max_duration: 12h
+
+pipeline:
+  processors:
+    - bloblang: |
+        root = if batch_index() == 0 {
+          {
+            "user_id": this.user_id,
+            "started_at": meta("window_start_timestamp"),
+            "ended_at": meta("window_end_timestamp"),
+            "pages": json("page").from_all(),
+          }
+        } else { deleted() }
+`,
+		)
+}
+
+func init() {
+	err := service.RegisterBatchBuffer(
+		"session_window", sessionWindowBufferConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.BatchBuffer, error) {
+			key, err := conf.FieldInterpolatedString("key")
+			if err != nil {
+				return nil, err
+			}
+			gap, err := getDuration(conf, true, "gap")
+			if err != nil {
+				return nil, err
+			}
+			if gap <= 0 {
+				return nil, fmt.Errorf("invalid gap '%v' must be greater than zero", gap)
+			}
+			maxDuration, err := getDuration(conf, false, "max_duration")
+			if err != nil {
+				return nil, err
+			}
+			if maxDuration < 0 {
+				return nil, fmt.Errorf("invalid max_duration '%v' must not be negative", maxDuration)
+			}
+			return newSessionWindowBuffer(key, func() time.Time {
+				return time.Now().UTC()
+			}, gap, maxDuration, mgr.Logger()), nil
+		})
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type sessionWindow struct {
+	key           string
+	start, lastTS time.Time
+	pending       []*tsMessage
+
+	// closeBy is the time at which the session must be closed regardless of
+	// activity, and is zero when there is no maximum duration.
+	closeBy time.Time
+}
+
+func (s *sessionWindow) expiresAt(gap time.Duration) time.Time {
+	t := s.lastTS.Add(gap)
+	if !s.closeBy.IsZero() && s.closeBy.Before(t) {
+		t = s.closeBy
+	}
+	return t
+}
+
+type sessionWindowBuffer struct {
+	logger *service.Logger
+
+	key              *service.InterpolatedString
+	clock            utcNowProvider
+	gap, maxDuration time.Duration
+
+	sessions   map[string]*sessionWindow
+	pendingMut sync.Mutex
+
+	// Signals to a blocked reader that the set of sessions has changed.
+	notifyChan chan struct{}
+
+	endOfInputChan      chan struct{}
+	closeEndOfInputOnce sync.Once
+}
+
+func newSessionWindowBuffer(
+	key *service.InterpolatedString,
+	clock utcNowProvider,
+	gap, maxDuration time.Duration,
+	logger *service.Logger,
+) *sessionWindowBuffer {
+	return &sessionWindowBuffer{
+		logger:         logger,
+		key:            key,
+		clock:          clock,
+		gap:            gap,
+		maxDuration:    maxDuration,
+		sessions:       map[string]*sessionWindow{},
+		notifyChan:     make(chan struct{}, 1),
+		endOfInputChan: make(chan struct{}),
+	}
+}
+
+func (w *sessionWindowBuffer) WriteBatch(ctx context.Context, msgBatch service.MessageBatch, aFn service.AckFunc) error {
+	w.pendingMut.Lock()
+	defer w.pendingMut.Unlock()
+
+	now := w.clock()
+	aggregatedAck := batch.NewCombinedAcker(batch.AckFunc(aFn))
+
+	for i, msg := range msgBatch {
+		key := msgBatch.InterpolatedString(i, w.key)
+
+		session, exists := w.sessions[key]
+		if !exists {
+			session = &sessionWindow{key: key, start: now}
+			if w.maxDuration > 0 {
+				session.closeBy = now.Add(w.maxDuration)
+			}
+			w.sessions[key] = session
+		}
+		session.lastTS = now
+		session.pending = append(session.pending, &tsMessage{
+			ts: now, m: msg, ackFn: service.AckFunc(aggregatedAck.Derive()),
+		})
+	}
+
+	select {
+	case w.notifyChan <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// nextExpired removes and returns the session that expired the earliest, or
+// returns nil and the time at which the next session will expire.
+func (w *sessionWindowBuffer) nextExpired() (*sessionWindow, time.Time) {
+	w.pendingMut.Lock()
+	defer w.pendingMut.Unlock()
+
+	var earliest *sessionWindow
+	var earliestExpiry time.Time
+	for _, session := range w.sessions {
+		expiry := session.expiresAt(w.gap)
+		if earliest == nil || expiry.Before(earliestExpiry) {
+			earliest, earliestExpiry = session, expiry
+		}
+	}
+	if earliest == nil || earliestExpiry.After(w.clock()) {
+		return nil, earliestExpiry
+	}
+
+	delete(w.sessions, earliest.key)
+	return earliest, earliestExpiry
+}
+
+func (w *sessionWindowBuffer) flushSession(session *sessionWindow) (service.MessageBatch, service.AckFunc) {
+	flushBatch := make(service.MessageBatch, 0, len(session.pending))
+	flushAcks := make([]service.AckFunc, 0, len(session.pending))
+
+	startStr := session.start.Format(time.RFC3339Nano)
+	endStr := session.lastTS.Format(time.RFC3339Nano)
+	for _, pending := range session.pending {
+		tmpMsg := pending.m.Copy()
+		tmpMsg.MetaSet("window_key", session.key)
+		tmpMsg.MetaSet("window_start_timestamp", startStr)
+		tmpMsg.MetaSet("window_end_timestamp", endStr)
+		flushBatch = append(flushBatch, tmpMsg)
+		flushAcks = append(flushAcks, pending.ackFn)
+	}
+
+	return flushBatch, func(ctx context.Context, err error) error {
+		for _, aFn := range flushAcks {
+			_ = aFn(ctx, err)
+		}
+		return nil
+	}
+}
+
+func (w *sessionWindowBuffer) ReadBatch(ctx context.Context) (service.MessageBatch, service.AckFunc, error) {
+	for {
+		session, nextExpiry := w.nextExpired()
+		if session != nil {
+			msgBatch, aFn := w.flushSession(session)
+			return msgBatch, aFn, nil
+		}
+
+		var expiryChan <-chan time.Time
+		var timer *time.Timer
+		if !nextExpiry.IsZero() {
+			timer = time.NewTimer(nextExpiry.Sub(w.clock()))
+			expiryChan = timer.C
+		}
+		stopTimer := func() {
+			if timer != nil {
+				timer.Stop()
+			}
+		}
+
+		select {
+		case <-expiryChan:
+		case <-w.notifyChan:
+			stopTimer()
+		case <-ctx.Done():
+			stopTimer()
+			return nil, nil, ctx.Err()
+		case <-w.endOfInputChan:
+			stopTimer()
+
+			// Nack all open sessions so that we re-consume them on the next
+			// start up.
+			w.pendingMut.Lock()
+			for _, session := range w.sessions {
+				for _, pending := range session.pending {
+					_ = pending.ackFn(ctx, errWindowClosed)
+				}
+			}
+			w.sessions = map[string]*sessionWindow{}
+			w.pendingMut.Unlock()
+			return nil, nil, service.ErrEndOfBuffer
+		}
+	}
+}
+
+func (w *sessionWindowBuffer) EndOfInput() {
+	w.closeEndOfInputOnce.Do(func() {
+		close(w.endOfInputChan)
+	})
+}
+
+func (w *sessionWindowBuffer) Close(ctx context.Context) error {
+	return nil
+}
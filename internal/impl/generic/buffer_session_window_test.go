@@ -0,0 +1,214 @@
+package generic
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+type fakeClock struct {
+	mut sync.Mutex
+	now time.Time
+}
+
+func (f *fakeClock) Now() time.Time {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	return f.now
+}
+
+func (f *fakeClock) Advance(d time.Duration) {
+	f.mut.Lock()
+	f.now = f.now.Add(d)
+	f.mut.Unlock()
+}
+
+func TestSessionWindowBufferConfigs(t *testing.T) {
+	tests := []struct {
+		config           string
+		lintErrContains  string
+		buildErrContains string
+	}{
+		{
+			config: `
+session_window:
+  key: ${! json("id") }
+  gap: 10s
+  max_duration: 1h
+`,
+		},
+		{
+			config: `
+session_window:
+  key: ${! json("id") }
+`,
+			lintErrContains: "field gap is required",
+		},
+		{
+			config: `
+session_window:
+  key: ${! json("id") }
+  gap: nope
+`,
+			buildErrContains: "failed to parse field 'gap' as duration",
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			env := service.NewStreamBuilder()
+			require.NoError(t, env.SetLoggerYAML(`level: OFF`))
+			err := env.AddConsumerFunc(func(context.Context, *service.Message) error {
+				return nil
+			})
+			require.NoError(t, err)
+			_, err = env.AddProducerFunc()
+			require.NoError(t, err)
+
+			err = env.SetBufferYAML(test.config)
+			if test.lintErrContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.lintErrContains)
+				return
+			}
+			require.NoError(t, err)
+
+			strm, err := env.Build()
+			require.NoError(t, err)
+
+			cancelledCtx, done := context.WithCancel(context.Background())
+			done()
+			err = strm.Run(cancelledCtx)
+			if test.buildErrContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.buildErrContains)
+				return
+			}
+			require.EqualError(t, err, "context canceled")
+			require.NoError(t, strm.StopWithin(time.Second))
+		})
+	}
+}
+
+func TestSessionWindowGap(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0).UTC()}
+
+	key, err := service.NewInterpolatedString(`${! json("user") }`)
+	require.NoError(t, err)
+
+	w := newSessionWindowBuffer(key, clock.Now, time.Second*10, 0, nil)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	var ackErrs []error
+	var ackMut sync.Mutex
+	ackFn := func(ctx context.Context, err error) error {
+		ackMut.Lock()
+		ackErrs = append(ackErrs, err)
+		ackMut.Unlock()
+		return nil
+	}
+
+	require.NoError(t, w.WriteBatch(ctx, service.MessageBatch{
+		service.NewMessage([]byte(`{"user":"a","v":1}`)),
+		service.NewMessage([]byte(`{"user":"b","v":2}`)),
+	}, ackFn))
+
+	clock.Advance(time.Second * 5)
+	require.NoError(t, w.WriteBatch(ctx, service.MessageBatch{
+		service.NewMessage([]byte(`{"user":"a","v":3}`)),
+	}, ackFn))
+
+	// User b has now been inactive for the gap, user a has not.
+	clock.Advance(time.Second * 5)
+
+	msgs, aFn, err := w.ReadBatch(ctx)
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	msgEqual(t, `{"user":"b","v":2}`, msgs[0])
+
+	v, _ := msgs[0].MetaGet("window_key")
+	assert.Equal(t, "b", v)
+	v, _ = msgs[0].MetaGet("window_start_timestamp")
+	assert.Equal(t, "1970-01-01T00:16:40Z", v)
+	require.NoError(t, aFn(ctx, nil))
+
+	clock.Advance(time.Second * 5)
+
+	msgs, aFn, err = w.ReadBatch(ctx)
+	require.NoError(t, err)
+	require.Len(t, msgs, 2)
+	msgEqual(t, `{"user":"a","v":1}`, msgs[0])
+	msgEqual(t, `{"user":"a","v":3}`, msgs[1])
+
+	v, _ = msgs[1].MetaGet("window_start_timestamp")
+	assert.Equal(t, "1970-01-01T00:16:40Z", v)
+	v, _ = msgs[1].MetaGet("window_end_timestamp")
+	assert.Equal(t, "1970-01-01T00:16:45Z", v)
+	require.NoError(t, aFn(ctx, nil))
+
+	ackMut.Lock()
+	assert.Equal(t, []error{nil, nil}, ackErrs)
+	ackMut.Unlock()
+}
+
+func TestSessionWindowMaxDuration(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0).UTC()}
+
+	key, err := service.NewInterpolatedString(`${! json("user") }`)
+	require.NoError(t, err)
+
+	w := newSessionWindowBuffer(key, clock.Now, time.Second*10, time.Second*15, nil)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	noopAck := func(ctx context.Context, err error) error { return nil }
+	for i := 0; i < 4; i++ {
+		require.NoError(t, w.WriteBatch(ctx, service.MessageBatch{
+			service.NewMessage([]byte(`{"user":"a"}`)),
+		}, noopAck))
+		clock.Advance(time.Second * 5)
+	}
+
+	msgs, _, err := w.ReadBatch(ctx)
+	require.NoError(t, err)
+	assert.Len(t, msgs, 4)
+}
+
+func TestSessionWindowEndOfInput(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0).UTC()}
+
+	key, err := service.NewInterpolatedString(`${! json("user") }`)
+	require.NoError(t, err)
+
+	w := newSessionWindowBuffer(key, clock.Now, time.Second*10, 0, nil)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+
+	var ackErrs []string
+	require.NoError(t, w.WriteBatch(ctx, service.MessageBatch{
+		service.NewMessage([]byte(`{"user":"a"}`)),
+		service.NewMessage([]byte(`{"user":"b"}`)),
+	}, func(ctx context.Context, err error) error {
+		ackErrs = append(ackErrs, err.Error())
+		return nil
+	}))
+
+	w.EndOfInput()
+	_, _, err = w.ReadBatch(ctx)
+	require.Equal(t, service.ErrEndOfBuffer, err)
+
+	sort.Strings(ackErrs)
+	assert.Equal(t, []string{errWindowClosed.Error()}, ackErrs)
+}
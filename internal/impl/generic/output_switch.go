@@ -218,7 +218,7 @@ func newSwitchOutput(conf ooutput.SwitchConfig, mgr bundle.NewManagement) (outpu
 
 	var err error
 	for i, cConf := range conf.Cases {
-		oMgr := mgr.IntoPath("switch", strconv.Itoa(i), "output").(bundle.NewManagement)
+		oMgr := mgr.IntoPath("switch", "cases", strconv.Itoa(i), "output").(bundle.NewManagement)
 		if o.outputs[i], err = oMgr.NewOutput(cConf.Output); err != nil {
 			return nil, err
 		}
@@ -8,7 +8,7 @@ import (
 
 func init() {
 	_ = bundle.AllTracers.Add(func(c tracer.Config) (tracer.Type, error) {
-		return noopTracer{}, nil
+		return tracer.Noop{}, nil
 	}, docs.ComponentSpec{
 		Name:    "none",
 		Type:    docs.TypeTracer,
@@ -17,11 +17,3 @@ func init() {
 		Config:  docs.FieldComponent().HasType(docs.FieldTypeObject),
 	})
 }
-
-//------------------------------------------------------------------------------
-
-type noopTracer struct{}
-
-func (n noopTracer) Close() error {
-	return nil
-}
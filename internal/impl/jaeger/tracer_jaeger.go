@@ -7,13 +7,12 @@ import (
 	"strings"
 	"time"
 
-	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/jaeger"
-	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	tracesdk "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/benthosdev/benthos/v4/internal/bundle"
 	"github.com/benthosdev/benthos/v4/internal/component/tracer"
@@ -118,22 +117,21 @@ func NewJaeger(config tracer.Config) (tracer.Type, error) {
 		tracesdk.WithSampler(sampler),
 	)
 
-	otel.SetTracerProvider(tp)
-
-	// TODO: I'm so confused, these APIs are a nightmare.
-	otel.SetTextMapPropagator(propagation.TraceContext{})
-
 	j.prov = tp
 	return j, nil
 }
 
 //------------------------------------------------------------------------------
 
+// TracerProvider returns the provider from which spans are created.
+func (j *Jaeger) TracerProvider() trace.TracerProvider {
+	return j.prov
+}
+
 // Close stops the tracer.
 func (j *Jaeger) Close() error {
 	if j.prov != nil {
 		_ = j.prov.Shutdown(context.Background())
-		j.prov = nil
 	}
 	return nil
 }
@@ -0,0 +1,70 @@
+package opentelemetry
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/benthosdev/benthos/v4/internal/docs"
+	"github.com/benthosdev/benthos/v4/internal/otlp"
+	btls "github.com/benthosdev/benthos/v4/internal/tls"
+)
+
+func collectorField(name, description, example string) docs.FieldSpec {
+	return docs.FieldObject(name, description).Array().WithChildren(
+		docs.FieldString("url", "The address of the collector, optionally followed by a URL path for HTTP collectors. The address can be prefixed with an `http://` or `https://` scheme, where `https` enables TLS with default settings when the `tls` field is not enabled.", example).HasDefault(""),
+		docs.FieldString("headers", "A map of headers to add to each export request.").Map().Advanced().HasDefault(map[string]interface{}{}),
+		btls.FieldSpec(),
+	).HasDefault([]interface{}{})
+}
+
+// collectorEndpoint is the parsed address of a collector.
+type collectorEndpoint struct {
+	host    string
+	urlPath string
+	tlsConf *tls.Config
+}
+
+// parseCollector parses the address of a collector into its host, an optional
+// URL path and a TLS config, which is nil when the collector is insecure.
+func parseCollector(c otlp.CollectorConfig) (e collectorEndpoint, err error) {
+	addr := c.URL
+	if !strings.Contains(addr, "://") {
+		// Without a scheme the address is parsed as a host followed by an
+		// optional path.
+		addr = "//" + addr
+	}
+
+	var u *url.URL
+	if u, err = url.Parse(addr); err != nil {
+		return e, fmt.Errorf("failed to parse url: %w", err)
+	}
+	if u.Host == "" {
+		return e, errors.New("url must contain a host")
+	}
+
+	var secure bool
+	switch u.Scheme {
+	case "", "http":
+	case "https":
+		secure = true
+	default:
+		return e, fmt.Errorf("url scheme '%v' is not supported, expected http or https", u.Scheme)
+	}
+
+	e.host = u.Host
+	e.urlPath = u.Path
+	if c.TLS.Enabled {
+		if e.tlsConf, err = c.TLS.Get(); err != nil {
+			return e, err
+		}
+		secure = true
+	}
+	if secure && e.tlsConf == nil {
+		// A nil config is returned when no TLS fields are customised.
+		e.tlsConf = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return e, nil
+}
@@ -0,0 +1,79 @@
+package opentelemetry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/otlp"
+	btls "github.com/benthosdev/benthos/v4/internal/tls"
+)
+
+func TestParseCollector(t *testing.T) {
+	tests := map[string]struct {
+		url         string
+		tls         bool
+		host        string
+		urlPath     string
+		secure      bool
+		errContains string
+	}{
+		"host only": {
+			url:  "localhost:4318",
+			host: "localhost:4318",
+		},
+		"host and path": {
+			url:     "localhost:4318/custom/metrics",
+			host:    "localhost:4318",
+			urlPath: "/custom/metrics",
+		},
+		"http scheme": {
+			url:  "http://localhost:4318",
+			host: "localhost:4318",
+		},
+		"http scheme and path": {
+			url:     "http://localhost:4318/custom/metrics",
+			host:    "localhost:4318",
+			urlPath: "/custom/metrics",
+		},
+		"https scheme": {
+			url:    "https://example.com",
+			host:   "example.com",
+			secure: true,
+		},
+		"tls enabled": {
+			url:    "localhost:4317",
+			tls:    true,
+			host:   "localhost:4317",
+			secure: true,
+		},
+		"unsupported scheme": {
+			url:         "ftp://localhost:4318",
+			errContains: "url scheme 'ftp' is not supported",
+		},
+		"missing host": {
+			url:         "http:///foo",
+			errContains: "url must contain a host",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			tlsConf := btls.NewConfig()
+			tlsConf.Enabled = test.tls
+
+			e, err := parseCollector(otlp.CollectorConfig{URL: test.url, TLS: tlsConf})
+			if test.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.host, e.host)
+			assert.Equal(t, test.urlPath, e.urlPath)
+			assert.Equal(t, test.secure, e.tlsConf != nil)
+		})
+	}
+}
@@ -0,0 +1,392 @@
+package opentelemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/unit"
+	"go.opentelemetry.io/otel/sdk/metric/aggregator/histogram"
+	controller "go.opentelemetry.io/otel/sdk/metric/controller/basic"
+	"go.opentelemetry.io/otel/sdk/metric/export"
+	"go.opentelemetry.io/otel/sdk/metric/export/aggregation"
+	processor "go.opentelemetry.io/otel/sdk/metric/processor/basic"
+	selector "go.opentelemetry.io/otel/sdk/metric/selector/simple"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	imetrics "github.com/benthosdev/benthos/v4/internal/component/metrics"
+	"github.com/benthosdev/benthos/v4/internal/docs"
+	"github.com/benthosdev/benthos/v4/internal/log"
+	"github.com/benthosdev/benthos/v4/internal/otlp"
+)
+
+func init() {
+	_ = bundle.AllMetrics.Add(newOtelMetrics, docs.ComponentSpec{
+		Name:    "open_telemetry",
+		Type:    docs.TypeMetrics,
+		Status:  docs.StatusExperimental,
+		Summary: `Push metrics to [Open Telemetry collectors](https://opentelemetry.io/docs/collector/) over OTLP.`,
+		Description: `
+Metrics can be pushed to any number of collectors over both gRPC and HTTP. Unless TLS is enabled for a collector metrics are sent without transport security.
+
+Counters are exported as sums, gauges as gauges and timing metrics as histograms, where timing values are converted from nanoseconds into seconds in order to better fit within bucket definitions. The names and labels of metrics can be modified with the ` + "`mapping`" + ` field.`,
+		Config: docs.FieldComponent().WithChildren(
+			collectorField("http", "A list of collectors to push metrics to over OTLP HTTP.", "localhost:4318"),
+			collectorField("grpc", "A list of collectors to push metrics to over OTLP gRPC.", "localhost:4317"),
+			docs.FieldString("push_interval", "The period of time between each push of metrics.").HasDefault("10s"),
+			docs.FieldFloat("histogram_buckets", "Timing metrics histogram buckets (in seconds). If left empty defaults to `[0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10]`.").Array().Advanced().HasDefault([]interface{}{}),
+			docs.FieldString("tags", "A map of tags to add to the resource of metrics as attributes. The attribute `service.name` defaults to `benthos` unless set here.").Map().Advanced().HasDefault(map[string]interface{}{}),
+		),
+	})
+}
+
+//------------------------------------------------------------------------------
+
+// otlpExporter is a metrics exporter that holds a connection to a collector.
+type otlpExporter interface {
+	export.Exporter
+	Shutdown(ctx context.Context) error
+}
+
+// multiExporter exports each collection of metrics to several exporters.
+type multiExporter struct {
+	aggregation.TemporalitySelector
+	exporters []otlpExporter
+}
+
+func (m *multiExporter) Export(ctx context.Context, res *resource.Resource, reader export.InstrumentationLibraryReader) error {
+	var errs []string
+	for _, e := range m.exporters {
+		if err := e.Export(ctx, res, reader); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// Shutdown closes the connections of all exporters, which is not done by the
+// controller when it is stopped.
+func (m *multiExporter) Shutdown(ctx context.Context) error {
+	var errs []string
+	for _, e := range m.exporters {
+		if err := e.Shutdown(ctx); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+func httpMetricsExporter(ctx context.Context, c otlp.CollectorConfig) (*otlpmetric.Exporter, error) {
+	e, err := parseCollector(c)
+	if err != nil {
+		return nil, err
+	}
+	opts := []otlpmetrichttp.Option{
+		otlpmetrichttp.WithEndpoint(e.host),
+		otlpmetrichttp.WithHeaders(c.Headers),
+	}
+	if e.urlPath != "" {
+		opts = append(opts, otlpmetrichttp.WithURLPath(e.urlPath))
+	}
+	if e.tlsConf != nil {
+		opts = append(opts, otlpmetrichttp.WithTLSClientConfig(e.tlsConf))
+	} else {
+		opts = append(opts, otlpmetrichttp.WithInsecure())
+	}
+	return otlpmetric.New(ctx, otlpmetrichttp.NewClient(opts...))
+}
+
+func grpcMetricsExporter(ctx context.Context, c otlp.CollectorConfig) (*otlpmetric.Exporter, error) {
+	e, err := parseCollector(c)
+	if err != nil {
+		return nil, err
+	}
+	if e.urlPath != "" {
+		return nil, errors.New("grpc collectors do not support a url path")
+	}
+	opts := []otlpmetricgrpc.Option{
+		otlpmetricgrpc.WithEndpoint(e.host),
+		otlpmetricgrpc.WithHeaders(c.Headers),
+	}
+	if e.tlsConf != nil {
+		opts = append(opts, otlpmetricgrpc.WithTLSCredentials(credentials.NewTLS(e.tlsConf)))
+	} else {
+		opts = append(opts, otlpmetricgrpc.WithInsecure())
+	}
+	return otlpmetric.New(ctx, otlpmetricgrpc.NewClient(opts...))
+}
+
+//------------------------------------------------------------------------------
+
+type otelMetrics struct {
+	ctx   context.Context
+	cont  *controller.Controller
+	exp   *multiExporter
+	meter metric.Meter
+	log   log.Modular
+
+	mut      sync.Mutex
+	counters map[string]metric.Int64Counter
+	timers   map[string]metric.Float64Histogram
+	gauges   map[string]*otelGaugeVec
+}
+
+func newOtelMetrics(config imetrics.Config, log log.Modular) (m imetrics.Type, err error) {
+	conf := config.OpenTelemetry
+	if len(conf.HTTP) == 0 && len(conf.GRPC) == 0 {
+		return nil, errors.New("at least one http or grpc collector must be specified")
+	}
+
+	var pushInterval time.Duration
+	if pushInterval, err = time.ParseDuration(conf.PushInterval); err != nil {
+		return nil, fmt.Errorf("failed to parse push interval: %v", err)
+	}
+
+	ctx := context.Background()
+	exp := &multiExporter{
+		TemporalitySelector: aggregation.CumulativeTemporalitySelector(),
+	}
+	defer func() {
+		if err != nil {
+			_ = exp.Shutdown(ctx)
+		}
+	}()
+
+	for i, c := range conf.HTTP {
+		if c.URL == "" {
+			return nil, fmt.Errorf("http collector %v: a url must be specified", i)
+		}
+		e, err := httpMetricsExporter(ctx, c)
+		if err != nil {
+			return nil, fmt.Errorf("http collector %v: %w", i, err)
+		}
+		exp.exporters = append(exp.exporters, e)
+	}
+	for i, c := range conf.GRPC {
+		if c.URL == "" {
+			return nil, fmt.Errorf("grpc collector %v: a url must be specified", i)
+		}
+		e, err := grpcMetricsExporter(ctx, c)
+		if err != nil {
+			return nil, fmt.Errorf("grpc collector %v: %w", i, err)
+		}
+		exp.exporters = append(exp.exporters, e)
+	}
+
+	var histOpts []histogram.Option
+	if len(conf.HistogramBuckets) > 0 {
+		histOpts = append(histOpts, histogram.WithExplicitBoundaries(conf.HistogramBuckets))
+	}
+
+	attrs := []attribute.KeyValue{semconv.ServiceNameKey.String("benthos")}
+	for k, v := range conf.Tags {
+		attrs = append(attrs, attribute.String(k, v))
+	}
+
+	cont := controller.New(
+		processor.NewFactory(selector.NewWithHistogramDistribution(histOpts...), exp),
+		controller.WithExporter(exp),
+		controller.WithCollectPeriod(pushInterval),
+		controller.WithResource(resource.NewWithAttributes(semconv.SchemaURL, attrs...)),
+	)
+	if err = cont.Start(ctx); err != nil {
+		return nil, err
+	}
+
+	return &otelMetrics{
+		ctx:      ctx,
+		cont:     cont,
+		exp:      exp,
+		meter:    cont.Meter("benthos"),
+		log:      log,
+		counters: map[string]metric.Int64Counter{},
+		timers:   map[string]metric.Float64Histogram{},
+		gauges:   map[string]*otelGaugeVec{},
+	}, nil
+}
+
+func labelAttributes(names, values []string) []attribute.KeyValue {
+	if len(names) != len(values) {
+		return nil
+	}
+	attrs := make([]attribute.KeyValue, len(names))
+	for i, n := range names {
+		attrs[i] = attribute.String(n, values[i])
+	}
+	return attrs
+}
+
+//------------------------------------------------------------------------------
+
+type otelCounter struct {
+	ctx   context.Context
+	c     metric.Int64Counter
+	attrs []attribute.KeyValue
+}
+
+func (o *otelCounter) Incr(count int64) {
+	o.c.Add(o.ctx, count, o.attrs...)
+}
+
+func (o *otelMetrics) GetCounter(path string) imetrics.StatCounter {
+	return o.GetCounterVec(path).With()
+}
+
+func (o *otelMetrics) GetCounterVec(path string, n ...string) imetrics.StatCounterVec {
+	o.mut.Lock()
+	c, exists := o.counters[path]
+	if !exists {
+		var err error
+		if c, err = o.meter.NewInt64Counter(path); err != nil {
+			o.mut.Unlock()
+			o.log.Errorf("Failed to register counter metric '%v': %v\n", path, err)
+			return imetrics.DudType{}.GetCounterVec(path, n...)
+		}
+		o.counters[path] = c
+	}
+	o.mut.Unlock()
+
+	return imetrics.FakeCounterVec(func(l ...string) imetrics.StatCounter {
+		return &otelCounter{ctx: o.ctx, c: c, attrs: labelAttributes(n, l)}
+	})
+}
+
+//------------------------------------------------------------------------------
+
+type otelTimer struct {
+	ctx   context.Context
+	h     metric.Float64Histogram
+	attrs []attribute.KeyValue
+}
+
+func (o *otelTimer) Timing(delta int64) {
+	o.h.Record(o.ctx, time.Duration(delta).Seconds(), o.attrs...)
+}
+
+func (o *otelMetrics) GetTimer(path string) imetrics.StatTimer {
+	return o.GetTimerVec(path).With()
+}
+
+func (o *otelMetrics) GetTimerVec(path string, n ...string) imetrics.StatTimerVec {
+	o.mut.Lock()
+	h, exists := o.timers[path]
+	if !exists {
+		var err error
+		if h, err = o.meter.NewFloat64Histogram(path, metric.WithUnit(unit.Unit("s"))); err != nil {
+			o.mut.Unlock()
+			o.log.Errorf("Failed to register timer metric '%v': %v\n", path, err)
+			return imetrics.DudType{}.GetTimerVec(path, n...)
+		}
+		o.timers[path] = h
+	}
+	o.mut.Unlock()
+
+	return imetrics.FakeTimerVec(func(l ...string) imetrics.StatTimer {
+		return &otelTimer{ctx: o.ctx, h: h, attrs: labelAttributes(n, l)}
+	})
+}
+
+//------------------------------------------------------------------------------
+
+// otelGauge holds the latest value of a gauge with a set of labels, which is
+// observed by the gauge instrument during each collection.
+type otelGauge struct {
+	value int64
+	attrs []attribute.KeyValue
+}
+
+func (o *otelGauge) Set(value int64) {
+	atomic.StoreInt64(&o.value, value)
+}
+
+func (o *otelGauge) Incr(count int64) {
+	atomic.AddInt64(&o.value, count)
+}
+
+func (o *otelGauge) Decr(count int64) {
+	atomic.AddInt64(&o.value, -count)
+}
+
+type otelGaugeVec struct {
+	mut    sync.Mutex
+	gauges map[string]*otelGauge
+}
+
+func (g *otelGaugeVec) with(names, values []string) *otelGauge {
+	key := strings.Join(values, "\x00")
+
+	g.mut.Lock()
+	defer g.mut.Unlock()
+	if gauge, exists := g.gauges[key]; exists {
+		return gauge
+	}
+	gauge := &otelGauge{attrs: labelAttributes(names, values)}
+	g.gauges[key] = gauge
+	return gauge
+}
+
+func (g *otelGaugeVec) observe(_ context.Context, res metric.Int64ObserverResult) {
+	g.mut.Lock()
+	defer g.mut.Unlock()
+	for _, gauge := range g.gauges {
+		res.Observe(atomic.LoadInt64(&gauge.value), gauge.attrs...)
+	}
+}
+
+func (o *otelMetrics) GetGauge(path string) imetrics.StatGauge {
+	return o.GetGaugeVec(path).With()
+}
+
+func (o *otelMetrics) GetGaugeVec(path string, n ...string) imetrics.StatGaugeVec {
+	o.mut.Lock()
+	g, exists := o.gauges[path]
+	if !exists {
+		g = &otelGaugeVec{gauges: map[string]*otelGauge{}}
+		if _, err := o.meter.NewInt64GaugeObserver(path, g.observe); err != nil {
+			o.mut.Unlock()
+			o.log.Errorf("Failed to register gauge metric '%v': %v\n", path, err)
+			return imetrics.DudType{}.GetGaugeVec(path, n...)
+		}
+		o.gauges[path] = g
+	}
+	o.mut.Unlock()
+
+	return imetrics.FakeGaugeVec(func(l ...string) imetrics.StatGauge {
+		return g.with(n, l)
+	})
+}
+
+//------------------------------------------------------------------------------
+
+func (o *otelMetrics) HandlerFunc() http.HandlerFunc {
+	return nil
+}
+
+// Close stops the metrics exporter, pushing any pending metrics.
+func (o *otelMetrics) Close() error {
+	ctx := context.Background()
+	err := o.cont.Stop(ctx)
+	if serr := o.exp.Shutdown(ctx); err == nil {
+		err = serr
+	}
+	return err
+}
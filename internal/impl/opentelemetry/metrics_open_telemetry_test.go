@@ -0,0 +1,133 @@
+package opentelemetry
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/component/metrics"
+	"github.com/benthosdev/benthos/v4/internal/log"
+	"github.com/benthosdev/benthos/v4/internal/otlp"
+)
+
+func TestOtelMetricsHTTP(t *testing.T) {
+	type request struct {
+		path   string
+		header string
+		body   string
+	}
+	reqChan := make(chan request, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		reqChan <- request{
+			path:   r.URL.Path,
+			header: r.Header.Get("X-Api-Key"),
+			body:   string(body),
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	conf := metrics.NewConfig()
+	conf.Type = "open_telemetry"
+	conf.OpenTelemetry.HTTP = []otlp.CollectorConfig{
+		{
+			URL:     strings.TrimPrefix(server.URL, "http://"),
+			Headers: map[string]string{"X-Api-Key": "foobar"},
+		},
+	}
+	conf.OpenTelemetry.PushInterval = "1h"
+
+	m, err := newOtelMetrics(conf, log.Noop())
+	require.NoError(t, err)
+
+	m.GetCounter("counter_foo").Incr(2)
+	m.GetCounterVec("counter_bar", "label_a").With("value_a").Incr(3)
+	m.GetTimer("timer_foo").Timing(int64(time.Millisecond * 20))
+	m.GetGaugeVec("gauge_foo", "label_b").With("value_b").Set(5)
+	m.GetGauge("gauge_foo_no_labels").Incr(1)
+
+	require.NoError(t, m.Close())
+
+	select {
+	case req := <-reqChan:
+		assert.Equal(t, "/v1/metrics", req.path)
+		assert.Equal(t, "foobar", req.header)
+		for _, s := range []string{
+			"counter_foo", "counter_bar", "label_a", "value_a", "timer_foo",
+			"gauge_foo", "label_b", "value_b", "gauge_foo_no_labels", "benthos",
+		} {
+			assert.Contains(t, req.body, s)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for export request")
+	}
+}
+
+type mockOTLPExporter struct {
+	multiExporter
+	shutdown bool
+}
+
+func (m *mockOTLPExporter) Shutdown(ctx context.Context) error {
+	m.shutdown = true
+	return nil
+}
+
+func TestOtelMetricsCloseShutsDownExporters(t *testing.T) {
+	conf := metrics.NewConfig()
+	conf.Type = "open_telemetry"
+	conf.OpenTelemetry.GRPC = []otlp.CollectorConfig{{URL: "localhost:4317"}}
+	conf.OpenTelemetry.PushInterval = "1h"
+
+	m, err := newOtelMetrics(conf, log.Noop())
+	require.NoError(t, err)
+
+	mockExp := &mockOTLPExporter{}
+	om := m.(*otelMetrics)
+	om.exp.exporters = append(om.exp.exporters, mockExp)
+
+	require.NoError(t, m.Close())
+	assert.True(t, mockExp.shutdown)
+}
+
+func TestOtelMetricsGaugeValues(t *testing.T) {
+	g := &otelGaugeVec{gauges: map[string]*otelGauge{}}
+
+	a := g.with([]string{"foo"}, []string{"a"})
+	a.Set(10)
+	a.Incr(5)
+	a.Decr(3)
+
+	assert.Equal(t, a, g.with([]string{"foo"}, []string{"a"}))
+	assert.Equal(t, int64(12), g.gauges["a"].value)
+
+	b := g.with([]string{"foo"}, []string{"b"})
+	b.Incr(1)
+	assert.Equal(t, int64(1), g.gauges["b"].value)
+	assert.Len(t, g.gauges, 2)
+}
+
+func TestOtelMetricsErrors(t *testing.T) {
+	conf := metrics.NewConfig()
+	conf.Type = "open_telemetry"
+
+	_, err := newOtelMetrics(conf, log.Noop())
+	require.EqualError(t, err, "at least one http or grpc collector must be specified")
+
+	conf.OpenTelemetry.HTTP = []otlp.CollectorConfig{{}}
+	_, err = newOtelMetrics(conf, log.Noop())
+	require.EqualError(t, err, "http collector 0: a url must be specified")
+
+	conf.OpenTelemetry.HTTP = nil
+	conf.OpenTelemetry.GRPC = []otlp.CollectorConfig{{URL: "localhost:4317/foo"}}
+	_, err = newOtelMetrics(conf, log.Noop())
+	require.EqualError(t, err, "grpc collector 0: grpc collectors do not support a url path")
+}
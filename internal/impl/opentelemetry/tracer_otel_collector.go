@@ -0,0 +1,171 @@
+package opentelemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/component/tracer"
+	"github.com/benthosdev/benthos/v4/internal/docs"
+	"github.com/benthosdev/benthos/v4/internal/otlp"
+)
+
+func init() {
+	_ = bundle.AllTracers.Add(NewOtelCollector, docs.ComponentSpec{
+		Name:    "open_telemetry_collector",
+		Type:    docs.TypeTracer,
+		Status:  docs.StatusExperimental,
+		Summary: `Send tracing events to an [Open Telemetry collector](https://opentelemetry.io/docs/collector/) over OTLP.`,
+		Description: `
+Tracing events can be sent to any number of collectors over both gRPC and HTTP. Unless TLS is enabled for a collector events are sent without transport security.`,
+		Config: docs.FieldComponent().WithChildren(
+			collectorField("http", "A list of collectors to send tracing events to over OTLP HTTP.", "localhost:4318"),
+			collectorField("grpc", "A list of collectors to send tracing events to over OTLP gRPC.", "localhost:4317"),
+			docs.FieldFloat("sampling_ratio", "The ratio of traces to sample, where 1 samples every trace and 0 samples none.").HasDefault(1.0),
+			docs.FieldString("tags", "A map of tags to add to the resource of tracing spans as attributes. The attribute `service.name` defaults to `benthos` unless set here.").Map().Advanced().HasDefault(map[string]interface{}{}),
+			docs.FieldString("flush_interval", "The period of time between each flush of tracing spans.").HasDefault(""),
+		),
+	})
+}
+
+//------------------------------------------------------------------------------
+
+// OtelCollector is a tracer with the capability to push spans to Open
+// Telemetry collectors.
+type OtelCollector struct {
+	prov *tracesdk.TracerProvider
+}
+
+func httpCollectorExporter(ctx context.Context, c otlp.CollectorConfig) (*otlptrace.Exporter, error) {
+	e, err := parseCollector(c)
+	if err != nil {
+		return nil, err
+	}
+	opts := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(e.host),
+		otlptracehttp.WithHeaders(c.Headers),
+	}
+	if e.urlPath != "" {
+		opts = append(opts, otlptracehttp.WithURLPath(e.urlPath))
+	}
+	if e.tlsConf != nil {
+		opts = append(opts, otlptracehttp.WithTLSClientConfig(e.tlsConf))
+	} else {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	return otlptrace.New(ctx, otlptracehttp.NewClient(opts...))
+}
+
+func grpcCollectorExporter(ctx context.Context, c otlp.CollectorConfig) (*otlptrace.Exporter, error) {
+	e, err := parseCollector(c)
+	if err != nil {
+		return nil, err
+	}
+	if e.urlPath != "" {
+		return nil, errors.New("grpc collectors do not support a url path")
+	}
+	opts := []otlptracegrpc.Option{
+		otlptracegrpc.WithEndpoint(e.host),
+		otlptracegrpc.WithHeaders(c.Headers),
+	}
+	if e.tlsConf != nil {
+		opts = append(opts, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(e.tlsConf)))
+	} else {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+	return otlptrace.New(ctx, otlptracegrpc.NewClient(opts...))
+}
+
+// NewOtelCollector creates and returns a new OtelCollector object.
+func NewOtelCollector(config tracer.Config) (t tracer.Type, err error) {
+	conf := config.OpenTelemetryCollector
+	if len(conf.HTTP) == 0 && len(conf.GRPC) == 0 {
+		return nil, errors.New("at least one http or grpc collector must be specified")
+	}
+	if conf.SamplingRatio < 0 || conf.SamplingRatio > 1 {
+		return nil, fmt.Errorf("sampling ratio must be between 0 and 1, got %v", conf.SamplingRatio)
+	}
+
+	var batchOpts []tracesdk.BatchSpanProcessorOption
+	if i := conf.FlushInterval; len(i) > 0 {
+		flushInterval, err := time.ParseDuration(i)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse flush interval '%s': %v", i, err)
+		}
+		batchOpts = append(batchOpts, tracesdk.WithBatchTimeout(flushInterval))
+	}
+
+	attrs := []attribute.KeyValue{semconv.ServiceNameKey.String("benthos")}
+	for k, v := range conf.Tags {
+		attrs = append(attrs, attribute.String(k, v))
+	}
+
+	provOpts := []tracesdk.TracerProviderOption{
+		tracesdk.WithResource(resource.NewWithAttributes(semconv.SchemaURL, attrs...)),
+		tracesdk.WithSampler(tracesdk.ParentBased(tracesdk.TraceIDRatioBased(conf.SamplingRatio))),
+	}
+
+	ctx := context.Background()
+	var exporters []*otlptrace.Exporter
+	defer func() {
+		if err != nil {
+			for _, exp := range exporters {
+				_ = exp.Shutdown(ctx)
+			}
+		}
+	}()
+
+	for i, c := range conf.HTTP {
+		if c.URL == "" {
+			return nil, fmt.Errorf("http collector %v: a url must be specified", i)
+		}
+		exp, err := httpCollectorExporter(ctx, c)
+		if err != nil {
+			return nil, fmt.Errorf("http collector %v: %w", i, err)
+		}
+		exporters = append(exporters, exp)
+		provOpts = append(provOpts, tracesdk.WithBatcher(exp, batchOpts...))
+	}
+	for i, c := range conf.GRPC {
+		if c.URL == "" {
+			return nil, fmt.Errorf("grpc collector %v: a url must be specified", i)
+		}
+		exp, err := grpcCollectorExporter(ctx, c)
+		if err != nil {
+			return nil, fmt.Errorf("grpc collector %v: %w", i, err)
+		}
+		exporters = append(exporters, exp)
+		provOpts = append(provOpts, tracesdk.WithBatcher(exp, batchOpts...))
+	}
+
+	tp := tracesdk.NewTracerProvider(provOpts...)
+
+	return &OtelCollector{prov: tp}, nil
+}
+
+//------------------------------------------------------------------------------
+
+// TracerProvider returns the provider from which spans are created.
+func (o *OtelCollector) TracerProvider() trace.TracerProvider {
+	return o.prov
+}
+
+// Close stops the tracer, flushing any pending tracing spans.
+func (o *OtelCollector) Close() error {
+	if o.prov != nil {
+		_ = o.prov.Shutdown(context.Background())
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package opentelemetry
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/component/tracer"
+	"github.com/benthosdev/benthos/v4/internal/otlp"
+)
+
+func TestOtelCollectorHTTP(t *testing.T) {
+	type request struct {
+		path   string
+		header string
+		body   string
+	}
+	reqChan := make(chan request, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		reqChan <- request{
+			path:   r.URL.Path,
+			header: r.Header.Get("X-Api-Key"),
+			body:   string(body),
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	conf := tracer.NewConfig()
+	conf.Type = "open_telemetry_collector"
+	conf.OpenTelemetryCollector.HTTP = []otlp.CollectorConfig{
+		{
+			URL:     server.URL + "/custom/traces",
+			Headers: map[string]string{"X-Api-Key": "foobar"},
+		},
+	}
+	conf.OpenTelemetryCollector.Tags = map[string]string{"deployment": "testing"}
+
+	tr, err := NewOtelCollector(conf)
+	require.NoError(t, err)
+
+	_, span := tr.TracerProvider().Tracer("test").Start(context.Background(), "meow")
+	span.End()
+
+	require.NoError(t, tr.Close())
+
+	select {
+	case req := <-reqChan:
+		assert.Equal(t, "/custom/traces", req.path)
+		assert.Equal(t, "foobar", req.header)
+		assert.Contains(t, req.body, "meow")
+		assert.Contains(t, req.body, "deployment")
+		assert.Contains(t, req.body, "benthos")
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for export request")
+	}
+}
+
+func TestOtelCollectorErrors(t *testing.T) {
+	conf := tracer.NewConfig()
+	conf.Type = "open_telemetry_collector"
+
+	_, err := NewOtelCollector(conf)
+	require.EqualError(t, err, "at least one http or grpc collector must be specified")
+
+	conf.OpenTelemetryCollector.GRPC = []otlp.CollectorConfig{{}}
+	_, err = NewOtelCollector(conf)
+	require.EqualError(t, err, "grpc collector 0: a url must be specified")
+
+	conf.OpenTelemetryCollector.GRPC = []otlp.CollectorConfig{{URL: "localhost:4317"}}
+	conf.OpenTelemetryCollector.SamplingRatio = 2
+	_, err = NewOtelCollector(conf)
+	require.EqualError(t, err, "sampling ratio must be between 0 and 1, got 2")
+}
@@ -0,0 +1,368 @@
+package postgresql
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// lsn is a position within the write-ahead log of a Postgres server.
+type lsn uint64
+
+// String formats the LSN in the same way as Postgres, e.g. 16/B374D848.
+func (l lsn) String() string {
+	return fmt.Sprintf("%X/%X", uint32(l>>32), uint32(l))
+}
+
+// Timestamps within the replication protocol are microseconds since the
+// Postgres epoch.
+var pgEpoch = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+func pgTimeFromMicros(micros int64) time.Time {
+	return pgEpoch.Add(time.Duration(micros) * time.Microsecond)
+}
+
+func pgMicrosFromTime(t time.Time) int64 {
+	return t.Sub(pgEpoch).Microseconds()
+}
+
+//------------------------------------------------------------------------------
+
+var errShortMessage = errors.New("message is too short")
+
+// pgReader consumes the fields of a replication protocol message, where an
+// error encountered by any read is retained and all subsequent reads return
+// zero values.
+type pgReader struct {
+	b   []byte
+	err error
+}
+
+func (r *pgReader) take(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if len(r.b) < n {
+		r.err = errShortMessage
+		return nil
+	}
+	v := r.b[:n]
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *pgReader) uint8() uint8 {
+	if b := r.take(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *pgReader) uint16() uint16 {
+	if b := r.take(2); b != nil {
+		return binary.BigEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *pgReader) uint32() uint32 {
+	if b := r.take(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *pgReader) uint64() uint64 {
+	if b := r.take(8); b != nil {
+		return binary.BigEndian.Uint64(b)
+	}
+	return 0
+}
+
+func (r *pgReader) string() string {
+	if r.err != nil {
+		return ""
+	}
+	for i, c := range r.b {
+		if c == 0 {
+			s := string(r.b[:i])
+			r.b = r.b[i+1:]
+			return s
+		}
+	}
+	r.err = errors.New("string is not null terminated")
+	return ""
+}
+
+//------------------------------------------------------------------------------
+
+// Messages sent by the server within the CopyBoth stream.
+const (
+	xLogDataByteID                = 'w'
+	primaryKeepaliveMessageByteID = 'k'
+	standbyStatusUpdateByteID     = 'r'
+)
+
+type xLogData struct {
+	walStart lsn
+	data     []byte
+}
+
+func parseXLogData(b []byte) (xLogData, error) {
+	r := pgReader{b: b}
+	x := xLogData{walStart: lsn(r.uint64())}
+	_ = r.uint64() // server WAL end
+	_ = r.uint64() // server time
+	if r.err != nil {
+		return x, fmt.Errorf("failed to parse XLogData: %w", r.err)
+	}
+	x.data = r.b
+	return x, nil
+}
+
+type primaryKeepalive struct {
+	walEnd         lsn
+	replyRequested bool
+}
+
+func parsePrimaryKeepalive(b []byte) (primaryKeepalive, error) {
+	r := pgReader{b: b}
+	k := primaryKeepalive{walEnd: lsn(r.uint64())}
+	_ = r.uint64() // server time
+	k.replyRequested = r.uint8() == 1
+	if r.err != nil {
+		return k, fmt.Errorf("failed to parse primary keepalive message: %w", r.err)
+	}
+	return k, nil
+}
+
+func encodeStandbyStatusUpdate(pos lsn, now time.Time) []byte {
+	b := make([]byte, 34)
+	b[0] = standbyStatusUpdateByteID
+	binary.BigEndian.PutUint64(b[1:], uint64(pos))  // written
+	binary.BigEndian.PutUint64(b[9:], uint64(pos))  // flushed
+	binary.BigEndian.PutUint64(b[17:], uint64(pos)) // applied
+	binary.BigEndian.PutUint64(b[25:], uint64(pgMicrosFromTime(now)))
+	return b
+}
+
+//------------------------------------------------------------------------------
+
+type pgColumn struct {
+	name     string
+	dataType uint32
+}
+
+type pgRelation struct {
+	id      uint32
+	schema  string
+	table   string
+	columns []pgColumn
+}
+
+type pgBegin struct {
+	finalLSN   lsn
+	commitTime time.Time
+	xid        uint32
+}
+
+type pgCommit struct {
+	commitLSN lsn
+	endLSN    lsn
+}
+
+// pgChange is an insert, update or delete of a row, where the before image is
+// only present for updates and deletes when the replica identity of the table
+// provides it.
+type pgChange struct {
+	operation string
+	relation  *pgRelation
+	before    map[string]interface{}
+	after     map[string]interface{}
+}
+
+// pgOutputDecoder decodes the messages of the pgoutput logical decoding plugin
+// (protocol version 1), keeping track of the relations that are referenced by
+// row changes.
+type pgOutputDecoder struct {
+	relations map[uint32]*pgRelation
+}
+
+func newPgOutputDecoder() *pgOutputDecoder {
+	return &pgOutputDecoder{
+		relations: map[uint32]*pgRelation{},
+	}
+}
+
+// decode a pgoutput message, returning either a *pgBegin, *pgCommit or
+// *pgChange, or nil for messages that do not need to be acted upon.
+func (d *pgOutputDecoder) decode(b []byte) (interface{}, error) {
+	if len(b) == 0 {
+		return nil, errShortMessage
+	}
+	r := &pgReader{b: b[1:]}
+
+	var res interface{}
+	var err error
+	switch b[0] {
+	case 'B':
+		res = &pgBegin{
+			finalLSN:   lsn(r.uint64()),
+			commitTime: pgTimeFromMicros(int64(r.uint64())),
+			xid:        r.uint32(),
+		}
+	case 'C':
+		_ = r.uint8() // flags
+		res = &pgCommit{
+			commitLSN: lsn(r.uint64()),
+			endLSN:    lsn(r.uint64()),
+		}
+	case 'R':
+		d.decodeRelation(r)
+	case 'I':
+		res, err = d.decodeChange("insert", r)
+	case 'U':
+		res, err = d.decodeChange("update", r)
+	case 'D':
+		res, err = d.decodeChange("delete", r)
+	case 'O', 'Y', 'T', 'M':
+		// Origin, type, truncate and generic messages are ignored.
+	default:
+		return nil, fmt.Errorf("unrecognised pgoutput message type '%c'", b[0])
+	}
+	if err == nil {
+		err = r.err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode pgoutput message '%c': %w", b[0], err)
+	}
+	return res, nil
+}
+
+func (d *pgOutputDecoder) decodeRelation(r *pgReader) {
+	rel := &pgRelation{
+		id:     r.uint32(),
+		schema: r.string(),
+		table:  r.string(),
+	}
+	_ = r.uint8() // replica identity
+	nCols := int(r.uint16())
+	for i := 0; i < nCols && r.err == nil; i++ {
+		_ = r.uint8() // flags
+		col := pgColumn{name: r.string()}
+		col.dataType = r.uint32()
+		_ = r.uint32() // type modifier
+		rel.columns = append(rel.columns, col)
+	}
+	if r.err == nil {
+		d.relations[rel.id] = rel
+	}
+}
+
+func (d *pgOutputDecoder) decodeChange(operation string, r *pgReader) (*pgChange, error) {
+	relID := r.uint32()
+	rel, exists := d.relations[relID]
+	if !exists {
+		if r.err != nil {
+			return nil, r.err
+		}
+		return nil, fmt.Errorf("relation %v was not recognised", relID)
+	}
+
+	c := &pgChange{operation: operation, relation: rel}
+	for r.err == nil && len(r.b) > 0 {
+		var err error
+		switch kind := r.uint8(); kind {
+		case 'K', 'O':
+			c.before, err = decodeTuple(rel, r)
+		case 'N':
+			c.after, err = decodeTuple(rel, r)
+		default:
+			err = fmt.Errorf("unrecognised tuple type '%c'", kind)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// decodeTuple reads the columns of a row, where columns that are TOASTed and
+// have not changed are omitted as their values are not sent.
+func decodeTuple(rel *pgRelation, r *pgReader) (map[string]interface{}, error) {
+	nCols := int(r.uint16())
+	if r.err == nil && nCols != len(rel.columns) {
+		return nil, fmt.Errorf("tuple has %v columns but relation %v.%v has %v", nCols, rel.schema, rel.table, len(rel.columns))
+	}
+
+	row := make(map[string]interface{}, nCols)
+	for i := 0; i < nCols && r.err == nil; i++ {
+		col := rel.columns[i]
+		switch kind := r.uint8(); kind {
+		case 'n':
+			row[col.name] = nil
+		case 'u':
+		case 't':
+			v, err := decodeTextValue(col.dataType, string(r.take(int(r.uint32()))))
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode column %v: %w", col.name, err)
+			}
+			row[col.name] = v
+		default:
+			if r.err == nil {
+				return nil, fmt.Errorf("unrecognised column type '%c'", kind)
+			}
+		}
+	}
+	return row, r.err
+}
+
+// Object IDs of the built-in types that are converted into structured values,
+// values of all other types are kept in their text representation.
+const (
+	boolOID    = 16
+	int8OID    = 20
+	int2OID    = 21
+	int4OID    = 23
+	oidOID     = 26
+	jsonOID    = 114
+	float4OID  = 700
+	float8OID  = 701
+	numericOID = 1700
+	jsonbOID   = 3802
+)
+
+func decodeTextValue(dataType uint32, s string) (interface{}, error) {
+	switch dataType {
+	case boolOID:
+		return s == "t", nil
+	case int2OID, int4OID, int8OID, oidOID:
+		return strconv.ParseInt(s, 10, 64)
+	case float4OID, float8OID, numericOID:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, err
+		}
+		// NaN and infinity are valid values but cannot be represented as JSON
+		// numbers.
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return s, nil
+		}
+		if dataType == numericOID {
+			// Retain the precision of numerics.
+			return json.Number(s), nil
+		}
+		return f, nil
+	case jsonOID, jsonbOID:
+		var v interface{}
+		if err := json.Unmarshal([]byte(s), &v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+	return s, nil
+}
@@ -0,0 +1,196 @@
+package postgresql
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type pgWriter struct {
+	b []byte
+}
+
+func (w *pgWriter) uint8(v uint8) *pgWriter {
+	w.b = append(w.b, v)
+	return w
+}
+
+func (w *pgWriter) uint16(v uint16) *pgWriter {
+	b := make([]byte, 2)
+	binary.BigEndian.PutUint16(b, v)
+	w.b = append(w.b, b...)
+	return w
+}
+
+func (w *pgWriter) uint32(v uint32) *pgWriter {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, v)
+	w.b = append(w.b, b...)
+	return w
+}
+
+func (w *pgWriter) uint64(v uint64) *pgWriter {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	w.b = append(w.b, b...)
+	return w
+}
+
+func (w *pgWriter) string(v string) *pgWriter {
+	w.b = append(append(w.b, v...), 0)
+	return w
+}
+
+func (w *pgWriter) text(v string) *pgWriter {
+	w.uint8('t').uint32(uint32(len(v)))
+	w.b = append(w.b, v...)
+	return w
+}
+
+func testRelationMsg() []byte {
+	w := &pgWriter{}
+	w.uint8('R').uint32(16385).string("public").string("foo").uint8('d').uint16(4)
+	for _, col := range []struct {
+		name string
+		oid  uint32
+	}{
+		{"id", int4OID},
+		{"name", 25},
+		{"price", numericOID},
+		{"doc", jsonbOID},
+	} {
+		w.uint8(0).string(col.name).uint32(col.oid).uint32(0xFFFFFFFF)
+	}
+	return w.b
+}
+
+func TestPgOutputDecode(t *testing.T) {
+	d := newPgOutputDecoder()
+
+	res, err := d.decode(testRelationMsg())
+	require.NoError(t, err)
+	assert.Nil(t, res)
+
+	commitTime := time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)
+	res, err = d.decode((&pgWriter{}).
+		uint8('B').uint64(0x16B374D848).uint64(uint64(pgMicrosFromTime(commitTime))).uint32(720).b)
+	require.NoError(t, err)
+	assert.Equal(t, &pgBegin{finalLSN: 0x16B374D848, commitTime: commitTime, xid: 720}, res)
+	assert.Equal(t, "16/B374D848", res.(*pgBegin).finalLSN.String())
+
+	res, err = d.decode((&pgWriter{}).
+		uint8('I').uint32(16385).uint8('N').uint16(4).
+		text("1").text("meow").text("10.50").text(`{"a":[1,2]}`).b)
+	require.NoError(t, err)
+
+	change := res.(*pgChange)
+	assert.Equal(t, "insert", change.operation)
+	assert.Equal(t, "foo", change.relation.table)
+	assert.Nil(t, change.before)
+	assert.Equal(t, map[string]interface{}{
+		"id":    int64(1),
+		"name":  "meow",
+		"price": json.Number("10.50"),
+		"doc":   map[string]interface{}{"a": []interface{}{1.0, 2.0}},
+	}, change.after)
+
+	res, err = d.decode((&pgWriter{}).
+		uint8('U').uint32(16385).
+		uint8('K').uint16(4).text("1").uint8('n').uint8('n').uint8('n').
+		uint8('N').uint16(4).text("2").text("woof").text("NaN").uint8('u').b)
+	require.NoError(t, err)
+
+	change = res.(*pgChange)
+	assert.Equal(t, "update", change.operation)
+	assert.Equal(t, map[string]interface{}{
+		"id": int64(1), "name": nil, "price": nil, "doc": nil,
+	}, change.before)
+	assert.Equal(t, map[string]interface{}{
+		"id": int64(2), "name": "woof", "price": "NaN",
+	}, change.after)
+
+	res, err = d.decode((&pgWriter{}).
+		uint8('D').uint32(16385).
+		uint8('O').uint16(4).text("2").text("woof").uint8('n').uint8('n').b)
+	require.NoError(t, err)
+
+	change = res.(*pgChange)
+	assert.Equal(t, "delete", change.operation)
+	assert.Equal(t, map[string]interface{}{
+		"id": int64(2), "name": "woof", "price": nil, "doc": nil,
+	}, change.before)
+	assert.Nil(t, change.after)
+
+	res, err = d.decode((&pgWriter{}).
+		uint8('C').uint8(0).uint64(0x16B374D848).uint64(0x16B374D878).uint64(0).b)
+	require.NoError(t, err)
+	assert.Equal(t, &pgCommit{commitLSN: 0x16B374D848, endLSN: 0x16B374D878}, res)
+}
+
+func TestPgOutputDecodeErrors(t *testing.T) {
+	d := newPgOutputDecoder()
+
+	_, err := d.decode((&pgWriter{}).uint8('I').uint32(16385).uint8('N').uint16(0).b)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "relation 16385 was not recognised")
+
+	_, err = d.decode(testRelationMsg())
+	require.NoError(t, err)
+
+	_, err = d.decode((&pgWriter{}).uint8('I').uint32(16385).uint8('N').uint16(1).text("1").b)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tuple has 1 columns but relation public.foo has 4")
+
+	_, err = d.decode((&pgWriter{}).uint8('I').uint32(16385).uint8('N').uint16(4).text("1").b)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "message is too short")
+
+	_, err = d.decode((&pgWriter{}).uint8('I').uint32(16385).uint8('N').uint16(4).
+		text("nope").text("").text("").text("").b)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to decode column id")
+
+	_, err = d.decode([]byte("Z"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unrecognised pgoutput message type 'Z'")
+}
+
+func TestCDCStreamCheckpoints(t *testing.T) {
+	s := newCDCStream(&postgresCDCInput{}, nil, nil)
+
+	// First transaction with two changes
+	ackA := s.track(0, 1)
+	ackB := s.track(0, 1)
+	s.track(100, 0)()
+
+	// Second transaction with one change
+	ackC := s.track(100, 1)
+	s.track(200, 0)()
+
+	ackC()
+	assert.Equal(t, lsn(0), s.confirmed)
+
+	ackB()
+	assert.Equal(t, lsn(0), s.confirmed)
+
+	ackA()
+	assert.Equal(t, lsn(200), s.confirmed)
+
+	// Transactions without any changes are confirmed immediately
+	s.track(300, 0)()
+	assert.Equal(t, lsn(300), s.confirmed)
+
+	ackD := s.track(300, 1)
+	s.track(400, 0)()
+	ackE := s.track(400, 1)
+
+	ackD()
+	assert.Equal(t, lsn(400), s.confirmed)
+
+	ackE()
+	assert.Equal(t, lsn(400), s.confirmed)
+}
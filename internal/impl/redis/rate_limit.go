@@ -0,0 +1,201 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v7"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func redisRatelimitConfig() *service.ConfigSpec {
+	spec := service.NewConfigSpec().
+		Beta().
+		Summary(`A rate limit backed by Redis, allowing a limit to be shared across any number of components within the pipeline and also across multiple running instances of Benthos.`).
+		Description(`
+Each access of the rate limit is checked and counted by an atomic Lua script executed by Redis, where the state of the limit is stored under the configured ` + "[`key`](#key)" + `. All instances of Benthos that share a rate limit must therefore be configured with the same key, count, interval and algorithm.
+
+### Algorithms
+
+The ` + "`fixed_window`" + ` algorithm allows up to ` + "`count`" + ` requests within each window of ` + "`interval`" + `, where the window starts at the first request. This is the cheapest algorithm but allows bursts of up to twice the count across the boundary of two windows.
+
+The ` + "`sliding_window`" + ` algorithm estimates the number of requests made within the last ` + "`interval`" + ` by weighting the count of the previous window by how much it overlaps with the sliding window. This smooths out bursts across window boundaries at the cost of a little more work per request, and the time used to calculate windows is that of the Redis server, which means it is unaffected by clock skew between instances of Benthos.`)
+
+	for _, f := range clientFields() {
+		spec = spec.Field(f)
+	}
+
+	spec = spec.
+		Field(service.NewStringField("key").
+			Description("The key to store the state of the rate limit under, which must be shared by all instances of the rate limit.").
+			Example("benthos_ratelimit")).
+		Field(service.NewIntField("count").
+			Description("The maximum number of requests to allow for a given period of time.").
+			Default(1000)).
+		Field(service.NewDurationField("interval").
+			Description("The time window to limit requests by.").
+			Default("1s")).
+		Field(service.NewStringEnumField("algorithm", "fixed_window", "sliding_window").
+			Description("The algorithm used to limit requests.").
+			Default("fixed_window").
+			Advanced())
+
+	return spec
+}
+
+func init() {
+	err := service.RegisterRateLimit(
+		"redis", redisRatelimitConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.RateLimit, error) {
+			return newRedisRatelimitFromConfig(conf)
+		})
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+func newRedisRatelimitFromConfig(conf *service.ParsedConfig) (*redisRatelimit, error) {
+	client, err := getClient(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := conf.FieldString("key")
+	if err != nil {
+		return nil, err
+	}
+	count, err := conf.FieldInt("count")
+	if err != nil {
+		return nil, err
+	}
+	interval, err := conf.FieldDuration("interval")
+	if err != nil {
+		return nil, err
+	}
+	algorithm, err := conf.FieldString("algorithm")
+	if err != nil {
+		return nil, err
+	}
+	return newRedisRatelimit(client, key, count, interval, algorithm)
+}
+
+//------------------------------------------------------------------------------
+
+// fixedWindowScript counts requests within a key that expires at the end of
+// the window, returning the number of milliseconds to wait when the count has
+// been exceeded.
+//
+// KEYS[1] = key, ARGV[1] = count, ARGV[2] = interval in milliseconds.
+var fixedWindowScript = redis.NewScript(`
+local current = redis.call("INCR", KEYS[1])
+if current == 1 then
+  redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+if current <= tonumber(ARGV[1]) then
+  return 0
+end
+local ttl = redis.call("PTTL", KEYS[1])
+if ttl < 0 then
+  -- The key somehow lost its expiry, therefore reset the window.
+  redis.call("PEXPIRE", KEYS[1], ARGV[2])
+  ttl = tonumber(ARGV[2])
+end
+return ttl
+`)
+
+// slidingWindowScript stores the counts of the current and previous windows
+// within a hash, where the number of requests within the sliding window is
+// estimated as the count of the current window plus the count of the previous
+// window weighted by its overlap with the sliding window. Returns the number of
+// milliseconds to wait when the count has been exceeded.
+//
+// KEYS[1] = key, ARGV[1] = count, ARGV[2] = interval in milliseconds.
+var slidingWindowScript = redis.NewScript(`
+redis.replicate_commands()
+
+local count = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local window = math.floor(now / interval)
+local elapsed = now - (window * interval)
+
+local state = redis.call("HMGET", KEYS[1], "window", "current", "previous")
+local current = tonumber(state[2]) or 0
+local previous = tonumber(state[3]) or 0
+
+local stateWindow = tonumber(state[1])
+if stateWindow ~= window then
+  if stateWindow == window - 1 then
+    previous = current
+  else
+    previous = 0
+  end
+  current = 0
+end
+
+local wait = 0
+if current >= count then
+  wait = interval - elapsed
+elseif previous * (interval - elapsed) / interval + current >= count then
+  wait = math.max(1, interval - elapsed - ((count - current) * interval / previous))
+else
+  current = current + 1
+end
+
+redis.call("HSET", KEYS[1], "window", window, "current", current, "previous", previous)
+redis.call("PEXPIRE", KEYS[1], interval * 2)
+return math.ceil(wait)
+`)
+
+type redisRatelimit struct {
+	client   redis.UniversalClient
+	script   *redis.Script
+	key      string
+	count    int
+	interval time.Duration
+}
+
+func newRedisRatelimit(client redis.UniversalClient, key string, count int, interval time.Duration, algorithm string) (*redisRatelimit, error) {
+	if count <= 0 {
+		return nil, errors.New("count must be larger than zero")
+	}
+	if interval < time.Millisecond {
+		return nil, errors.New("interval must be at least one millisecond")
+	}
+
+	r := &redisRatelimit{
+		client:   client,
+		key:      key,
+		count:    count,
+		interval: interval,
+	}
+	switch algorithm {
+	case "fixed_window":
+		r.script = fixedWindowScript
+	case "sliding_window":
+		r.script = slidingWindowScript
+	default:
+		return nil, errors.New("algorithm not recognised: " + algorithm)
+	}
+	return r, nil
+}
+
+func (r *redisRatelimit) Access(ctx context.Context) (time.Duration, error) {
+	waitMillis, err := r.script.Run(
+		r.client, []string{r.key},
+		r.count, int64(r.interval/time.Millisecond),
+	).Int64()
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(waitMillis) * time.Millisecond, nil
+}
+
+func (r *redisRatelimit) Close(ctx context.Context) error {
+	return r.client.Close()
+}
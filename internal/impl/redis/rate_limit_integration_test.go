@@ -0,0 +1,90 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ory/dockertest/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/integration"
+)
+
+func TestIntegrationRedisRatelimit(t *testing.T) {
+	integration.CheckSkip(t)
+	t.Parallel()
+
+	pool, err := dockertest.NewPool("")
+	require.NoError(t, err)
+
+	pool.MaxWait = time.Second * 30
+
+	resource, err := pool.Run("redis", "latest", nil)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, pool.Purge(resource))
+	})
+
+	resource.Expire(900)
+
+	newRatelimit := func(key, algorithm string) *redisRatelimit {
+		pConf, err := redisRatelimitConfig().ParseYAML(fmt.Sprintf(`
+url: tcp://localhost:%v/1
+key: %v
+count: 3
+interval: 2s
+algorithm: %v
+`, resource.GetPort("6379/tcp"), key, algorithm), nil)
+		require.NoError(t, err)
+
+		r, err := newRedisRatelimitFromConfig(pConf)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_ = r.Close(context.Background())
+		})
+		return r
+	}
+
+	require.NoError(t, pool.Retry(func() error {
+		_, err := newRatelimit("benthos_test_redis_connect", "fixed_window").Access(context.Background())
+		return err
+	}))
+
+	for _, algorithm := range []string{"fixed_window", "sliding_window"} {
+		algorithm := algorithm
+		t.Run(algorithm, func(t *testing.T) {
+			ctx := context.Background()
+
+			// Two instances sharing a key share the limit.
+			rA := newRatelimit(algorithm, algorithm)
+			rB := newRatelimit(algorithm, algorithm)
+
+			for _, r := range []*redisRatelimit{rA, rB, rA} {
+				period, err := r.Access(ctx)
+				require.NoError(t, err)
+				assert.Equal(t, time.Duration(0), period)
+			}
+
+			for _, r := range []*redisRatelimit{rA, rB} {
+				period, err := r.Access(ctx)
+				require.NoError(t, err)
+				assert.Greater(t, int64(period), int64(0))
+				assert.LessOrEqual(t, int64(period), int64(time.Second*2))
+			}
+
+			// Instances with a different key are unaffected.
+			period, err := newRatelimit(algorithm+"_other", algorithm).Access(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, time.Duration(0), period)
+
+			assert.Eventually(t, func() bool {
+				period, err := rA.Access(ctx)
+				require.NoError(t, err)
+				return period == 0
+			}, time.Second*5, time.Millisecond*100)
+		})
+	}
+}
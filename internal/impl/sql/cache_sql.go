@@ -0,0 +1,375 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+
+	"github.com/benthosdev/benthos/v4/internal/shutdown"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+// CacheConfig returns a config spec for an sql cache.
+func CacheConfig() *service.ConfigSpec {
+	return service.NewConfigSpec().
+		Beta().
+		Summary("Uses an SQL database table as a cache, where each item is stored as a row.").
+		Description(`
+Each cache item is a row of the configured ` + "[`table`](#table)" + `, where the key of the item is stored within the ` + "[`key_column`](#key_column)" + ` and the value within the ` + "[`value_column`](#value_column)" + `. The key column must be the primary key of the table (or have a unique constraint), as the operations ` + "`set`" + ` and ` + "`add`" + ` are executed as inserts with conflict resolution.
+
+The table is not created by this cache and must therefore exist beforehand, the following is an example of a suitable table for the ` + "`postgres`" + ` driver:
+
+` + "```sql" + `
+CREATE TABLE benthos_cache (
+  cache_key varchar(256) PRIMARY KEY,
+  cache_value bytea NOT NULL,
+  expires_at bigint
+);
+` + "```" + `
+
+This cache currently supports the drivers ` + "`postgres`" + `, ` + "`sqlite`" + ` and ` + "`mysql`" + `.
+
+### TTLs
+
+When an ` + "[`expires_column`](#expires_column)" + ` is specified items can be given a TTL, and the column is populated with the time at which the item expires as an integer unix timestamp in milliseconds, or ` + "`NULL`" + ` when the item never expires. Expired items are ignored by the cache, and are periodically deleted from the table according to the ` + "[`reap_interval`](#reap_interval)" + `.`).
+		Field(driverField).
+		Field(dsnField).
+		Field(service.NewStringField("table").
+			Description("The table to store items in.").
+			Example("benthos_cache")).
+		Field(service.NewStringField("key_column").
+			Description("The column to store item keys within.").
+			Example("cache_key")).
+		Field(service.NewStringField("value_column").
+			Description("The column to store item values within.").
+			Example("cache_value")).
+		Field(service.NewStringField("expires_column").
+			Description("An optional column to store the expiry time of items within. When omitted item TTLs are ignored.").
+			Optional().
+			Example("expires_at")).
+		Field(service.NewDurationField("default_ttl").
+			Description("An optional default TTL to set for items, calculated from the moment the item is cached. An `expires_column` must be specified in order to set item TTLs.").
+			Optional().
+			Advanced()).
+		Field(service.NewDurationField("reap_interval").
+			Description("The period of time between each deletion of expired items from the table. Setting this to `0s` disables the deletion of expired items, which can be useful when the database has its own means of removing them.").
+			Default("1m").
+			Advanced())
+}
+
+func init() {
+	err := service.RegisterCache(
+		"sql", CacheConfig(),
+		func(conf *service.ParsedConfig, mgr *service.Resources) (service.Cache, error) {
+			return NewSQLCacheFromConfig(conf, mgr.Logger())
+		})
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// sqlCacheDialect describes the driver specific clauses required for inserts
+// that either overwrite or ignore an existing row with a conflicting key.
+type sqlCacheDialect struct {
+	upsert func(b squirrel.InsertBuilder, keyCol string, updateCols []string) squirrel.InsertBuilder
+	ignore func(b squirrel.InsertBuilder, keyCol string) squirrel.InsertBuilder
+}
+
+var onConflictDialect = sqlCacheDialect{
+	upsert: func(b squirrel.InsertBuilder, keyCol string, updateCols []string) squirrel.InsertBuilder {
+		setStr := ""
+		for i, col := range updateCols {
+			if i > 0 {
+				setStr += ", "
+			}
+			setStr += fmt.Sprintf("%v = excluded.%v", col, col)
+		}
+		return b.Suffix(fmt.Sprintf("ON CONFLICT (%v) DO UPDATE SET %v", keyCol, setStr))
+	},
+	ignore: func(b squirrel.InsertBuilder, keyCol string) squirrel.InsertBuilder {
+		return b.Suffix(fmt.Sprintf("ON CONFLICT (%v) DO NOTHING", keyCol))
+	},
+}
+
+var sqlCacheDialects = map[string]sqlCacheDialect{
+	"postgres": onConflictDialect,
+	"sqlite":   onConflictDialect,
+	"mysql": {
+		upsert: func(b squirrel.InsertBuilder, keyCol string, updateCols []string) squirrel.InsertBuilder {
+			setStr := ""
+			for i, col := range updateCols {
+				if i > 0 {
+					setStr += ", "
+				}
+				setStr += fmt.Sprintf("%v = VALUES(%v)", col, col)
+			}
+			return b.Suffix("ON DUPLICATE KEY UPDATE " + setStr)
+		},
+		ignore: func(b squirrel.InsertBuilder, keyCol string) squirrel.InsertBuilder {
+			return b.Options("IGNORE")
+		},
+	},
+}
+
+type sqlCache struct {
+	db      *sql.DB
+	dialect sqlCacheDialect
+	format  squirrel.PlaceholderFormat
+
+	table      string
+	keyColumn  string
+	valColumn  string
+	expColumn  string
+	defaultTTL *time.Duration
+
+	logger  *service.Logger
+	shutSig *shutdown.Signaller
+}
+
+// NewSQLCacheFromConfig returns an internal sql cache.
+// nolint:revive // Not bothered as this is internal anyway
+func NewSQLCacheFromConfig(conf *service.ParsedConfig, logger *service.Logger) (*sqlCache, error) {
+	s := &sqlCache{
+		logger:  logger,
+		shutSig: shutdown.NewSignaller(),
+	}
+
+	driver, driverSpec, err := driverFromConf(conf)
+	if err != nil {
+		return nil, err
+	}
+	var exists bool
+	if s.dialect, exists = sqlCacheDialects[driver]; !exists {
+		return nil, fmt.Errorf("sql driver '%v' is not supported by the sql cache", driver)
+	}
+	s.format = placeholderFormat(driverSpec)
+
+	dsn, err := conf.FieldString("dsn")
+	if err != nil {
+		return nil, err
+	}
+
+	if s.table, err = conf.FieldString("table"); err != nil {
+		return nil, err
+	}
+	if s.keyColumn, err = conf.FieldString("key_column"); err != nil {
+		return nil, err
+	}
+	if s.valColumn, err = conf.FieldString("value_column"); err != nil {
+		return nil, err
+	}
+	if conf.Contains("expires_column") {
+		if s.expColumn, err = conf.FieldString("expires_column"); err != nil {
+			return nil, err
+		}
+	}
+	if conf.Contains("default_ttl") {
+		ttl, err := conf.FieldDuration("default_ttl")
+		if err != nil {
+			return nil, err
+		}
+		s.defaultTTL = &ttl
+	}
+
+	reapInterval, err := conf.FieldDuration("reap_interval")
+	if err != nil {
+		return nil, err
+	}
+
+	if s.db, err = sql.Open(driver, dsn); err != nil {
+		return nil, err
+	}
+
+	go s.reapLoop(reapInterval)
+	return s, nil
+}
+
+//------------------------------------------------------------------------------
+
+func (s *sqlCache) reapLoop(interval time.Duration) {
+	defer func() {
+		_ = s.db.Close()
+		s.shutSig.ShutdownComplete()
+	}()
+
+	if s.expColumn == "" || interval <= 0 {
+		<-s.shutSig.CloseNowChan()
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	ctx, done := s.shutSig.CloseNowCtx(context.Background())
+	defer done()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.reap(ctx); err != nil && ctx.Err() == nil {
+				s.logger.Errorf("Failed to delete expired cache items: %v", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *sqlCache) reap(ctx context.Context) error {
+	_, err := squirrel.Delete(s.table).
+		Where(squirrel.LtOrEq{s.expColumn: unixMillis(time.Now())}).
+		PlaceholderFormat(s.format).
+		RunWith(s.db).
+		ExecContext(ctx)
+	return err
+}
+
+func unixMillis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+// notExpired returns a condition that excludes rows that have expired.
+func (s *sqlCache) notExpired() squirrel.Sqlizer {
+	if s.expColumn == "" {
+		return squirrel.Expr("1 = 1")
+	}
+	return squirrel.Or{
+		squirrel.Eq{s.expColumn: nil},
+		squirrel.Gt{s.expColumn: unixMillis(time.Now())},
+	}
+}
+
+func (s *sqlCache) insertBuilder(key string, value []byte, ttl *time.Duration) squirrel.InsertBuilder {
+	if s.expColumn == "" {
+		return squirrel.Insert(s.table).
+			Columns(s.keyColumn, s.valColumn).
+			Values(key, value).
+			PlaceholderFormat(s.format)
+	}
+
+	if ttl == nil {
+		ttl = s.defaultTTL
+	}
+	var expires interface{}
+	if ttl != nil {
+		expires = unixMillis(time.Now().Add(*ttl))
+	}
+	return squirrel.Insert(s.table).
+		Columns(s.keyColumn, s.valColumn, s.expColumn).
+		Values(key, value, expires).
+		PlaceholderFormat(s.format)
+}
+
+func (s *sqlCache) updateColumns() []string {
+	if s.expColumn == "" {
+		return []string{s.valColumn}
+	}
+	return []string{s.valColumn, s.expColumn}
+}
+
+//------------------------------------------------------------------------------
+
+func (s *sqlCache) Get(ctx context.Context, key string) ([]byte, error) {
+	var value []byte
+	err := squirrel.Select(s.valColumn).
+		From(s.table).
+		Where(squirrel.Eq{s.keyColumn: key}).
+		Where(s.notExpired()).
+		PlaceholderFormat(s.format).
+		RunWith(s.db).
+		QueryRowContext(ctx).
+		Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrKeyNotFound
+	}
+	return value, err
+}
+
+func (s *sqlCache) Set(ctx context.Context, key string, value []byte, ttl *time.Duration) error {
+	_, err := s.dialect.upsert(s.insertBuilder(key, value, ttl), s.keyColumn, s.updateColumns()).
+		RunWith(s.db).
+		ExecContext(ctx)
+	return err
+}
+
+func (s *sqlCache) SetMulti(ctx context.Context, items ...service.CacheItem) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if _, err = s.dialect.upsert(s.insertBuilder(item.Key, item.Value, item.TTL), s.keyColumn, s.updateColumns()).
+			RunWith(tx).
+			ExecContext(ctx); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *sqlCache) Add(ctx context.Context, key string, value []byte, ttl *time.Duration) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	// An expired row that hasn't yet been reaped would otherwise prevent the
+	// key from being added.
+	if s.expColumn != "" {
+		if _, err = squirrel.Delete(s.table).
+			Where(squirrel.Eq{s.keyColumn: key}).
+			Where(squirrel.LtOrEq{s.expColumn: unixMillis(time.Now())}).
+			PlaceholderFormat(s.format).
+			RunWith(tx).
+			ExecContext(ctx); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	res, err := s.dialect.ignore(s.insertBuilder(key, value, ttl), s.keyColumn).
+		RunWith(tx).
+		ExecContext(ctx)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if n == 0 {
+		_ = tx.Rollback()
+		return service.ErrKeyAlreadyExists
+	}
+	return tx.Commit()
+}
+
+func (s *sqlCache) Delete(ctx context.Context, key string) error {
+	_, err := squirrel.Delete(s.table).
+		Where(squirrel.Eq{s.keyColumn: key}).
+		PlaceholderFormat(s.format).
+		RunWith(s.db).
+		ExecContext(ctx)
+	return err
+}
+
+func (s *sqlCache) Close(ctx context.Context) error {
+	s.shutSig.CloseNow()
+	select {
+	case <-s.shutSig.HasClosedChan():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
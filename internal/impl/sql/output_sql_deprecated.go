@@ -53,7 +53,7 @@ func init() {
 //------------------------------------------------------------------------------
 
 func newSQLDeprecatedOutputFromConfig(conf *service.ParsedConfig, logger *service.Logger) (*sqlRawOutput, error) {
-	driverStr, err := conf.FieldString("driver")
+	driverStr, driverSpec, err := driverFromConf(conf)
 	if err != nil {
 		return nil, err
 	}
@@ -75,9 +75,5 @@ func newSQLDeprecatedOutputFromConfig(conf *service.ParsedConfig, logger *servic
 		}
 	}
 
-	_, useTxStmt := map[string]struct{}{
-		"clickhouse": {},
-	}[driverStr]
-
-	return newSQLRawOutput(logger, driverStr, dsnStr, useTxStmt, queryStatic, argsMapping), nil
+	return newSQLRawOutput(logger, driverStr, dsnStr, driverSpec.UseTxStmt, queryStatic, argsMapping), nil
 }
@@ -106,16 +106,12 @@ func newSQLInsertOutputFromConfig(conf *service.ParsedConfig, logger *service.Lo
 		shutSig: shutdown.NewSignaller(),
 	}
 
-	var err error
-
-	if s.driver, err = conf.FieldString("driver"); err != nil {
+	driver, driverSpec, err := driverFromConf(conf)
+	if err != nil {
 		return nil, err
 	}
-	if _, in := map[string]struct{}{
-		"clickhouse": {},
-	}[s.driver]; in {
-		s.useTxStmt = true
-	}
+	s.driver = driver
+	s.useTxStmt = driverSpec.UseTxStmt
 
 	if s.dsn, err = conf.FieldString("dsn"); err != nil {
 		return nil, err
@@ -137,10 +133,7 @@ func newSQLInsertOutputFromConfig(conf *service.ParsedConfig, logger *service.Lo
 		}
 	}
 
-	s.builder = squirrel.Insert(tableStr).Columns(columns...)
-	if s.driver == "postgres" {
-		s.builder = s.builder.PlaceholderFormat(squirrel.Dollar)
-	}
+	s.builder = squirrel.Insert(tableStr).Columns(columns...).PlaceholderFormat(placeholderFormat(driverSpec))
 
 	if conf.Contains("prefix") {
 		prefixStr, err := conf.FieldString("prefix")
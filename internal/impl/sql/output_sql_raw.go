@@ -88,7 +88,7 @@ type sqlRawOutput struct {
 }
 
 func newSQLRawOutputFromConfig(conf *service.ParsedConfig, logger *service.Logger) (*sqlRawOutput, error) {
-	driverStr, err := conf.FieldString("driver")
+	driverStr, driverSpec, err := driverFromConf(conf)
 	if err != nil {
 		return nil, err
 	}
@@ -110,11 +110,7 @@ func newSQLRawOutputFromConfig(conf *service.ParsedConfig, logger *service.Logge
 		}
 	}
 
-	_, useTxStmt := map[string]struct{}{
-		"clickhouse": {},
-	}[driverStr]
-
-	return newSQLRawOutput(logger, driverStr, dsnStr, useTxStmt, queryStatic, argsMapping), nil
+	return newSQLRawOutput(logger, driverStr, dsnStr, driverSpec.UseTxStmt, queryStatic, argsMapping), nil
 }
 
 func newSQLRawOutput(
@@ -52,7 +52,7 @@ func init() {
 // NewSQLDeprecatedProcessorFromConfig returns an internal sql processor.
 // nolint:revive // Not bothered as this is internal anyway
 func NewSQLDeprecatedProcessorFromConfig(conf *service.ParsedConfig, logger *service.Logger) (*sqlRawProcessor, error) {
-	driverStr, err := conf.FieldString("driver")
+	driverStr, _, err := driverFromConf(conf)
 	if err != nil {
 		return nil, err
 	}
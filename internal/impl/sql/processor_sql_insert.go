@@ -98,15 +98,11 @@ func NewSQLInsertProcessorFromConfig(conf *service.ParsedConfig, logger *service
 		shutSig: shutdown.NewSignaller(),
 	}
 
-	driverStr, err := conf.FieldString("driver")
+	driverStr, driverSpec, err := driverFromConf(conf)
 	if err != nil {
 		return nil, err
 	}
-	if _, in := map[string]struct{}{
-		"clickhouse": {},
-	}[driverStr]; in {
-		s.useTxStmt = true
-	}
+	s.useTxStmt = driverSpec.UseTxStmt
 
 	dsnStr, err := conf.FieldString("dsn")
 	if err != nil {
@@ -129,10 +125,7 @@ func NewSQLInsertProcessorFromConfig(conf *service.ParsedConfig, logger *service
 		}
 	}
 
-	s.builder = squirrel.Insert(tableStr).Columns(columns...)
-	if driverStr == "postgres" {
-		s.builder = s.builder.PlaceholderFormat(squirrel.Dollar)
-	}
+	s.builder = squirrel.Insert(tableStr).Columns(columns...).PlaceholderFormat(placeholderFormat(driverSpec))
 
 	if conf.Contains("prefix") {
 		prefixStr, err := conf.FieldString("prefix")
@@ -100,7 +100,7 @@ type sqlRawProcessor struct {
 // NewSQLRawProcessorFromConfig returns an internal sql_raw processor.
 // nolint:revive // Not bothered as this is internal anyway
 func NewSQLRawProcessorFromConfig(conf *service.ParsedConfig, logger *service.Logger) (*sqlRawProcessor, error) {
-	driverStr, err := conf.FieldString("driver")
+	driverStr, _, err := driverFromConf(conf)
 	if err != nil {
 		return nil, err
 	}
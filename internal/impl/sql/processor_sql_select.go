@@ -106,7 +106,7 @@ func NewSQLSelectProcessorFromConfig(conf *service.ParsedConfig, logger *service
 		shutSig: shutdown.NewSignaller(),
 	}
 
-	driverStr, err := conf.FieldString("driver")
+	driverStr, driverSpec, err := driverFromConf(conf)
 	if err != nil {
 		return nil, err
 	}
@@ -138,10 +138,7 @@ func NewSQLSelectProcessorFromConfig(conf *service.ParsedConfig, logger *service
 		}
 	}
 
-	s.builder = squirrel.Select(columns...).From(tableStr)
-	if driverStr == "postgres" {
-		s.builder = s.builder.PlaceholderFormat(squirrel.Dollar)
-	}
+	s.builder = squirrel.Select(columns...).From(tableStr).PlaceholderFormat(placeholderFormat(driverSpec))
 
 	if conf.Contains("prefix") {
 		prefixStr, err := conf.FieldString("prefix")
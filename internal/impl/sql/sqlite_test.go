@@ -0,0 +1,352 @@
+package sql_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	isql "github.com/benthosdev/benthos/v4/internal/impl/sql"
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func TestSQLite(t *testing.T) {
+	if !driverAvailable("sqlite") {
+		t.Skip("Skipping as the sqlite driver is not available on this platform")
+	}
+
+	dsn := fmt.Sprintf("file:%v?_pragma=busy_timeout(5000)", filepath.Join(t.TempDir(), "test.db"))
+
+	db, err := sql.Open("sqlite", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	createTable := func(name string) error {
+		_, err := db.Exec(fmt.Sprintf(`create table %v (
+  foo varchar(50) not null,
+  bar integer not null,
+  baz varchar(50) not null,
+  primary key (foo)
+		);`, name))
+		return err
+	}
+
+	testSuite(t, "sqlite", dsn, createTable)
+}
+
+func TestSQLiteCache(t *testing.T) {
+	if !driverAvailable("sqlite") {
+		t.Skip("Skipping as the sqlite driver is not available on this platform")
+	}
+
+	dsn := fmt.Sprintf("file:%v?_pragma=busy_timeout(5000)", filepath.Join(t.TempDir(), "test.db"))
+
+	db, err := sql.Open("sqlite", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	_, err = db.Exec(`create table cache (
+  cache_key varchar(256) primary key,
+  cache_value blob not null,
+  expires_at bigint
+);`)
+	require.NoError(t, err)
+
+	conf, err := isql.CacheConfig().ParseYAML(fmt.Sprintf(`
+driver: sqlite
+dsn: %v
+table: cache
+key_column: cache_key
+value_column: cache_value
+expires_column: expires_at
+reap_interval: 10ms
+`, dsn), service.NewEnvironment())
+	require.NoError(t, err)
+
+	c, err := isql.NewSQLCacheFromConfig(conf, nil)
+	require.NoError(t, err)
+
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	t.Cleanup(func() {
+		require.NoError(t, c.Close(context.Background()))
+	})
+
+	_, err = c.Get(tCtx, "foo")
+	assert.Equal(t, service.ErrKeyNotFound, err)
+
+	require.NoError(t, c.Set(tCtx, "foo", []byte("1"), nil))
+
+	act, err := c.Get(tCtx, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, "1", string(act))
+
+	require.NoError(t, c.Add(tCtx, "bar", []byte("2"), nil))
+
+	act, err = c.Get(tCtx, "bar")
+	require.NoError(t, err)
+	assert.Equal(t, "2", string(act))
+
+	assert.Equal(t, service.ErrKeyAlreadyExists, c.Add(tCtx, "foo", []byte("2"), nil))
+
+	require.NoError(t, c.Set(tCtx, "foo", []byte("3"), nil))
+
+	act, err = c.Get(tCtx, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, "3", string(act))
+
+	require.NoError(t, c.SetMulti(tCtx,
+		service.CacheItem{Key: "foo", Value: []byte("4")},
+		service.CacheItem{Key: "baz", Value: []byte("5")},
+	))
+
+	act, err = c.Get(tCtx, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, "4", string(act))
+
+	act, err = c.Get(tCtx, "baz")
+	require.NoError(t, err)
+	assert.Equal(t, "5", string(act))
+
+	require.NoError(t, c.Delete(tCtx, "foo"))
+
+	_, err = c.Get(tCtx, "foo")
+	assert.Equal(t, service.ErrKeyNotFound, err)
+
+	ttl := time.Millisecond * 50
+	require.NoError(t, c.Set(tCtx, "buz", []byte("6"), &ttl))
+
+	act, err = c.Get(tCtx, "buz")
+	require.NoError(t, err)
+	assert.Equal(t, "6", string(act))
+
+	assert.Eventually(t, func() bool {
+		var count int
+		require.NoError(t, db.QueryRow(`select count(*) from cache where cache_key = 'buz'`).Scan(&count))
+		return count == 0
+	}, time.Second*5, time.Millisecond*10)
+
+	_, err = c.Get(tCtx, "buz")
+	assert.Equal(t, service.ErrKeyNotFound, err)
+
+	require.NoError(t, c.Add(tCtx, "buz", []byte("7"), &ttl))
+	<-time.After(ttl)
+
+	_, err = c.Get(tCtx, "buz")
+	assert.Equal(t, service.ErrKeyNotFound, err)
+	require.NoError(t, c.Add(tCtx, "buz", []byte("8"), nil))
+
+	act, err = c.Get(tCtx, "buz")
+	require.NoError(t, err)
+	assert.Equal(t, "8", string(act))
+}
+
+// runSQLitePollingStream runs a stream from a config until the expected rows
+// have been consumed and the final checkpoint has been stored, calling midway
+// once half of the rows have been consumed.
+func runSQLitePollingStream(ctx context.Context, t *testing.T, conf, checkpointPath, lastCheckpoint string, expected []string, midway func()) {
+	t.Helper()
+
+	streamBuilder := service.NewStreamBuilder()
+	require.NoError(t, streamBuilder.SetYAML(conf))
+
+	var received []string
+	allReceived := make(chan struct{})
+	require.NoError(t, streamBuilder.AddConsumerFunc(func(ctx context.Context, msg *service.Message) error {
+		b, err := msg.AsBytes()
+		require.NoError(t, err)
+		received = append(received, string(b))
+		if len(received) == len(expected)/2 && midway != nil {
+			midway()
+		}
+		if len(received) == len(expected) {
+			close(allReceived)
+		}
+		return nil
+	}))
+
+	stream, err := streamBuilder.Build()
+	require.NoError(t, err)
+
+	go func() {
+		_ = stream.Run(ctx)
+	}()
+
+	select {
+	case <-allReceived:
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for rows")
+	}
+
+	// Allow the checkpoint of the final row to be stored.
+	assert.Eventually(t, func() bool {
+		b, err := os.ReadFile(checkpointPath)
+		return err == nil && string(b) == lastCheckpoint
+	}, time.Second*5, time.Millisecond*10)
+
+	require.NoError(t, stream.StopWithin(time.Second*10))
+	assert.Equal(t, expected, received)
+}
+
+func TestSQLiteSelectPolling(t *testing.T) {
+	if !driverAvailable("sqlite") {
+		t.Skip("Skipping as the sqlite driver is not available on this platform")
+	}
+
+	dsn := fmt.Sprintf("file:%v?_pragma=busy_timeout(5000)", filepath.Join(t.TempDir(), "test.db"))
+	checkpointDir := t.TempDir()
+
+	db, err := sql.Open("sqlite", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	_, err = db.Exec(`create table footable (
+  id integer primary key,
+  name varchar(50) not null
+);`)
+	require.NoError(t, err)
+
+	insertRows := func(names ...string) {
+		for _, name := range names {
+			_, err := db.Exec(`insert into footable (name) values (?)`, name)
+			require.NoError(t, err)
+		}
+	}
+
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	conf := fmt.Sprintf(`
+input:
+  sql_select:
+    driver: sqlite
+    dsn: %v
+    table: footable
+    columns: [ id, name ]
+    polling:
+      interval: 10ms
+      checkpoint_column: id
+      checkpoint_cache: checkpoints
+
+cache_resources:
+  - label: checkpoints
+    file:
+      directory: %v
+
+logger:
+  level: none
+`, dsn, checkpointDir)
+	checkpointPath := filepath.Join(checkpointDir, "sql_select_checkpoint")
+
+	insertRows("foo", "bar")
+	runSQLitePollingStream(tCtx, t, conf, checkpointPath, "4", []string{
+		`{"id":1,"name":"foo"}`,
+		`{"id":2,"name":"bar"}`,
+		`{"id":3,"name":"baz"}`,
+		`{"id":4,"name":"buz"}`,
+	}, func() {
+		insertRows("baz", "buz")
+	})
+
+	insertRows("qux", "quz")
+	runSQLitePollingStream(tCtx, t, conf, checkpointPath, "6", []string{
+		`{"id":5,"name":"qux"}`,
+		`{"id":6,"name":"quz"}`,
+	}, nil)
+}
+
+func TestSQLiteSelectPollingTiebreaker(t *testing.T) {
+	if !driverAvailable("sqlite") {
+		t.Skip("Skipping as the sqlite driver is not available on this platform")
+	}
+
+	dsn := fmt.Sprintf("file:%v?_pragma=busy_timeout(5000)", filepath.Join(t.TempDir(), "test.db"))
+	checkpointDir := t.TempDir()
+
+	db, err := sql.Open("sqlite", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	_, err = db.Exec(`create table footable (
+  id integer primary key,
+  updated_at integer not null,
+  name varchar(50) not null
+);`)
+	require.NoError(t, err)
+
+	insertRow := func(updatedAt int, name string) {
+		_, err := db.Exec(`insert into footable (updated_at, name) values (?, ?)`, updatedAt, name)
+		require.NoError(t, err)
+	}
+
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	conf := fmt.Sprintf(`
+input:
+  sql_select:
+    driver: sqlite
+    dsn: %v
+    table: footable
+    columns: [ id, updated_at, name ]
+    polling:
+      interval: 10ms
+      checkpoint_column: updated_at
+      checkpoint_tiebreaker_column: id
+      checkpoint_cache: checkpoints
+
+cache_resources:
+  - label: checkpoints
+    file:
+      directory: %v
+
+logger:
+  level: none
+`, dsn, checkpointDir)
+	checkpointPath := filepath.Join(checkpointDir, "sql_select_checkpoint")
+
+	// Rows that share the checkpoint column value of the last row consumed
+	// must not be skipped.
+	insertRow(1, "foo")
+	insertRow(2, "bar")
+	runSQLitePollingStream(tCtx, t, conf, checkpointPath, "[3,4]", []string{
+		`{"id":1,"name":"foo","updated_at":1}`,
+		`{"id":2,"name":"bar","updated_at":2}`,
+		`{"id":3,"name":"baz","updated_at":2}`,
+		`{"id":4,"name":"buz","updated_at":3}`,
+	}, func() {
+		insertRow(2, "baz")
+		insertRow(3, "buz")
+	})
+
+	insertRow(3, "qux")
+	insertRow(4, "quz")
+	runSQLitePollingStream(tCtx, t, conf, checkpointPath, "[4,6]", []string{
+		`{"id":5,"name":"qux","updated_at":3}`,
+		`{"id":6,"name":"quz","updated_at":4}`,
+	}, nil)
+}
+
+func driverAvailable(name string) bool {
+	for _, d := range sql.Drivers() {
+		if d == name {
+			return true
+		}
+	}
+	return false
+}
@@ -2,8 +2,35 @@ package sql
 
 import (
 	"database/sql"
+
+	"github.com/Masterminds/squirrel"
+
+	"github.com/benthosdev/benthos/v4/internal/sqldriver"
+	"github.com/benthosdev/benthos/v4/public/service"
 )
 
+// driverFromConf extracts the name of the driver from a parsed config and
+// returns it along with its spec.
+func driverFromConf(conf *service.ParsedConfig) (string, sqldriver.Spec, error) {
+	driver, err := conf.FieldString("driver")
+	if err != nil {
+		return "", sqldriver.Spec{}, err
+	}
+	return driver, sqldriver.Get(driver), nil
+}
+
+func placeholderFormat(spec sqldriver.Spec) squirrel.PlaceholderFormat {
+	switch spec.Placeholder {
+	case sqldriver.PlaceholderDollar:
+		return squirrel.Dollar
+	case sqldriver.PlaceholderColon:
+		return squirrel.Colon
+	case sqldriver.PlaceholderAtP:
+		return squirrel.AtP
+	}
+	return squirrel.Question
+}
+
 func sqlRowsToArray(rows *sql.Rows) ([]interface{}, error) {
 	columnNames, err := rows.Columns()
 	if err != nil {
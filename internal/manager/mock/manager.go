@@ -4,6 +4,8 @@ import (
 	"context"
 	"net/http"
 
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/benthosdev/benthos/v4/internal/bloblang"
 	"github.com/benthosdev/benthos/v4/internal/component"
 	"github.com/benthosdev/benthos/v4/internal/component/cache"
@@ -65,6 +67,9 @@ func (m *Manager) Metrics() metrics.Type { return metrics.Noop() }
 // Logger returns a no-op logger.
 func (m *Manager) Logger() log.Modular { return log.Noop() }
 
+// Tracer returns a no-op tracer provider.
+func (m *Manager) Tracer() trace.TracerProvider { return trace.NewNoopTracerProvider() }
+
 // RegisterEndpoint registers a server wide HTTP endpoint.
 func (m *Manager) RegisterEndpoint(path, desc string, h http.HandlerFunc) {
 	if m.OnRegisterEndpoint != nil {
@@ -8,6 +8,8 @@ import (
 	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/benthosdev/benthos/v4/internal/bloblang"
 	"github.com/benthosdev/benthos/v4/internal/bloblang/query"
 	"github.com/benthosdev/benthos/v4/internal/bundle"
@@ -79,6 +81,7 @@ type Type struct {
 
 	logger log.Modular
 	stats  *metrics.Namespaced
+	tracer trace.TracerProvider
 
 	pipes    map[string]<-chan message.Transaction
 	pipeLock *sync.RWMutex
@@ -103,6 +106,14 @@ func OptSetBloblangEnvironment(env *bloblang.Environment) OptFunc {
 	}
 }
 
+// OptSetTracer determines the tracer provider from which components of the
+// manager create spans. By default spans are not recorded.
+func OptSetTracer(tracer trace.TracerProvider) OptFunc {
+	return func(t *Type) {
+		t.tracer = tracer
+	}
+}
+
 // NewV2 returns an instance of manager.Type, which can be shared amongst
 // components and logical threads of a Benthos service.
 func NewV2(conf ResourceConfig, apiReg APIReg, log log.Modular, stats *metrics.Namespaced, opts ...OptFunc) (*Type, error) {
@@ -122,6 +133,7 @@ func NewV2(conf ResourceConfig, apiReg APIReg, log log.Modular, stats *metrics.N
 
 		logger: log,
 		stats:  stats,
+		tracer: trace.NewNoopTracerProvider(),
 
 		pipes:    map[string]<-chan message.Transaction{},
 		pipeLock: &sync.RWMutex{},
@@ -342,6 +354,11 @@ func (t *Type) Logger() log.Modular {
 	return t.logger
 }
 
+// Tracer returns the tracer provider from which components create spans.
+func (t *Type) Tracer() trace.TracerProvider {
+	return t.tracer
+}
+
 // Environment returns a bundle environment used by the manager. This is for
 // internal use only.
 func (t *Type) Environment() *bundle.Environment {
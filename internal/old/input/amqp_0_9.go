@@ -96,5 +96,5 @@ func NewAMQP09(conf Config, mgr interop.Manager, log log.Modular, stats metrics.
 	if a, err = reader.NewAMQP09(conf.AMQP09, log, stats); err != nil {
 		return nil, err
 	}
-	return NewAsyncReader(TypeAMQP09, true, a, log, stats)
+	return NewAsyncReader(TypeAMQP09, true, a, mgr)
 }
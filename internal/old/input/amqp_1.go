@@ -59,7 +59,7 @@ func NewAMQP1(conf Config, mgr interop.Manager, log log.Modular, stats metrics.T
 	if a, err = reader.NewAMQP1(conf.AMQP1, log, stats); err != nil {
 		return nil, err
 	}
-	return NewAsyncReader(TypeAMQP1, true, a, log, stats)
+	return NewAsyncReader(TypeAMQP1, true, a, mgr)
 }
 
 //------------------------------------------------------------------------------
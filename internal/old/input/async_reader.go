@@ -7,10 +7,12 @@ import (
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/benthosdev/benthos/v4/internal/component"
 	"github.com/benthosdev/benthos/v4/internal/component/input"
 	"github.com/benthosdev/benthos/v4/internal/component/metrics"
+	"github.com/benthosdev/benthos/v4/internal/interop"
 	"github.com/benthosdev/benthos/v4/internal/log"
 	"github.com/benthosdev/benthos/v4/internal/message"
 	"github.com/benthosdev/benthos/v4/internal/old/input/reader"
@@ -29,8 +31,9 @@ type AsyncReader struct {
 	typeStr string
 	reader  reader.Async
 
-	stats metrics.Type
-	log   log.Modular
+	stats  metrics.Type
+	log    log.Modular
+	tracer trace.TracerProvider
 
 	transactions chan message.Transaction
 	shutSig      *shutdown.Signaller
@@ -41,8 +44,7 @@ func NewAsyncReader(
 	typeStr string,
 	allowSkipAcks bool,
 	r reader.Async,
-	log log.Modular,
-	stats metrics.Type,
+	mgr interop.Manager,
 ) (input.Streamed, error) {
 	boff := backoff.NewExponentialBackOff()
 	boff.InitialInterval = time.Millisecond * 100
@@ -54,8 +56,9 @@ func NewAsyncReader(
 		allowSkipAcks: allowSkipAcks,
 		typeStr:       typeStr,
 		reader:        r,
-		log:           log,
-		stats:         stats,
+		log:           mgr.Logger(),
+		stats:         mgr.Metrics(),
+		tracer:        mgr.Tracer(),
 		transactions:  make(chan message.Transaction),
 		shutSig:       shutdown.NewSignaller(),
 	}
@@ -169,7 +172,7 @@ func (r *AsyncReader) loop() {
 		startedAt := time.Now()
 
 		resChan := make(chan error)
-		tracing.InitSpans("input_"+r.typeStr, msg)
+		tracing.InitSpans(r.tracer, "input_"+r.typeStr, msg)
 		select {
 		case r.transactions <- message.NewTransaction(msg, resChan):
 		case <-r.shutSig.CloseAtLeisureChan():
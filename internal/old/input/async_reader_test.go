@@ -15,8 +15,6 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/benthosdev/benthos/v4/internal/component"
-	"github.com/benthosdev/benthos/v4/internal/component/metrics"
-	"github.com/benthosdev/benthos/v4/internal/log"
 	"github.com/benthosdev/benthos/v4/internal/manager/mock"
 	"github.com/benthosdev/benthos/v4/internal/message"
 	"github.com/benthosdev/benthos/v4/internal/old/input/reader"
@@ -117,7 +115,7 @@ func (r asyncReaderCantConnect) WaitForClose(time.Duration) error {
 func TestAsyncReaderCantConnect(t *testing.T) {
 	r, err := NewAsyncReader(
 		"foo", true, asyncReaderCantConnect{},
-		log.Noop(), metrics.Noop(),
+		mock.NewManager(),
 	)
 	if err != nil {
 		t.Error(err)
@@ -154,7 +152,7 @@ func TestAsyncReaderCantRead(t *testing.T) {
 
 	r, err := NewAsyncReader(
 		"foo", true, readerImpl,
-		log.Noop(), metrics.Noop(),
+		mock.NewManager(),
 	)
 	if err != nil {
 		t.Error(err)
@@ -179,7 +177,7 @@ func TestAsyncReaderTypeClosedOnConn(t *testing.T) {
 
 	r, err := NewAsyncReader(
 		"foo", true, readerImpl,
-		log.Noop(), metrics.Noop(),
+		mock.NewManager(),
 	)
 	if err != nil {
 		t.Error(err)
@@ -203,7 +201,7 @@ func TestAsyncReaderTypeClosedOnReconn(t *testing.T) {
 
 	r, err := NewAsyncReader(
 		"foo", true, readerImpl,
-		log.Noop(), metrics.Noop(),
+		mock.NewManager(),
 	)
 	if err != nil {
 		t.Error(err)
@@ -235,7 +233,7 @@ func TestAsyncReaderTypeClosedOnReread(t *testing.T) {
 
 	r, err := NewAsyncReader(
 		"foo", true, readerImpl,
-		log.Noop(), metrics.Noop(),
+		mock.NewManager(),
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -275,7 +273,7 @@ func TestAsyncReaderCanReconnect(t *testing.T) {
 
 	r, err := NewAsyncReader(
 		"foo", true, readerImpl,
-		log.Noop(), metrics.Noop(),
+		mock.NewManager(),
 	)
 	if err != nil {
 		t.Error(err)
@@ -341,7 +339,7 @@ func TestAsyncReaderFailsReconnect(t *testing.T) {
 
 	r, err := NewAsyncReader(
 		"foo", true, readerImpl,
-		log.Noop(), metrics.Noop(),
+		mock.NewManager(),
 	)
 	if err != nil {
 		t.Error(err)
@@ -407,7 +405,7 @@ func TestAsyncReaderCloseDuringReconnect(t *testing.T) {
 
 	r, err := NewAsyncReader(
 		"foo", true, readerImpl,
-		log.Noop(), metrics.Noop(),
+		mock.NewManager(),
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -453,7 +451,7 @@ func TestAsyncReaderHappyPath(t *testing.T) {
 
 	r, err := NewAsyncReader(
 		"foo", true, readerImpl,
-		log.Noop(), metrics.Noop(),
+		mock.NewManager(),
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -516,7 +514,7 @@ func TestAsyncReaderCloseWithPendingAcks(t *testing.T) {
 	readerImpl := newMockAsyncReader()
 	readerImpl.msgsToSnd = []*message.Batch{message.QuickBatch(exp)}
 
-	r, err := NewAsyncReader("foo", true, readerImpl, log.Noop(), metrics.Noop())
+	r, err := NewAsyncReader("foo", true, readerImpl, mock.NewManager())
 	require.NoError(t, err)
 
 	select {
@@ -583,7 +581,7 @@ func TestAsyncReaderSadPath(t *testing.T) {
 
 	r, err := NewAsyncReader(
 		"foo", true, readerImpl,
-		log.Noop(), metrics.Noop(),
+		mock.NewManager(),
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -656,7 +654,7 @@ func TestAsyncReaderParallel(t *testing.T) {
 
 	r, err := NewAsyncReader(
 		"foo", true, readerImpl,
-		log.Noop(), metrics.Noop(),
+		mock.NewManager(),
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -758,7 +756,7 @@ func benchmarkAsyncReaderGenerateN(b *testing.B, capacity int) {
 	readerImpl, err := newBloblang(mock.NewManager(), bloblConf)
 	require.NoError(b, err)
 
-	r, err := NewAsyncReader("foo", true, readerImpl, log.Noop(), metrics.Noop())
+	r, err := NewAsyncReader("foo", true, readerImpl, mock.NewManager())
 	require.NoError(b, err)
 
 	b.Cleanup(func() {
@@ -64,7 +64,7 @@ func init() {
 			if err != nil {
 				return nil, err
 			}
-			return NewAsyncReader(TypeKinesis, false, reader.NewAsyncPreserver(rdr), log, stats)
+			return NewAsyncReader(TypeKinesis, false, reader.NewAsyncPreserver(rdr), mgr)
 		}),
 		Status:  docs.StatusStable,
 		Version: "3.36.0",
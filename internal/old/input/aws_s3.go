@@ -44,7 +44,7 @@ func init() {
 			if conf.AWSS3.SQS.URL == "" {
 				r = reader.NewAsyncPreserver(r)
 			}
-			return NewAsyncReader(TypeAWSS3, false, r, log, stats)
+			return NewAsyncReader(TypeAWSS3, false, r, mgr)
 		}),
 		Status: docs.StatusStable,
 		Summary: `
@@ -34,7 +34,7 @@ func init() {
 			if err != nil {
 				return nil, err
 			}
-			return NewAsyncReader(TypeAWSSQS, false, r, log, stats)
+			return NewAsyncReader(TypeAWSSQS, false, r, mgr)
 		}),
 		Summary: `
 Consume messages from an AWS SQS URL.`,
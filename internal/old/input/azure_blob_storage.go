@@ -37,7 +37,7 @@ func init() {
 				TypeAzureBlobStorage,
 				true,
 				reader.NewAsyncPreserver(r),
-				log, stats,
+				mgr,
 			)
 		}),
 		Status:  docs.StatusBeta,
@@ -26,7 +26,7 @@ func init() {
 			if err != nil {
 				return nil, err
 			}
-			return NewAsyncReader(TypeAzureQueueStorage, false, r, log, stats)
+			return NewAsyncReader(TypeAzureQueueStorage, false, r, mgr)
 		}),
 		Status:  docs.StatusBeta,
 		Version: "3.42.0",
@@ -139,7 +139,7 @@ func NewCSVFile(conf Config, mgr interop.Manager, log log.Modular, stats metrics
 		return nil, err
 	}
 
-	return NewAsyncReader(TypeFile, true, reader.NewAsyncPreserver(rdr), log, stats)
+	return NewAsyncReader(TypeFile, true, reader.NewAsyncPreserver(rdr), mgr)
 }
 
 //------------------------------------------------------------------------------
@@ -90,7 +90,7 @@ func NewFile(conf Config, mgr interop.Manager, log log.Modular, stats metrics.Ty
 	if err != nil {
 		return nil, err
 	}
-	return NewAsyncReader(TypeFile, true, reader.NewAsyncPreserver(rdr), log, stats)
+	return NewAsyncReader(TypeFile, true, reader.NewAsyncPreserver(rdr), mgr)
 }
 
 //------------------------------------------------------------------------------
@@ -54,7 +54,7 @@ func NewGCPPubSub(conf Config, mgr interop.Manager, log log.Modular, stats metri
 	if c, err = reader.NewGCPPubSub(conf.GCPPubSub, log, stats); err != nil {
 		return nil, err
 	}
-	return NewAsyncReader(TypeGCPPubSub, true, c, log, stats)
+	return NewAsyncReader(TypeGCPPubSub, true, c, mgr)
 }
 
 //------------------------------------------------------------------------------
@@ -30,7 +30,7 @@ func init() {
 			if err != nil {
 				return nil, err
 			}
-			return NewAsyncReader(TypeGenerate, false, reader.NewAsyncPreserver(b), log, stats)
+			return NewAsyncReader(TypeGenerate, false, reader.NewAsyncPreserver(b), mgr)
 		}),
 		Version: "3.40.0",
 		Status:  docs.StatusStable,
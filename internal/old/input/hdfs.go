@@ -55,7 +55,7 @@ func NewHDFS(conf Config, mgr interop.Manager, log log.Modular, stats metrics.Ty
 		reader.NewAsyncPreserver(
 			reader.NewHDFS(conf.HDFS, log, stats),
 		),
-		log, stats,
+		mgr,
 	)
 }
 
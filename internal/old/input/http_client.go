@@ -152,7 +152,7 @@ func NewHTTPClient(conf Config, mgr interop.Manager, log log.Modular, stats metr
 	if err != nil {
 		return nil, err
 	}
-	return NewAsyncReader(TypeHTTPClient, true, reader.NewAsyncPreserver(rdr), log, stats)
+	return NewAsyncReader(TypeHTTPClient, true, reader.NewAsyncPreserver(rdr), mgr)
 }
 
 func newHTTPClient(conf HTTPClientConfig, mgr interop.Manager, log log.Modular, stats metrics.Type) (*HTTPClient, error) {
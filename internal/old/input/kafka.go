@@ -185,7 +185,7 @@ func NewKafka(conf Config, mgr interop.Manager, log log.Modular, stats metrics.T
 			return nil, err
 		}
 	}
-	return NewAsyncReader(TypeKafka, false, reader.NewAsyncPreserver(rdr), log, stats)
+	return NewAsyncReader(TypeKafka, false, reader.NewAsyncPreserver(rdr), mgr)
 }
 
 //------------------------------------------------------------------------------
@@ -67,7 +67,7 @@ func NewMQTT(conf Config, mgr interop.Manager, log log.Modular, stats metrics.Ty
 		TypeMQTT,
 		true,
 		reader.NewAsyncPreserver(m),
-		log, stats,
+		mgr,
 	)
 }
 
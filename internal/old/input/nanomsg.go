@@ -39,7 +39,7 @@ func NewNanomsg(conf Config, mgr interop.Manager, log log.Modular, stats metrics
 	if err != nil {
 		return nil, err
 	}
-	return NewAsyncReader(TypeNanomsg, true, reader.NewAsyncPreserver(s), log, stats)
+	return NewAsyncReader(TypeNanomsg, true, reader.NewAsyncPreserver(s), mgr)
 }
 
 //------------------------------------------------------------------------------
@@ -59,7 +59,7 @@ func NewNATS(conf Config, mgr interop.Manager, log log.Modular, stats metrics.Ty
 	if err != nil {
 		return nil, err
 	}
-	return NewAsyncReader(TypeNATS, true, reader.NewAsyncPreserver(n), log, stats)
+	return NewAsyncReader(TypeNATS, true, reader.NewAsyncPreserver(n), mgr)
 }
 
 //------------------------------------------------------------------------------
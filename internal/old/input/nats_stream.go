@@ -76,7 +76,7 @@ func NewNATSStream(conf Config, mgr interop.Manager, log log.Modular, stats metr
 	if c, err = reader.NewNATSStream(conf.NATSStream, log, stats); err != nil {
 		return nil, err
 	}
-	return NewAsyncReader(TypeNATSStream, true, c, log, stats)
+	return NewAsyncReader(TypeNATSStream, true, c, mgr)
 }
 
 //------------------------------------------------------------------------------
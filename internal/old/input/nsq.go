@@ -41,7 +41,7 @@ func NewNSQ(conf Config, mgr interop.Manager, log log.Modular, stats metrics.Typ
 	if n, err = reader.NewNSQ(conf.NSQ, log, stats); err != nil {
 		return nil, err
 	}
-	return NewAsyncReader(TypeNSQ, true, n, log, stats)
+	return NewAsyncReader(TypeNSQ, true, n, mgr)
 }
 
 //------------------------------------------------------------------------------
@@ -35,7 +35,7 @@ func NewRedisList(conf Config, mgr interop.Manager, log log.Modular, stats metri
 	if err != nil {
 		return nil, err
 	}
-	return NewAsyncReader(TypeRedisList, true, reader.NewAsyncPreserver(r), log, stats)
+	return NewAsyncReader(TypeRedisList, true, reader.NewAsyncPreserver(r), mgr)
 }
 
 //------------------------------------------------------------------------------
@@ -47,7 +47,7 @@ func NewRedisPubSub(conf Config, mgr interop.Manager, log log.Modular, stats met
 	if err != nil {
 		return nil, err
 	}
-	return NewAsyncReader(TypeRedisPubSub, true, reader.NewAsyncPreserver(r), log, stats)
+	return NewAsyncReader(TypeRedisPubSub, true, reader.NewAsyncPreserver(r), mgr)
 }
 
 //------------------------------------------------------------------------------
@@ -49,7 +49,7 @@ func NewRedisStreams(conf Config, mgr interop.Manager, log log.Modular, stats me
 		return nil, err
 	}
 	c = reader.NewAsyncPreserver(c)
-	return NewAsyncReader(TypeRedisStreams, true, c, log, stats)
+	return NewAsyncReader(TypeRedisStreams, true, c, mgr)
 }
 
 //------------------------------------------------------------------------------
@@ -55,7 +55,7 @@ func init() {
 				TypeSFTP,
 				true,
 				reader.NewAsyncPreserver(r),
-				log, stats,
+				mgr,
 			)
 		}),
 		Status:  docs.StatusExperimental,
@@ -73,7 +73,7 @@ func NewSocket(conf Config, mgr interop.Manager, log log.Modular, stats metrics.
 	// we can get the same results by making sure that the async readers forward
 	// CloseAsync all the way through. We would need it to be configurable as it
 	// wouldn't be appropriate for inputs that have real acks.
-	return NewAsyncReader(TypeSocket, true, reader.NewAsyncCutOff(reader.NewAsyncPreserver(rdr)), log, stats)
+	return NewAsyncReader(TypeSocket, true, reader.NewAsyncCutOff(reader.NewAsyncPreserver(rdr)), mgr)
 }
 
 //------------------------------------------------------------------------------
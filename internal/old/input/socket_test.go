@@ -929,7 +929,7 @@ func BenchmarkTCPSocketWithCutOff(b *testing.B) {
 	sRdr, err := newSocketClient(conf.Socket, log.Noop())
 	require.NoError(b, err)
 
-	rdr, err := NewAsyncReader(TypeSocket, true, reader.NewAsyncCutOff(reader.NewAsyncPreserver(sRdr)), log.Noop(), metrics.Noop())
+	rdr, err := NewAsyncReader(TypeSocket, true, reader.NewAsyncCutOff(reader.NewAsyncPreserver(sRdr)), mock.NewManager())
 	require.NoError(b, err)
 
 	defer func() {
@@ -999,7 +999,7 @@ func BenchmarkTCPSocketNoCutOff(b *testing.B) {
 	sRdr, err := newSocketClient(conf.Socket, log.Noop())
 	require.NoError(b, err)
 
-	rdr, err := NewAsyncReader(TypeSocket, true, reader.NewAsyncPreserver(sRdr), log.Noop(), metrics.Noop())
+	rdr, err := NewAsyncReader(TypeSocket, true, reader.NewAsyncPreserver(sRdr), mock.NewManager())
 	require.NoError(b, err)
 
 	defer func() {
@@ -67,7 +67,7 @@ func NewSTDIN(conf Config, mgr interop.Manager, log log.Modular, stats metrics.T
 	return NewAsyncReader(
 		TypeSTDIN, true,
 		reader.NewAsyncCutOff(reader.NewAsyncPreserver(rdr)),
-		log, stats,
+		mgr,
 	)
 }
 
@@ -6,10 +6,11 @@ import (
 
 	"github.com/benthosdev/benthos/v4/internal/component/metrics"
 	"github.com/benthosdev/benthos/v4/internal/log"
+	"github.com/benthosdev/benthos/v4/internal/manager/mock"
 )
 
 func TestSTDINClose(t *testing.T) {
-	s, err := NewSTDIN(NewConfig(), nil, log.Noop(), metrics.Noop())
+	s, err := NewSTDIN(NewConfig(), mock.NewManager(), log.Noop(), metrics.Noop())
 	if err != nil {
 		t.Error(err)
 		return
@@ -29,7 +29,7 @@ func init() {
 			if err != nil {
 				return nil, err
 			}
-			return NewAsyncReader(TypeSubprocess, true, b, log, stats)
+			return NewAsyncReader(TypeSubprocess, true, b, mgr)
 		}),
 		Status: docs.StatusBeta,
 		Summary: `
@@ -41,7 +41,7 @@ func NewWebsocket(conf Config, mgr interop.Manager, log log.Modular, stats metri
 	if err != nil {
 		return nil, err
 	}
-	return NewAsyncReader("websocket", true, reader.NewAsyncPreserver(ws), log, stats)
+	return NewAsyncReader("websocket", true, reader.NewAsyncPreserver(ws), mgr)
 }
 
 //------------------------------------------------------------------------------
@@ -91,7 +91,24 @@ output:
       processors:
         - archive:
             format: json_array
-` + "```" + ``,
+` + "```" + `
+
+### Streaming Objects
+
+By default each message is uploaded as an individual object. When a ` + "`codec`" + ` other than ` + "`all-bytes`" + ` is set messages are instead streamed into objects with that codec as multipart uploads, where an object is opened with the path and properties of its first message and is finalised once the path of a message changes, it reaches ` + "`max_file_size`" + ` bytes, or the output is closed. When ` + "`max_file_size`" + ` is set the path is only resolved when a new object is opened and should therefore resolve to a unique object each time, e.g. ` + "`${!count(\"files\")}-${!timestamp_unix_nano()}.parquet`" + `.
+
+This allows large files such as those written with the ` + "`parquet:x`" + ` codec to be uploaded without holding an entire file in memory, as rows are flushed to the upload one row group at a time:
+
+` + "```yaml" + `
+output:
+  aws_s3:
+    bucket: TODO
+    path: ${!count("files")}-${!timestamp_unix_nano()}.parquet
+    codec: parquet:file://./schema.json
+    max_file_size: 1073741824
+` + "```" + `
+
+Messages are acknowledged once they're written to the stream of an object, and therefore messages written to an object that then fails to finalise are lost.`,
 		Async: true,
 		FieldSpecs: docs.FieldSpecs{
 			docs.FieldCommon("bucket", "The bucket to upload messages to."),
@@ -121,6 +138,14 @@ output:
 			docs.FieldAdvanced("kms_key_id", "An optional server side encryption key."),
 			docs.FieldAdvanced("server_side_encryption", "An optional server side encryption algorithm.").AtVersion("3.63.0"),
 			docs.FieldAdvanced("force_path_style_urls", "Forces the client API to use path style URLs, which helps when connecting to custom endpoints."),
+			docs.FieldAdvanced(
+				"codec", "The codec used for writing messages to objects. With the default `all-bytes` each message is uploaded as an individual object, any other codec streams messages into objects as described in [streaming objects](#streaming-objects). The available codecs are the same as those of the [`file` output](/docs/components/outputs/file#codec).",
+				"lines", "gzip/lines", "parquet:file://./schema.json",
+			),
+			docs.FieldAdvanced(
+				"max_file_size", "An optional maximum size in bytes of each streamed object, once an object exceeds this size it is finalised and the path is resolved again for the next message. When set to zero objects are not rolled.",
+				1073741824,
+			).HasType(docs.FieldTypeInt),
 			docs.FieldCommon("max_in_flight", "The maximum number of messages to have in flight at a given time. Increase this to improve throughput."),
 			docs.FieldAdvanced("timeout", "The maximum period to wait on an upload before abandoning it and reattempting."),
 			policy.FieldSpec(),
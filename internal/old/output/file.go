@@ -29,6 +29,10 @@ Writes messages to files on disk based on a chosen codec.`,
 		Description: `
 Messages can be written to different files by using [interpolation functions](/docs/configuration/interpolation#bloblang-queries) in the path field. However, only one file is ever open at a given time, and therefore when the path changes the previously open file is closed.
 
+### Rolling Files
+
+When a ` + "`max_file_size`" + ` is specified the path is only resolved when a new file is opened, and all subsequent messages are written to that file until it reaches the maximum size, at which point it is closed and the path is resolved again for the next message. The path should therefore resolve to a unique file each time, e.g. ` + "`/tmp/${! timestamp_unix_nano() }.parquet`" + `. This is useful in combination with codecs such as ` + "`parquet:x`" + `, which write rows in groups and only finalise a file once it is closed.
+
 ` + multipartCodecDoc,
 		FieldSpecs: docs.FieldSpecs{
 			docs.FieldCommon(
@@ -38,6 +42,10 @@ Messages can be written to different files by using [interpolation functions](/d
 				`/tmp/${! json("document.id") }.json`,
 			).IsInterpolated().AtVersion("3.33.0"),
 			codec.WriterDocs.AtVersion("3.33.0"),
+			docs.FieldAdvanced(
+				"max_file_size", "An optional maximum size in bytes of each file, once a file exceeds this size it is closed and the path is resolved again for the next message. When set to zero files are not rolled.",
+				67108864,
+			).HasType(docs.FieldTypeInt),
 		},
 		Categories: []Category{
 			CategoryLocal,
@@ -49,15 +57,17 @@ Messages can be written to different files by using [interpolation functions](/d
 
 // FileConfig contains configuration fields for the file based output type.
 type FileConfig struct {
-	Path  string `json:"path" yaml:"path"`
-	Codec string `json:"codec" yaml:"codec"`
+	Path        string `json:"path" yaml:"path"`
+	Codec       string `json:"codec" yaml:"codec"`
+	MaxFileSize int64  `json:"max_file_size" yaml:"max_file_size"`
 }
 
 // NewFileConfig creates a new FileConfig with default values.
 func NewFileConfig() FileConfig {
 	return FileConfig{
-		Path:  "",
-		Codec: "lines",
+		Path:        "",
+		Codec:       "lines",
+		MaxFileSize: 0,
 	}
 }
 
@@ -65,10 +75,14 @@ func NewFileConfig() FileConfig {
 
 // NewFile creates a new File output type.
 func NewFile(conf Config, mgr interop.Manager, log log.Modular, stats metrics.Type) (output.Streamed, error) {
+	if conf.File.MaxFileSize < 0 {
+		return nil, fmt.Errorf("max_file_size must not be negative, got %v", conf.File.MaxFileSize)
+	}
 	f, err := newFileWriter(conf.File.Path, conf.File.Codec, mgr, log, stats)
 	if err != nil {
 		return nil, err
 	}
+	f.maxFileSize = conf.File.MaxFileSize
 	w, err := NewAsyncWriter(TypeFile, 1, f, log, stats)
 	if err != nil {
 		return nil, err
@@ -89,9 +103,13 @@ type fileWriter struct {
 	codec     codec.WriterConstructor
 	codecConf codec.WriterConfig
 
-	handleMut  sync.Mutex
-	handlePath string
-	handle     codec.Writer
+	// When non-zero files are closed once they exceed this size.
+	maxFileSize int64
+
+	handleMut     sync.Mutex
+	handlePath    string
+	handle        codec.Writer
+	handleCounter *countingFile
 
 	shutSig *shutdown.Signaller
 }
@@ -123,11 +141,19 @@ func (w *fileWriter) ConnectWithContext(ctx context.Context) error {
 
 func (w *fileWriter) WriteWithContext(ctx context.Context, msg *message.Batch) error {
 	err := writer.IterateBatchedSend(msg, func(i int, p *message.Part) error {
-		path := filepath.Clean(w.path.String(i, msg))
-
 		w.handleMut.Lock()
 		defer w.handleMut.Unlock()
 
+		if w.handle != nil && w.maxFileSize > 0 {
+			// When rolling files the path is only resolved when a new file is
+			// opened.
+			if err := w.handle.Write(ctx, p); err != nil {
+				return err
+			}
+			return w.rollIfFull(ctx)
+		}
+
+		path := filepath.Clean(w.path.String(i, msg))
 		if w.handle != nil && path == w.handlePath {
 			return w.handle.Write(ctx, p)
 		}
@@ -135,6 +161,7 @@ func (w *fileWriter) WriteWithContext(ctx context.Context, msg *message.Batch) e
 			if err := w.handle.Close(ctx); err != nil {
 				return err
 			}
+			w.handle = nil
 		}
 
 		flag := os.O_CREATE | os.O_RDWR
@@ -155,8 +182,25 @@ func (w *fileWriter) WriteWithContext(ctx context.Context, msg *message.Batch) e
 		}
 
 		w.handlePath = path
-		handle, err := w.codec(file)
+		w.handleCounter = &countingFile{File: file}
+		if w.codecConf.Append && !w.codecConf.Truncate {
+			// Appended files already count towards the maximum size.
+			info, err := file.Stat()
+			if err != nil {
+				file.Close()
+				return err
+			}
+			w.handleCounter.written = info.Size()
+		}
+
+		var handle codec.Writer
+		if w.maxFileSize > 0 {
+			handle, err = w.codec(w.handleCounter)
+		} else {
+			handle, err = w.codec(file)
+		}
 		if err != nil {
+			file.Close()
 			return err
 		}
 
@@ -167,25 +211,51 @@ func (w *fileWriter) WriteWithContext(ctx context.Context, msg *message.Batch) e
 
 		if !w.codecConf.CloseAfter {
 			w.handle = handle
-		} else {
-			handle.Close(ctx)
+			return w.rollIfFull(ctx)
 		}
-		return nil
+		return handle.Close(ctx)
 	})
 	if err != nil {
 		return err
 	}
 
-	if msg.Len() > 1 {
+	if msg.Len() > 1 || w.codecConf.EndEveryBatch {
 		w.handleMut.Lock()
+		defer w.handleMut.Unlock()
 		if w.handle != nil {
-			w.handle.EndBatch()
+			if err := w.handle.EndBatch(); err != nil {
+				return err
+			}
+			return w.rollIfFull(ctx)
 		}
-		w.handleMut.Unlock()
 	}
 	return nil
 }
 
+// rollIfFull closes the currently open file when it has exceeded the maximum
+// file size. Must be called whilst holding the handle mutex.
+func (w *fileWriter) rollIfFull(ctx context.Context) error {
+	if w.maxFileSize <= 0 || w.handle == nil || w.handleCounter.written < w.maxFileSize {
+		return nil
+	}
+	err := w.handle.Close(ctx)
+	w.handle = nil
+	return err
+}
+
+// countingFile tracks the number of bytes written to a file, which is used in
+// order to determine when to roll files.
+type countingFile struct {
+	*os.File
+	written int64
+}
+
+func (c *countingFile) Write(p []byte) (int, error) {
+	n, err := c.File.Write(p)
+	c.written += int64(n)
+	return n, err
+}
+
 // CloseAsync shuts down the File output and stops processing messages.
 func (w *fileWriter) CloseAsync() {
 	go func() {
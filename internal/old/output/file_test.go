@@ -0,0 +1,189 @@
+package output_test
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/component/metrics"
+	"github.com/benthosdev/benthos/v4/internal/log"
+	"github.com/benthosdev/benthos/v4/internal/manager/mock"
+	"github.com/benthosdev/benthos/v4/internal/message"
+	"github.com/benthosdev/benthos/v4/internal/old/output"
+)
+
+func sendFileMsg(t *testing.T, msg string, tChan chan message.Transaction) {
+	t.Helper()
+
+	resChan := make(chan error)
+	select {
+	case tChan <- message.NewTransaction(message.QuickBatch([][]byte{[]byte(msg)}), resChan):
+	case <-time.After(time.Second):
+		t.Fatal("timed out")
+	}
+
+	select {
+	case res := <-resChan:
+		require.NoError(t, res)
+	case <-time.After(time.Second):
+		t.Fatal("timed out")
+	}
+}
+
+func TestFileSinglePath(t *testing.T) {
+	dir := t.TempDir()
+
+	conf := output.NewConfig()
+	conf.Type = output.TypeFile
+	conf.File.Path = filepath.Join(dir, "out.txt")
+
+	o, err := output.New(conf, mock.NewManager(), log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	tranChan := make(chan message.Transaction)
+	require.NoError(t, o.Consume(tranChan))
+
+	sendFileMsg(t, "foo", tranChan)
+	sendFileMsg(t, "bar", tranChan)
+
+	o.CloseAsync()
+	require.NoError(t, o.WaitForClose(time.Second))
+
+	resBytes, err := os.ReadFile(conf.File.Path)
+	require.NoError(t, err)
+	assert.Equal(t, "foo\nbar\n", string(resBytes))
+}
+
+func TestFileRolling(t *testing.T) {
+	dir := t.TempDir()
+
+	conf := output.NewConfig()
+	conf.Type = output.TypeFile
+	conf.File.Path = filepath.Join(dir, `${! count("rolling_files") }.txt`)
+	conf.File.MaxFileSize = 8
+
+	o, err := output.New(conf, mock.NewManager(), log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	tranChan := make(chan message.Transaction)
+	require.NoError(t, o.Consume(tranChan))
+
+	for _, msg := range []string{"foo", "bar", "baz", "buz", "qux"} {
+		sendFileMsg(t, msg, tranChan)
+	}
+
+	o.CloseAsync()
+	require.NoError(t, o.WaitForClose(time.Second))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	var names []string
+	contents := map[string]string{}
+	for _, e := range entries {
+		b, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		require.NoError(t, err)
+		names = append(names, e.Name())
+		contents[e.Name()] = string(b)
+	}
+	sort.Strings(names)
+
+	assert.Equal(t, []string{"1.txt", "2.txt", "3.txt"}, names)
+	assert.Equal(t, map[string]string{
+		"1.txt": "foo\nbar\n",
+		"2.txt": "baz\nbuz\n",
+		"3.txt": "qux\n",
+	}, contents)
+}
+
+func TestFileRollingAppendedFile(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "1.txt"), []byte("abcd\n"), 0o644))
+
+	conf := output.NewConfig()
+	conf.Type = output.TypeFile
+	conf.File.Path = filepath.Join(dir, `${! count("rolling_appended_files") }.txt`)
+	conf.File.MaxFileSize = 8
+
+	o, err := output.New(conf, mock.NewManager(), log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	tranChan := make(chan message.Transaction)
+	require.NoError(t, o.Consume(tranChan))
+
+	for _, msg := range []string{"foo", "bar", "baz"} {
+		sendFileMsg(t, msg, tranChan)
+	}
+
+	o.CloseAsync()
+	require.NoError(t, o.WaitForClose(time.Second))
+
+	// The existing contents of an appended file count towards its size.
+	for name, exp := range map[string]string{
+		"1.txt": "abcd\nfoo\n",
+		"2.txt": "bar\nbaz\n",
+	} {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		assert.Equal(t, exp, string(b), name)
+	}
+}
+
+func TestFileAvroOCFFlushedPerBatch(t *testing.T) {
+	dir := t.TempDir()
+
+	conf := output.NewConfig()
+	conf.Type = output.TypeFile
+	conf.File.Path = filepath.Join(dir, "data.avro")
+	conf.File.Codec = `avro-ocf:{"type":"record","name":"doc","fields":[{"name":"id","type":"int"}]}`
+
+	o, err := output.New(conf, mock.NewManager(), log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	tranChan := make(chan message.Transaction)
+	require.NoError(t, o.Consume(tranChan))
+
+	for _, msg := range []string{`{"id":1}`, `{"id":2}`} {
+		sendFileMsg(t, msg, tranChan)
+	}
+
+	// Records of single message batches are written before the file is
+	// closed.
+	f, err := os.Open(conf.File.Path)
+	require.NoError(t, err)
+
+	ocfR, err := goavro.NewOCFReader(f)
+	require.NoError(t, err)
+
+	var records []interface{}
+	for ocfR.Scan() {
+		r, err := ocfR.Read()
+		require.NoError(t, err)
+		records = append(records, r)
+	}
+	require.NoError(t, ocfR.Err())
+	require.NoError(t, f.Close())
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"id": int32(1)},
+		map[string]interface{}{"id": int32(2)},
+	}, records)
+
+	o.CloseAsync()
+	require.NoError(t, o.WaitForClose(time.Second))
+}
+
+func TestFileRollingNegativeSize(t *testing.T) {
+	conf := output.NewConfig()
+	conf.Type = output.TypeFile
+	conf.File.Path = "/tmp/nope.txt"
+	conf.File.MaxFileSize = -1
+
+	_, err := output.New(conf, mock.NewManager(), log.Noop(), metrics.Noop())
+	require.Error(t, err)
+}
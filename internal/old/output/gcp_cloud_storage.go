@@ -28,6 +28,8 @@ type GCPCloudStorageConfig struct {
 	ContentType     string        `json:"content_type" yaml:"content_type"`
 	ContentEncoding string        `json:"content_encoding" yaml:"content_encoding"`
 	ChunkSize       int           `json:"chunk_size" yaml:"chunk_size"`
+	Codec           string        `json:"codec" yaml:"codec"`
+	MaxFileSize     int64         `json:"max_file_size" yaml:"max_file_size"`
 	MaxInFlight     int           `json:"max_in_flight" yaml:"max_in_flight"`
 	Batching        policy.Config `json:"batching" yaml:"batching"`
 	CollisionMode   string        `json:"collision_mode" yaml:"collision_mode"`
@@ -41,6 +43,8 @@ func NewGCPCloudStorageConfig() GCPCloudStorageConfig {
 		ContentType:     "application/octet-stream",
 		ContentEncoding: "",
 		ChunkSize:       googleapi.DefaultUploadChunkSize,
+		Codec:           "all-bytes",
+		MaxFileSize:     0,
 		MaxInFlight:     1,
 		Batching:        policy.NewConfig(),
 		CollisionMode:   GCPCloudStorageOverwriteCollisionMode,
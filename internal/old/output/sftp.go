@@ -158,7 +158,7 @@ func (s *sftpWriter) WriteWithContext(ctx context.Context, msg *message.Batch) e
 		return component.ErrNotConnected
 	}
 
-	return writer.IterateBatchedSend(msg, func(i int, p *message.Part) error {
+	err := writer.IterateBatchedSend(msg, func(i int, p *message.Part) error {
 		path := s.path.String(i, msg)
 
 		s.handleMut.Lock()
@@ -209,6 +209,18 @@ func (s *sftpWriter) WriteWithContext(ctx context.Context, msg *message.Batch) e
 		}
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	if s.codecConf.EndEveryBatch {
+		s.handleMut.Lock()
+		defer s.handleMut.Unlock()
+		if s.handle != nil {
+			return s.handle.EndBatch()
+		}
+	}
+	return nil
 }
 
 // CloseAsync begins cleaning up resources used by this reader asynchronously.
@@ -87,12 +87,13 @@ func NewSTDOUT(conf Config, mgr interop.Manager, log log.Modular, stats metrics.
 }
 
 type stdoutWriter struct {
-	handle  codec.Writer
-	shutSig *shutdown.Signaller
+	handle    codec.Writer
+	codecConf codec.WriterConfig
+	shutSig   *shutdown.Signaller
 }
 
 func newStdoutWriter(codecStr string, log log.Modular, stats metrics.Type) (*stdoutWriter, error) {
-	codec, _, err := codec.GetWriter(codecStr)
+	codec, codecConf, err := codec.GetWriter(codecStr)
 	if err != nil {
 		return nil, err
 	}
@@ -103,8 +104,9 @@ func newStdoutWriter(codecStr string, log log.Modular, stats metrics.Type) (*std
 	}
 
 	return &stdoutWriter{
-		handle:  handle,
-		shutSig: shutdown.NewSignaller(),
+		handle:    handle,
+		codecConf: codecConf,
+		shutSig:   shutdown.NewSignaller(),
 	}, nil
 }
 
@@ -119,9 +121,9 @@ func (w *stdoutWriter) WriteWithContext(ctx context.Context, msg *message.Batch)
 	if err != nil {
 		return err
 	}
-	if msg.Len() > 1 {
+	if msg.Len() > 1 || w.codecConf.EndEveryBatch {
 		if w.handle != nil {
-			w.handle.EndBatch()
+			return w.handle.EndBatch()
 		}
 	}
 	return nil
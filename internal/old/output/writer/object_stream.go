@@ -0,0 +1,223 @@
+package writer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/field"
+	"github.com/benthosdev/benthos/v4/internal/codec"
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+// ObjectUpload is a stream of an object being uploaded, where closing the
+// stream finalises the object and aborting it discards the object.
+type ObjectUpload interface {
+	io.WriteCloser
+	Abort()
+}
+
+// ObjectOpenFn opens an upload of an object at a path, where the properties of
+// the object are derived from the message at index i of a batch. When both the
+// upload and error are nil the message is dropped.
+type ObjectOpenFn func(ctx context.Context, path string, i int, msg *message.Batch) (ObjectUpload, error)
+
+var errObjectAborted = errors.New("object upload was aborted")
+
+// ObjectStreamer writes messages with a codec into objects that are uploaded as
+// a stream, which allows codecs such as parquet to flush data incrementally
+// rather than holding an entire object in memory. An object is finalised once
+// the path of a message changes, it reaches a maximum size, or the streamer is
+// closed.
+//
+// Messages are acknowledged once they're written to the stream of an object,
+// and therefore any messages already written to an object that then fails to
+// finalise are lost.
+type ObjectStreamer struct {
+	codec     codec.WriterConstructor
+	codecConf codec.WriterConfig
+	path      *field.Expression
+	open      ObjectOpenFn
+
+	// When non-zero objects are finalised once they exceed this size.
+	maxSize int64
+
+	mut        sync.Mutex
+	handlePath string
+	handle     codec.Writer
+	upload     *countingUpload
+}
+
+// NewObjectStreamer creates a new ObjectStreamer using a codec, where the path
+// of objects is resolved from an expression and objects are opened with the
+// provided function.
+func NewObjectStreamer(codecStr string, maxSize int64, path *field.Expression, open ObjectOpenFn) (*ObjectStreamer, error) {
+	if maxSize < 0 {
+		return nil, fmt.Errorf("max_file_size must not be negative, got %v", maxSize)
+	}
+	ctor, codecConf, err := codec.GetWriter(codecStr)
+	if err != nil {
+		return nil, err
+	}
+	return &ObjectStreamer{
+		codec:     ctor,
+		codecConf: codecConf,
+		path:      path,
+		open:      open,
+		maxSize:   maxSize,
+	}, nil
+}
+
+// WriteWithContext writes each message of a batch to the currently open
+// object, opening a new object when required.
+func (o *ObjectStreamer) WriteWithContext(ctx context.Context, msg *message.Batch) error {
+	err := IterateBatchedSend(msg, func(i int, p *message.Part) error {
+		o.mut.Lock()
+		defer o.mut.Unlock()
+
+		if o.handle != nil && o.maxSize > 0 {
+			// When rolling objects the path is only resolved when a new object
+			// is opened.
+			if err := o.writeHandle(ctx, p); err != nil {
+				return err
+			}
+			return o.rollIfFull(ctx)
+		}
+
+		path := o.path.String(i, msg)
+		if o.handle != nil && path == o.handlePath {
+			return o.writeHandle(ctx, p)
+		}
+		if err := o.closeHandle(ctx); err != nil {
+			return err
+		}
+
+		upload, err := o.open(ctx, path, i, msg)
+		if err != nil {
+			return err
+		}
+		if upload == nil {
+			return nil
+		}
+
+		o.upload = &countingUpload{ObjectUpload: upload}
+		if o.handle, err = o.codec(o.upload); err != nil {
+			o.upload.Abort()
+			o.upload = nil
+			return err
+		}
+		o.handlePath = path
+
+		if err := o.writeHandle(ctx, p); err != nil {
+			return err
+		}
+		if o.codecConf.CloseAfter {
+			return o.closeHandle(ctx)
+		}
+		return o.rollIfFull(ctx)
+	})
+	if err != nil {
+		return err
+	}
+
+	if msg.Len() > 1 || o.codecConf.EndEveryBatch {
+		o.mut.Lock()
+		defer o.mut.Unlock()
+		if o.handle != nil {
+			if err := o.handle.EndBatch(); err != nil {
+				o.abortOnUploadErr(ctx)
+				return err
+			}
+			return o.rollIfFull(ctx)
+		}
+	}
+	return nil
+}
+
+// writeHandle writes a message to the current object. Must be called whilst
+// holding the mutex.
+func (o *ObjectStreamer) writeHandle(ctx context.Context, p *message.Part) error {
+	if err := o.handle.Write(ctx, p); err != nil {
+		o.abortOnUploadErr(ctx)
+		return err
+	}
+	return nil
+}
+
+// abortOnUploadErr discards the current object when its upload has failed, as
+// an upload is unable to recover from errors. Errors from a codec, such as a
+// message that doesn't match a schema, leave the object open. Must be called
+// whilst holding the mutex.
+func (o *ObjectStreamer) abortOnUploadErr(ctx context.Context) {
+	if o.upload == nil || o.upload.err == nil {
+		return
+	}
+	o.upload.Abort()
+	_ = o.handle.Close(ctx)
+	o.handle, o.upload = nil, nil
+}
+
+// rollIfFull finalises the current object when it has exceeded the maximum
+// size. Must be called whilst holding the mutex.
+func (o *ObjectStreamer) rollIfFull(ctx context.Context) error {
+	if o.maxSize <= 0 || o.handle == nil || o.upload.written < o.maxSize {
+		return nil
+	}
+	return o.closeHandle(ctx)
+}
+
+// closeHandle finalises the current object. Must be called whilst holding the
+// mutex.
+func (o *ObjectStreamer) closeHandle(ctx context.Context) error {
+	if o.handle == nil {
+		return nil
+	}
+	err := o.handle.Close(ctx)
+	o.handle, o.upload = nil, nil
+	return err
+}
+
+// Close finalises the currently open object.
+func (o *ObjectStreamer) Close(ctx context.Context) error {
+	o.mut.Lock()
+	defer o.mut.Unlock()
+	return o.closeHandle(ctx)
+}
+
+// countingUpload tracks the number of bytes written to an upload, which is
+// used in order to determine when to roll objects, and ensures that an aborted
+// upload isn't finalised when the codec wrapping it is closed.
+type countingUpload struct {
+	ObjectUpload
+	written int64
+	err     error
+	aborted bool
+}
+
+func (c *countingUpload) Write(p []byte) (int, error) {
+	if c.aborted {
+		return 0, errObjectAborted
+	}
+	n, err := c.ObjectUpload.Write(p)
+	c.written += int64(n)
+	if err != nil {
+		c.err = err
+	}
+	return n, err
+}
+
+func (c *countingUpload) Close() error {
+	if c.aborted {
+		return errObjectAborted
+	}
+	return c.ObjectUpload.Close()
+}
+
+func (c *countingUpload) Abort() {
+	if !c.aborted {
+		c.aborted = true
+		c.ObjectUpload.Abort()
+	}
+}
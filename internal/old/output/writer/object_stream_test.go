@@ -0,0 +1,190 @@
+package writer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang"
+	"github.com/benthosdev/benthos/v4/internal/codec"
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+type memUpload struct {
+	path     string
+	buf      bytes.Buffer
+	writeErr error
+	closed   bool
+	aborted  bool
+}
+
+func (m *memUpload) Write(p []byte) (int, error) {
+	if m.writeErr != nil {
+		return 0, m.writeErr
+	}
+	return m.buf.Write(p)
+}
+
+func (m *memUpload) Close() error {
+	m.closed = true
+	return nil
+}
+
+func (m *memUpload) Abort() {
+	m.aborted = true
+}
+
+type memObjects struct {
+	uploads []*memUpload
+}
+
+func (m *memObjects) open(ctx context.Context, path string, i int, msg *message.Batch) (ObjectUpload, error) {
+	if path == "drop" {
+		return nil, nil
+	}
+	u := &memUpload{path: path}
+	m.uploads = append(m.uploads, u)
+	return u, nil
+}
+
+func (m *memObjects) contents() map[string]string {
+	res := map[string]string{}
+	for _, u := range m.uploads {
+		if u.closed {
+			res[u.path] = u.buf.String()
+		}
+	}
+	return res
+}
+
+func newTestStreamer(t *testing.T, codecStr string, maxSize int64, path string) (*ObjectStreamer, *memObjects) {
+	t.Helper()
+
+	pathExpr, err := bloblang.GlobalEnvironment().NewField(path)
+	require.NoError(t, err)
+
+	objects := &memObjects{}
+	o, err := NewObjectStreamer(codecStr, maxSize, pathExpr, objects.open)
+	require.NoError(t, err)
+	return o, objects
+}
+
+func TestObjectStreamerPathChanges(t *testing.T) {
+	o, objects := newTestStreamer(t, "lines", 0, `${! meta("path") }`)
+
+	for _, m := range [][2]string{
+		{"a", "foo"}, {"a", "bar"}, {"drop", "nope"}, {"b", "baz"},
+	} {
+		msg := message.QuickBatch([][]byte{[]byte(m[1])})
+		msg.Get(0).MetaSet("path", m[0])
+		require.NoError(t, o.WriteWithContext(context.Background(), msg))
+	}
+
+	assert.Equal(t, map[string]string{
+		"a": "foo\nbar\n",
+	}, objects.contents())
+
+	require.NoError(t, o.Close(context.Background()))
+	assert.Equal(t, map[string]string{
+		"a": "foo\nbar\n",
+		"b": "baz\n",
+	}, objects.contents())
+}
+
+func TestObjectStreamerRolling(t *testing.T) {
+	o, objects := newTestStreamer(t, "lines", 8, `${! count("object_streamer_rolling") }.txt`)
+
+	for _, m := range []string{"foo", "bar", "baz"} {
+		require.NoError(t, o.WriteWithContext(context.Background(), message.QuickBatch([][]byte{[]byte(m)})))
+	}
+
+	assert.Equal(t, map[string]string{
+		"1.txt": "foo\nbar\n",
+	}, objects.contents())
+
+	require.NoError(t, o.Close(context.Background()))
+	assert.Equal(t, map[string]string{
+		"1.txt": "foo\nbar\n",
+		"2.txt": "baz\n",
+	}, objects.contents())
+}
+
+func TestObjectStreamerUploadFailure(t *testing.T) {
+	o, objects := newTestStreamer(t, "lines", 0, "foo.txt")
+
+	require.NoError(t, o.WriteWithContext(context.Background(), message.QuickBatch([][]byte{[]byte("foo")})))
+	require.Len(t, objects.uploads, 1)
+
+	objects.uploads[0].writeErr = errors.New("upload failed")
+	require.Error(t, o.WriteWithContext(context.Background(), message.QuickBatch([][]byte{[]byte("bar")})))
+
+	// The failed upload is discarded and the next message opens a new object.
+	assert.True(t, objects.uploads[0].aborted)
+	assert.False(t, objects.uploads[0].closed)
+
+	require.NoError(t, o.WriteWithContext(context.Background(), message.QuickBatch([][]byte{[]byte("bar")})))
+	require.NoError(t, o.Close(context.Background()))
+
+	require.Len(t, objects.uploads, 2)
+	assert.Equal(t, map[string]string{
+		"foo.txt": "bar\n",
+	}, objects.contents())
+}
+
+func TestObjectStreamerCodecErrors(t *testing.T) {
+	_, err := NewObjectStreamer("nope", 0, nil, nil)
+	require.Error(t, err)
+
+	_, err = NewObjectStreamer("lines", -1, nil, nil)
+	require.Error(t, err)
+
+	o, objects := newTestStreamer(t, `avro-ocf:{"type":"record","name":"doc","fields":[{"name":"id","type":"int"}]}`, 0, "foo.avro")
+
+	// A message that doesn't match the schema leaves the object open.
+	require.Error(t, o.WriteWithContext(context.Background(), message.QuickBatch([][]byte{[]byte(`{"id":"nope"}`)})))
+	require.NoError(t, o.WriteWithContext(context.Background(), message.QuickBatch([][]byte{[]byte(`{"id":1}`)})))
+	require.NoError(t, o.Close(context.Background()))
+
+	require.Len(t, objects.uploads, 1)
+	assert.True(t, objects.uploads[0].closed)
+	assert.False(t, objects.uploads[0].aborted)
+}
+
+func TestObjectStreamerParquet(t *testing.T) {
+	schema := `{"Tag":"name=root, repetitiontype=REQUIRED","Fields":[{"Tag":"name=id, type=INT32, repetitiontype=REQUIRED"}]}`
+	o, objects := newTestStreamer(t, "parquet:"+schema, 0, "foo.parquet")
+
+	for _, m := range []string{`{"id":1}`, `{"id":2}`, `{"id":3}`} {
+		require.NoError(t, o.WriteWithContext(context.Background(), message.QuickBatch([][]byte{[]byte(m)})))
+	}
+	require.NoError(t, o.Close(context.Background()))
+	require.Len(t, objects.uploads, 1)
+
+	ctor, err := codec.GetReader("parquet", codec.NewReaderConfig())
+	require.NoError(t, err)
+
+	r, err := ctor("foo.parquet", io.NopCloser(&objects.uploads[0].buf), func(ctx context.Context, err error) error {
+		return nil
+	})
+	require.NoError(t, err)
+
+	var rows []string
+	for {
+		parts, ackFn, err := r.Next(context.Background())
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		for _, p := range parts {
+			rows = append(rows, string(p.Get()))
+		}
+		require.NoError(t, ackFn(context.Background(), nil))
+	}
+	require.NoError(t, r.Close(context.Background()))
+	assert.Equal(t, []string{`{"id":1}`, `{"id":2}`, `{"id":3}`}, rows)
+}
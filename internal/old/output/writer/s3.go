@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"net/url"
 	"sort"
 	"strings"
@@ -22,6 +23,7 @@ import (
 	"github.com/benthosdev/benthos/v4/internal/log"
 	"github.com/benthosdev/benthos/v4/internal/message"
 	"github.com/benthosdev/benthos/v4/internal/metadata"
+	"github.com/benthosdev/benthos/v4/internal/shutdown"
 )
 
 //------------------------------------------------------------------------------
@@ -44,6 +46,8 @@ type AmazonS3Config struct {
 	Timeout                 string                       `json:"timeout" yaml:"timeout"`
 	KMSKeyID                string                       `json:"kms_key_id" yaml:"kms_key_id"`
 	ServerSideEncryption    string                       `json:"server_side_encryption" yaml:"server_side_encryption"`
+	Codec                   string                       `json:"codec" yaml:"codec"`
+	MaxFileSize             int64                        `json:"max_file_size" yaml:"max_file_size"`
 	MaxInFlight             int                          `json:"max_in_flight" yaml:"max_in_flight"`
 	Batching                policy.Config                `json:"batching" yaml:"batching"`
 }
@@ -67,6 +71,8 @@ func NewAmazonS3Config() AmazonS3Config {
 		Timeout:                 "5s",
 		KMSKeyID:                "",
 		ServerSideEncryption:    "",
+		Codec:                   "all-bytes",
+		MaxFileSize:             0,
 		MaxInFlight:             1,
 		Batching:                policy.NewConfig(),
 	}
@@ -99,8 +105,13 @@ type AmazonS3 struct {
 	uploader *s3manager.Uploader
 	timeout  time.Duration
 
-	log   log.Modular
-	stats metrics.Type
+	// When a codec other than all-bytes is configured messages are streamed
+	// into objects rather than uploaded individually.
+	streamer *ObjectStreamer
+
+	log     log.Modular
+	stats   metrics.Type
+	shutSig *shutdown.Signaller
 }
 
 // NewAmazonS3V2 creates a new Amazon S3 bucket writer.Type.
@@ -122,6 +133,7 @@ func NewAmazonS3V2(
 		log:     log,
 		stats:   stats,
 		timeout: timeout,
+		shutSig: shutdown.NewSignaller(),
 	}
 	var err error
 	if a.path, err = mgr.BloblEnvironment().NewField(conf.Path); err != nil {
@@ -168,6 +180,11 @@ func NewAmazonS3V2(
 		return a.tags[i].key < a.tags[j].key
 	})
 
+	if conf.Codec != "all-bytes" {
+		if a.streamer, err = NewObjectStreamer(conf.Codec, conf.MaxFileSize, a.path, a.openUpload); err != nil {
+			return nil, err
+		}
+	}
 	return a, nil
 }
 
@@ -214,83 +231,158 @@ func (a *AmazonS3) WriteWithContext(wctx context.Context, msg *message.Batch) er
 	)
 	defer cancel()
 
+	if a.streamer != nil {
+		return a.streamer.WriteWithContext(ctx, msg)
+	}
+
 	return IterateBatchedSend(msg, func(i int, p *message.Part) error {
-		metadata := map[string]*string{}
-		a.metaFilter.Iter(p, func(k, v string) error {
-			metadata[k] = aws.String(v)
-			return nil
-		})
+		uploadInput := a.uploadInput(a.path.String(i, msg), i, msg)
+		uploadInput.Body = bytes.NewReader(p.Get())
 
-		var contentEncoding *string
-		if ce := a.contentEncoding.String(i, msg); len(ce) > 0 {
-			contentEncoding = aws.String(ce)
-		}
-		var cacheControl *string
-		if ce := a.cacheControl.String(i, msg); len(ce) > 0 {
-			cacheControl = aws.String(ce)
-		}
-		var contentDisposition *string
-		if ce := a.contentDisposition.String(i, msg); len(ce) > 0 {
-			contentDisposition = aws.String(ce)
-		}
-		var contentLanguage *string
-		if ce := a.contentLanguage.String(i, msg); len(ce) > 0 {
-			contentLanguage = aws.String(ce)
-		}
-		var websiteRedirectLocation *string
-		if ce := a.websiteRedirectLocation.String(i, msg); len(ce) > 0 {
-			websiteRedirectLocation = aws.String(ce)
+		if _, err := a.uploader.UploadWithContext(ctx, uploadInput); err != nil {
+			return err
 		}
+		return nil
+	})
+}
 
-		uploadInput := &s3manager.UploadInput{
-			Bucket:                  &a.conf.Bucket,
-			Key:                     aws.String(a.path.String(i, msg)),
-			Body:                    bytes.NewReader(p.Get()),
-			ContentType:             aws.String(a.contentType.String(i, msg)),
-			ContentEncoding:         contentEncoding,
-			CacheControl:            cacheControl,
-			ContentDisposition:      contentDisposition,
-			ContentLanguage:         contentLanguage,
-			WebsiteRedirectLocation: websiteRedirectLocation,
-			StorageClass:            aws.String(a.storageClass.String(i, msg)),
-			Metadata:                metadata,
-		}
+// uploadInput creates the input of an upload to a key, where the properties of
+// the object are derived from the message at index i of a batch.
+func (a *AmazonS3) uploadInput(key string, i int, msg *message.Batch) *s3manager.UploadInput {
+	metadata := map[string]*string{}
+	a.metaFilter.Iter(msg.Get(i), func(k, v string) error {
+		metadata[k] = aws.String(v)
+		return nil
+	})
 
-		// Prepare tags, escaping keys and values to ensure they're valid query string parameters.
-		if len(a.tags) > 0 {
-			tags := make([]string, len(a.tags))
-			for j, pair := range a.tags {
-				tags[j] = url.QueryEscape(pair.key) + "=" + url.QueryEscape(pair.value.String(i, msg))
-			}
-			uploadInput.Tagging = aws.String(strings.Join(tags, "&"))
-		}
+	var contentEncoding *string
+	if ce := a.contentEncoding.String(i, msg); len(ce) > 0 {
+		contentEncoding = aws.String(ce)
+	}
+	var cacheControl *string
+	if ce := a.cacheControl.String(i, msg); len(ce) > 0 {
+		cacheControl = aws.String(ce)
+	}
+	var contentDisposition *string
+	if ce := a.contentDisposition.String(i, msg); len(ce) > 0 {
+		contentDisposition = aws.String(ce)
+	}
+	var contentLanguage *string
+	if ce := a.contentLanguage.String(i, msg); len(ce) > 0 {
+		contentLanguage = aws.String(ce)
+	}
+	var websiteRedirectLocation *string
+	if ce := a.websiteRedirectLocation.String(i, msg); len(ce) > 0 {
+		websiteRedirectLocation = aws.String(ce)
+	}
 
-		if a.conf.KMSKeyID != "" {
-			uploadInput.ServerSideEncryption = aws.String("aws:kms")
-			uploadInput.SSEKMSKeyId = &a.conf.KMSKeyID
-		}
+	uploadInput := &s3manager.UploadInput{
+		Bucket:                  &a.conf.Bucket,
+		Key:                     aws.String(key),
+		ContentType:             aws.String(a.contentType.String(i, msg)),
+		ContentEncoding:         contentEncoding,
+		CacheControl:            cacheControl,
+		ContentDisposition:      contentDisposition,
+		ContentLanguage:         contentLanguage,
+		WebsiteRedirectLocation: websiteRedirectLocation,
+		StorageClass:            aws.String(a.storageClass.String(i, msg)),
+		Metadata:                metadata,
+	}
 
-		// NOTE: This overrides the ServerSideEncryption set above. We need this to preserve
-		// backwards compatibility, where it is allowed to only set kms_key_id in the config and
-		// the ServerSideEncryption value of "aws:kms" is implied.
-		if a.conf.ServerSideEncryption != "" {
-			uploadInput.ServerSideEncryption = &a.conf.ServerSideEncryption
+	// Prepare tags, escaping keys and values to ensure they're valid query string parameters.
+	if len(a.tags) > 0 {
+		tags := make([]string, len(a.tags))
+		for j, pair := range a.tags {
+			tags[j] = url.QueryEscape(pair.key) + "=" + url.QueryEscape(pair.value.String(i, msg))
 		}
+		uploadInput.Tagging = aws.String(strings.Join(tags, "&"))
+	}
 
-		if _, err := a.uploader.UploadWithContext(ctx, uploadInput); err != nil {
-			return err
-		}
-		return nil
-	})
+	if a.conf.KMSKeyID != "" {
+		uploadInput.ServerSideEncryption = aws.String("aws:kms")
+		uploadInput.SSEKMSKeyId = &a.conf.KMSKeyID
+	}
+
+	// NOTE: This overrides the ServerSideEncryption set above. We need this to preserve
+	// backwards compatibility, where it is allowed to only set kms_key_id in the config and
+	// the ServerSideEncryption value of "aws:kms" is implied.
+	if a.conf.ServerSideEncryption != "" {
+		uploadInput.ServerSideEncryption = &a.conf.ServerSideEncryption
+	}
+	return uploadInput
+}
+
+// openUpload starts a streamed upload of an object, which the uploader sends
+// as a multipart upload once it exceeds the size of a single part.
+func (a *AmazonS3) openUpload(ctx context.Context, key string, i int, msg *message.Batch) (ObjectUpload, error) {
+	uploadInput := a.uploadInput(key, i, msg)
+
+	pr, pw := io.Pipe()
+	uploadInput.Body = pr
+
+	uploadCtx, cancel := context.WithCancel(context.Background())
+	u := &s3Upload{
+		pw:     pw,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	go func() {
+		_, err := a.uploader.UploadWithContext(uploadCtx, uploadInput)
+		u.err = err
+
+		// Unblocks any pending writes when the upload fails early.
+		_ = pr.CloseWithError(err)
+		close(u.done)
+	}()
+	return u, nil
+}
+
+// s3Upload streams the bytes written to it to an upload running in the
+// background.
+type s3Upload struct {
+	pw     *io.PipeWriter
+	cancel func()
+	done   chan struct{}
+	err    error
+}
+
+func (s *s3Upload) Write(p []byte) (int, error) {
+	return s.pw.Write(p)
+}
+
+func (s *s3Upload) Close() error {
+	_ = s.pw.Close()
+	<-s.done
+	s.cancel()
+	return s.err
+}
+
+func (s *s3Upload) Abort() {
+	s.cancel()
+	_ = s.pw.CloseWithError(errObjectAborted)
+	<-s.done
 }
 
 // CloseAsync begins cleaning up resources used by this reader asynchronously.
 func (a *AmazonS3) CloseAsync() {
+	go func() {
+		if a.streamer != nil {
+			if err := a.streamer.Close(context.Background()); err != nil {
+				a.log.Errorf("Failed to finalise object: %v\n", err)
+			}
+		}
+		a.shutSig.ShutdownComplete()
+	}()
 }
 
 // WaitForClose will block until either the reader is closed or a specified
 // timeout occurs.
-func (a *AmazonS3) WaitForClose(time.Duration) error {
+func (a *AmazonS3) WaitForClose(timeout time.Duration) error {
+	select {
+	case <-a.shutSig.HasClosedChan():
+	case <-time.After(timeout):
+		return component.ErrTimeout
+	}
 	return nil
 }
 
@@ -132,7 +132,7 @@ func (s *Socket) WriteWithContext(ctx context.Context, msg *message.Batch) error
 		}
 		return serr
 	})
-	if err == nil && msg.Len() > 1 {
+	if err == nil && (msg.Len() > 1 || s.codecConf.EndEveryBatch) {
 		if err = w.EndBatch(); err != nil {
 			s.writerMut.Lock()
 			s.writer.Close(ctx)
@@ -0,0 +1,14 @@
+// Package otlp contains configuration shared by components that push telemetry
+// to OpenTelemetry collectors over OTLP.
+package otlp
+
+import (
+	btls "github.com/benthosdev/benthos/v4/internal/tls"
+)
+
+// CollectorConfig is config for a single OpenTelemetry collector.
+type CollectorConfig struct {
+	URL     string            `json:"url" yaml:"url"`
+	Headers map[string]string `json:"headers" yaml:"headers"`
+	TLS     btls.Config       `json:"tls" yaml:"tls"`
+}
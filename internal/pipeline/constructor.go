@@ -33,6 +33,13 @@ func NewConfig() Config {
 
 // New creates an input type based on an input configuration.
 func New(conf Config, mgr interop.Manager) (Type, error) {
+	return NewWithWrapper(conf, mgr, nil)
+}
+
+// NewWithWrapper creates a pipeline where each processor is passed through a
+// wrapper function after construction along with its index, allowing callers
+// to observe the results of individual processors. A nil wrapper is ignored.
+func NewWithWrapper(conf Config, mgr interop.Manager, wrap func(int, iprocessor.V1) iprocessor.V1) (Type, error) {
 	processors := make([]iprocessor.V1, len(conf.Processors))
 	for j, procConf := range conf.Processors {
 		var err error
@@ -41,6 +48,9 @@ func New(conf Config, mgr interop.Manager) (Type, error) {
 		if err != nil {
 			return nil, err
 		}
+		if wrap != nil {
+			processors[j] = wrap(j, processors[j])
+		}
 	}
 	if conf.Threads == 1 {
 		return NewProcessor(processors...), nil
@@ -111,20 +111,23 @@ func NewHandler(conf config.Type) (*Handler, error) {
 
 	// Create our metrics type.
 	var stats *metrics.Namespaced
-	if stats, err = bundle.AllMetrics.Init(conf.Metrics, logger); err != nil {
+	if stats, err = bundle.GlobalEnvironment.MetricsInit(conf.Metrics, logger); err != nil {
 		logger.Errorf("Failed to connect metrics aggregator: %v\n", err)
 		stats = metrics.NewNamespaced(metrics.Noop())
 	}
 
 	// Create our tracer type.
-	trac, err := bundle.AllTracers.Init(conf.Tracer)
+	trac, err := bundle.GlobalEnvironment.TracersInit(conf.Tracer)
 	if err != nil {
 		logger.Errorf("Failed to initialise tracer: %v\n", err)
 		trac = tracer.Noop{}
 	}
 
 	// Create resource manager.
-	manager, err := manager.NewV2(conf.ResourceConfig, mock.NewManager(), logger, stats)
+	manager, err := manager.NewV2(
+		conf.ResourceConfig, mock.NewManager(), logger, stats,
+		manager.OptSetTracer(trac.TracerProvider()),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create resource: %v", err)
 	}
@@ -0,0 +1,69 @@
+// Package sqldriver contains a registry of the database/sql drivers supported
+// by the sql components, along with the properties of each driver that the
+// components need to be aware of.
+package sqldriver
+
+import (
+	"fmt"
+	"sync"
+)
+
+// PlaceholderFormat describes the syntax of argument placeholders expected by a
+// driver.
+type PlaceholderFormat int
+
+// Placeholder formats supported by the sql components.
+const (
+	// PlaceholderQuestion is of the form `?`.
+	PlaceholderQuestion PlaceholderFormat = iota
+	// PlaceholderDollar is of the form `$1`.
+	PlaceholderDollar
+	// PlaceholderColon is of the form `:1`.
+	PlaceholderColon
+	// PlaceholderAtP is of the form `@p1`.
+	PlaceholderAtP
+)
+
+// Spec describes how the sql components should interact with a driver.
+type Spec struct {
+	// Placeholder is the format of argument placeholders within queries.
+	Placeholder PlaceholderFormat
+
+	// UseTxStmt indicates that batched inserts must be executed with a
+	// prepared statement within a transaction.
+	UseTxStmt bool
+}
+
+var (
+	specsMut sync.RWMutex
+	specs    = map[string]Spec{
+		"clickhouse": {UseTxStmt: true},
+		"mssql":      {},
+		"mysql":      {},
+		"postgres":   {Placeholder: PlaceholderDollar},
+		"sqlite":     {},
+	}
+)
+
+// Register adds a driver to the registry, returning an error if a driver of the
+// same name already exists.
+func Register(name string, spec Spec) error {
+	specsMut.Lock()
+	defer specsMut.Unlock()
+
+	if _, exists := specs[name]; exists {
+		return fmt.Errorf("sql driver '%v' is already registered", name)
+	}
+	specs[name] = spec
+	return nil
+}
+
+// Get returns the spec of a driver. Drivers that haven't been registered are
+// given a default spec, as they might still be registered with database/sql
+// directly, in which case they're expected to use question mark placeholders.
+func Get(name string) Spec {
+	specsMut.RLock()
+	defer specsMut.RUnlock()
+
+	return specs[name]
+}
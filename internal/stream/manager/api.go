@@ -8,6 +8,7 @@ import (
 	"io"
 	"net/http"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -52,6 +53,21 @@ func (m *Type) registerEndpoints(enableCrud bool) {
 			" and DELETE (Delete).",
 		m.HandleStreamCRUD,
 	)
+	m.manager.RegisterEndpoint(
+		"/streams/{id}/pause",
+		"POST: Stop consuming data from the input of a stream without closing it.",
+		m.HandleStreamPause,
+	)
+	m.manager.RegisterEndpoint(
+		"/streams/{id}/resume",
+		"POST: Continue consuming data from the input of a paused stream.",
+		m.HandleStreamResume,
+	)
+	m.manager.RegisterEndpoint(
+		"/streams/{id}/tap",
+		"GET: Stream a sample of the messages passing through a point of a stream as server-sent events, or over a websocket.",
+		m.HandleStreamTap,
+	)
 	m.manager.RegisterEndpoint(
 		"/streams/{id}/stats",
 		"GET a structured JSON object containing metrics for the stream.",
@@ -113,17 +129,26 @@ func (m *Type) HandleStreamsCRUD(w http.ResponseWriter, r *http.Request) {
 		}
 	}()
 
+	if r.Method == "POST" {
+		m.apiLock.Lock()
+		defer m.apiLock.Unlock()
+	}
+
 	type confInfo struct {
 		Active    bool    `json:"active"`
+		Paused    bool    `json:"paused"`
 		Uptime    float64 `json:"uptime"`
 		UptimeStr string  `json:"uptime_str"`
 	}
 	infos := map[string]confInfo{}
+	prevConfs := map[string]stream.Config{}
 
 	m.lock.Lock()
 	for id, strInfo := range m.streams {
+		prevConfs[id] = strInfo.Config()
 		infos[id] = confInfo{
 			Active:    strInfo.IsRunning(),
+			Paused:    strInfo.IsPaused(),
 			Uptime:    strInfo.Uptime().Seconds(),
 			UptimeStr: strInfo.Uptime().String(),
 		}
@@ -202,6 +227,9 @@ func (m *Type) HandleStreamsCRUD(w http.ResponseWriter, r *http.Request) {
 		deadline = time.Now().Add(m.apiTimeout)
 	}
 
+	ctx, done := context.WithDeadline(r.Context(), deadline)
+	defer done()
+
 	wg := sync.WaitGroup{}
 	wg.Add(len(toDelete))
 	wg.Add(len(toUpdate))
@@ -212,25 +240,32 @@ func (m *Type) HandleStreamsCRUD(w http.ResponseWriter, r *http.Request) {
 	errCreate := make([]error, len(toCreate))
 
 	for i, id := range toDelete {
-		go func(sid string, j int) {
-			errDelete[j] = m.Delete(sid, time.Until(deadline))
+		prevConf := prevConfs[id]
+		go func(sid string, prev *stream.Config, j int) {
+			errDelete[j] = m.persistThen(ctx, sid, prev, nil, func() error {
+				return m.Delete(sid, time.Until(deadline))
+			})
 			wg.Done()
-		}(id, i)
+		}(id, &prevConf, i)
 	}
 	i := 0
 	for id, conf := range toUpdate {
-		newConf := conf
-		go func(sid string, sconf *stream.Config, j int) {
-			errUpdate[j] = m.Update(sid, *sconf, time.Until(deadline))
+		prevConf, newConf := prevConfs[id], conf
+		go func(sid string, prev, sconf *stream.Config, j int) {
+			errUpdate[j] = m.persistThen(ctx, sid, prev, sconf, func() error {
+				return m.Update(sid, *sconf, time.Until(deadline))
+			})
 			wg.Done()
-		}(id, &newConf, i)
+		}(id, &prevConf, &newConf, i)
 		i++
 	}
 	i = 0
 	for id, conf := range toCreate {
 		newConf := conf
 		go func(sid string, sconf *stream.Config, j int) {
-			errCreate[j] = m.Create(sid, *sconf)
+			errCreate[j] = m.persistThen(ctx, sid, nil, sconf, func() error {
+				return m.Create(sid, *sconf)
+			})
 			wg.Done()
 		}(id, &newConf, i)
 		i++
@@ -347,6 +382,14 @@ func (m *Type) HandleStreamCRUD(w http.ResponseWriter, r *http.Request) {
 		deadline = time.Now().Add(m.apiTimeout)
 	}
 
+	if r.Method != "GET" {
+		m.apiLock.Lock()
+		defer m.apiLock.Unlock()
+	}
+
+	ctx, done := context.WithDeadline(r.Context(), deadline)
+	defer done()
+
 	var conf stream.Config
 	var lints []string
 	switch r.Method {
@@ -364,7 +407,13 @@ func (m *Type) HandleStreamCRUD(w http.ResponseWriter, r *http.Request) {
 			w.Write(errBytes)
 			return
 		}
-		serverErr = m.Create(id, conf)
+		if _, err := m.Read(id); err == nil {
+			serverErr = ErrStreamExists
+			break
+		}
+		serverErr = m.persistThen(ctx, id, nil, &conf, func() error {
+			return m.Create(id, conf)
+		})
 	case "GET":
 		var info *StreamStatus
 		if info, serverErr = m.Read(id); serverErr == nil {
@@ -373,11 +422,13 @@ func (m *Type) HandleStreamCRUD(w http.ResponseWriter, r *http.Request) {
 			var bodyBytes []byte
 			if bodyBytes, serverErr = json.Marshal(struct {
 				Active    bool        `json:"active"`
+				Paused    bool        `json:"paused"`
 				Uptime    float64     `json:"uptime"`
 				UptimeStr string      `json:"uptime_str"`
 				Config    interface{} `json:"config"`
 			}{
 				Active:    info.IsRunning(),
+				Paused:    info.IsPaused(),
 				Uptime:    info.Uptime().Seconds(),
 				UptimeStr: info.Uptime().String(),
 				Config:    sanit,
@@ -386,9 +437,17 @@ func (m *Type) HandleStreamCRUD(w http.ResponseWriter, r *http.Request) {
 			}
 
 			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("ETag", strconv.Quote(info.Version()))
 			w.Write(bodyBytes)
 		}
 	case "PUT":
+		var info *StreamStatus
+		if info, serverErr = m.Read(id); serverErr != nil {
+			break
+		}
+		if serverErr = checkVersion(r, info); serverErr != nil {
+			break
+		}
 		if conf, lints, requestErr = readConfig(); requestErr != nil {
 			return
 		}
@@ -402,16 +461,35 @@ func (m *Type) HandleStreamCRUD(w http.ResponseWriter, r *http.Request) {
 			w.Write(errBytes)
 			return
 		}
-		serverErr = m.Update(id, conf, time.Until(deadline))
+		prev := info.Config()
+		serverErr = m.persistThen(ctx, id, &prev, &conf, func() error {
+			return m.Update(id, conf, time.Until(deadline))
+		})
 	case "DELETE":
-		serverErr = m.Delete(id, time.Until(deadline))
+		var info *StreamStatus
+		if info, serverErr = m.Read(id); serverErr != nil {
+			break
+		}
+		if serverErr = checkVersion(r, info); serverErr != nil {
+			break
+		}
+		prev := info.Config()
+		serverErr = m.persistThen(ctx, id, &prev, nil, func() error {
+			return m.Delete(id, time.Until(deadline))
+		})
 	case "PATCH":
 		var info *StreamStatus
 		if info, serverErr = m.Read(id); serverErr == nil {
-			if conf, requestErr = patchConfig(info.Config()); requestErr != nil {
+			if serverErr = checkVersion(r, info); serverErr != nil {
+				break
+			}
+			prev := info.Config()
+			if conf, requestErr = patchConfig(prev); requestErr != nil {
 				return
 			}
-			serverErr = m.Update(id, conf, time.Until(deadline))
+			serverErr = m.persistThen(ctx, id, &prev, &conf, func() error {
+				return m.Update(id, conf, time.Until(deadline))
+			})
 		}
 	default:
 		requestErr = fmt.Errorf("verb not supported: %v", r.Method)
@@ -427,6 +505,34 @@ func (m *Type) HandleStreamCRUD(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Stream already exists", http.StatusBadRequest)
 		return
 	}
+	if serverErr == ErrVersionMismatch {
+		serverErr = nil
+		http.Error(w, "Stream version does not match", http.StatusPreconditionFailed)
+		return
+	}
+	if serverErr != nil || requestErr != nil || r.Method == "GET" {
+		return
+	}
+
+	if info, err := m.Read(id); err == nil {
+		w.Header().Set("ETag", strconv.Quote(info.Version()))
+	}
+}
+
+// checkVersion returns ErrVersionMismatch when a request has an If-Match header
+// that does not match the current version of a stream.
+func checkVersion(r *http.Request, info *StreamStatus) error {
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" || ifMatch == "*" {
+		return nil
+	}
+	for _, tag := range strings.Split(ifMatch, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if strings.Trim(tag, `"`) == info.Version() {
+			return nil
+		}
+	}
+	return ErrVersionMismatch
 }
 
 // HandleResourceCRUD is an http.HandleFunc for performing CRUD operations on
@@ -548,6 +654,80 @@ func (m *Type) HandleResourceCRUD(w http.ResponseWriter, r *http.Request) {
 	storeFn(confNode)
 }
 
+// HandleStreamPause is an http.HandleFunc for pausing the consumption of data
+// from the input of a stream.
+func (m *Type) HandleStreamPause(w http.ResponseWriter, r *http.Request) {
+	m.handleStreamPauseResume(w, r, m.Pause)
+}
+
+// HandleStreamResume is an http.HandleFunc for resuming the consumption of data
+// from the input of a paused stream.
+func (m *Type) HandleStreamResume(w http.ResponseWriter, r *http.Request) {
+	m.handleStreamPauseResume(w, r, m.Resume)
+}
+
+func (m *Type) handleStreamPauseResume(w http.ResponseWriter, r *http.Request, fn func(id string) error) {
+	var serverErr, requestErr error
+	defer func() {
+		if r.Body != nil {
+			r.Body.Close()
+		}
+		if serverErr != nil {
+			m.manager.Logger().Errorf("Stream pause Error: %v\n", serverErr)
+			http.Error(w, fmt.Sprintf("Error: %v", serverErr), http.StatusBadGateway)
+			return
+		}
+		if requestErr != nil {
+			m.manager.Logger().Debugf("Stream request pause Error: %v\n", requestErr)
+			http.Error(w, fmt.Sprintf("Error: %v", requestErr), http.StatusBadRequest)
+			return
+		}
+	}()
+
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		http.Error(w, "Var `id` must be set", http.StatusBadRequest)
+		return
+	}
+
+	if r.Method != "POST" {
+		requestErr = fmt.Errorf("verb not supported: %v", r.Method)
+		return
+	}
+
+	if serverErr = fn(id); serverErr == ErrStreamDoesNotExist {
+		serverErr = nil
+		http.Error(w, "Stream not found", http.StatusNotFound)
+		return
+	}
+}
+
+// HandleStreamTap is an http.HandleFunc for streaming a sample of the messages
+// passing through a stream.
+func (m *Type) HandleStreamTap(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		http.Error(w, "Var `id` must be set", http.StatusBadRequest)
+		return
+	}
+
+	if r.Method != "GET" {
+		http.Error(w, fmt.Sprintf("Error: verb not supported: %v", r.Method), http.StatusBadRequest)
+		return
+	}
+
+	info, err := m.Read(id)
+	if err == ErrStreamDoesNotExist {
+		http.Error(w, "Stream not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error: %v", err), http.StatusBadGateway)
+		return
+	}
+	info.strm.HandleTap(w, r)
+}
+
 // HandleStreamStats is an http.HandleFunc for obtaining metrics for a stream.
 func (m *Type) HandleStreamStats(w http.ResponseWriter, r *http.Request) {
 	var serverErr, requestErr error
@@ -617,21 +797,31 @@ func (m *Type) HandleStreamStats(w http.ResponseWriter, r *http.Request) {
 // HandleStreamReady is an http.HandleFunc for providing a ready check across
 // all streams.
 func (m *Type) HandleStreamReady(w http.ResponseWriter, r *http.Request) {
-	var notReady []string
+	var notReady, paused []string
 
 	m.lock.Lock()
 	for k, v := range m.streams {
-		if !v.IsReady() {
+		if v.IsPaused() {
+			paused = append(paused, k)
+		} else if !v.IsReady() {
 			notReady = append(notReady, k)
 		}
 	}
 	m.lock.Unlock()
 
-	if len(notReady) == 0 {
+	if len(notReady) == 0 && len(paused) == 0 {
 		w.Write([]byte("OK"))
 		return
 	}
 
+	sort.Strings(notReady)
+	sort.Strings(paused)
+
 	w.WriteHeader(http.StatusServiceUnavailable)
-	fmt.Fprintf(w, "streams %v are not connected\n", strings.Join(notReady, ", "))
+	if len(notReady) > 0 {
+		fmt.Fprintf(w, "streams %v are not connected\n", strings.Join(notReady, ", "))
+	}
+	if len(paused) > 0 {
+		fmt.Fprintf(w, "streams %v are paused\n", strings.Join(paused, ", "))
+	}
 }
@@ -37,6 +37,10 @@ func router(m *manager.Type) *mux.Router {
 	router.HandleFunc("/streams", m.HandleStreamsCRUD)
 	router.HandleFunc("/streams/{id}", m.HandleStreamCRUD)
 	router.HandleFunc("/streams/{id}/stats", m.HandleStreamStats)
+	router.HandleFunc("/streams/{id}/pause", m.HandleStreamPause)
+	router.HandleFunc("/streams/{id}/resume", m.HandleStreamResume)
+	router.HandleFunc("/streams/{id}/tap", m.HandleStreamTap)
+	router.HandleFunc("/ready", m.HandleStreamReady)
 	router.HandleFunc("/resources/{type}/{id}", m.HandleResourceCRUD)
 	return router
 }
@@ -85,6 +89,7 @@ func genYAMLRequest(verb, url string, payload interface{}) *http.Request {
 
 type listItemBody struct {
 	Active    bool    `json:"active"`
+	Paused    bool    `json:"paused"`
 	Uptime    float64 `json:"uptime"`
 	UptimeStr string  `json:"uptime_str"`
 }
@@ -101,6 +106,7 @@ func parseListBody(data *bytes.Buffer) listBody {
 
 type getBody struct {
 	Active    bool        `json:"active"`
+	Paused    bool        `json:"paused"`
 	Uptime    float64     `json:"uptime"`
 	UptimeStr string      `json:"uptime_str"`
 	Config    interface{} `json:"config"`
@@ -460,6 +466,100 @@ func TestTypeAPIList(t *testing.T) {
 	}
 }
 
+func TestTypeAPIPauseResume(t *testing.T) {
+	res, err := bmanager.NewV2(bmanager.NewResourceConfig(), mock.NewManager(), log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	mgr := manager.New(res,
+		manager.OptSetAPITimeout(time.Second*10),
+	)
+
+	r := router(mgr)
+
+	request := genRequest("POST", "/streams/foo/pause", nil)
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	require.NoError(t, mgr.Create("foo", harmlessConf()))
+
+	request = genRequest("GET", "/streams/foo/pause", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+
+	request = genRequest("POST", "/streams/foo/pause", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	request = genRequest("GET", "/streams", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.True(t, parseListBody(response.Body)["foo"].Paused)
+
+	request = genRequest("GET", "/ready", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusServiceUnavailable, response.Code)
+	assert.Equal(t, "streams foo are paused\n", response.Body.String())
+
+	newConf := harmlessConf()
+	newConf.Input.HTTPServer.Path = "/foobar"
+	require.NoError(t, mgr.Update("foo", newConf, time.Second*5))
+
+	request = genRequest("GET", "/streams/foo", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.True(t, parseGetBody(t, response.Body).Paused)
+
+	request = genRequest("POST", "/streams/foo/resume", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	request = genRequest("GET", "/streams/foo", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.False(t, parseGetBody(t, response.Body).Paused)
+
+	require.NoError(t, mgr.Stop(time.Second*5))
+}
+
+func TestTypeAPITap(t *testing.T) {
+	res, err := bmanager.NewV2(bmanager.NewResourceConfig(), mock.NewManager(), log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	mgr := manager.New(res,
+		manager.OptSetAPITimeout(time.Second*10),
+	)
+
+	r := router(mgr)
+
+	request := genRequest("GET", "/streams/foo/tap", nil)
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	require.NoError(t, mgr.Create("foo", harmlessConf()))
+
+	request = genRequest("POST", "/streams/foo/tap", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+
+	request = genRequest("GET", "/streams/foo/tap?point=nope", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.Equal(t, "Tap point not recognised: nope\n", response.Body.String())
+
+	require.NoError(t, mgr.Stop(time.Second*5))
+}
+
 func TestTypeAPISetStreams(t *testing.T) {
 	res, err := bmanager.NewV2(bmanager.NewResourceConfig(), mock.NewManager(), log.Noop(), metrics.Noop())
 	require.NoError(t, err)
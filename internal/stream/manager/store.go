@@ -0,0 +1,294 @@
+package manager
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/bundle"
+	"github.com/benthosdev/benthos/v4/internal/component"
+	"github.com/benthosdev/benthos/v4/internal/component/cache"
+	"github.com/benthosdev/benthos/v4/internal/stream"
+)
+
+// Store is a persistence layer for the configs of streams that are created,
+// updated or deleted via the HTTP API, allowing them to be reloaded when the
+// service is restarted.
+type Store interface {
+	// List returns the configs of all stored streams by their ids.
+	List(ctx context.Context) (map[string][]byte, error)
+
+	// Set stores the config of a stream, replacing any existing config.
+	Set(ctx context.Context, id string, conf []byte) error
+
+	// Delete removes the config of a stream, it is not an error for the stream
+	// to not exist.
+	Delete(ctx context.Context, id string) error
+}
+
+// OptSetStore sets a store for persisting streams that are modified via the
+// HTTP API. By default streams are not persisted.
+func OptSetStore(s Store) func(*Type) {
+	return func(t *Type) {
+		t.store = s
+	}
+}
+
+//------------------------------------------------------------------------------
+
+// marshalStreamConfig returns a sanitised YAML representation of a stream
+// config along with a version derived from its contents.
+func marshalStreamConfig(conf stream.Config) (confBytes []byte, version string, err error) {
+	var sanit interface{}
+	if sanit, err = conf.Sanitised(); err != nil {
+		return
+	}
+	if confBytes, err = yaml.Marshal(sanit); err != nil {
+		return
+	}
+	hash := sha256.Sum256(confBytes)
+	version = hex.EncodeToString(hash[:8])
+	return
+}
+
+// LoadStored creates streams from the configs held within the store of the
+// manager, replacing any existing streams of the same id. This is a no-op when
+// a store has not been set.
+func (m *Type) LoadStored(ctx context.Context) error {
+	if m.store == nil {
+		return nil
+	}
+
+	confs, err := m.store.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list stored streams: %w", err)
+	}
+
+	timeout := m.apiTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+
+	for id, confBytes := range confs {
+		conf := stream.NewConfig()
+		if err := yaml.Unmarshal(confBytes, &conf); err != nil {
+			return fmt.Errorf("failed to parse stored stream '%v': %w", id, err)
+		}
+		if err := m.Update(id, conf, timeout); err != nil {
+			if !errors.Is(err, ErrStreamDoesNotExist) {
+				return fmt.Errorf("failed to update stored stream '%v': %w", id, err)
+			}
+			if err = m.Create(id, conf); err != nil {
+				return fmt.Errorf("failed to create stored stream '%v': %w", id, err)
+			}
+		}
+	}
+	return nil
+}
+
+// storeWrite sets the config of a stream within the store, or removes it from
+// the store when the config is nil.
+func (m *Type) storeWrite(ctx context.Context, id string, conf *stream.Config) error {
+	if conf == nil {
+		return m.store.Delete(ctx, id)
+	}
+	confBytes, _, err := marshalStreamConfig(*conf)
+	if err != nil {
+		return err
+	}
+	return m.store.Set(ctx, id, confBytes)
+}
+
+// persistThen writes the next config of a stream (nil when it is being
+// deleted) to the store before applying the change with fn. When fn fails the
+// store is reverted to the previous config, and therefore a change is only
+// applied to the running streams once it has been persisted and vice versa.
+func (m *Type) persistThen(ctx context.Context, id string, prev, next *stream.Config, fn func() error) error {
+	if m.store == nil {
+		return fn()
+	}
+	if err := m.storeWrite(ctx, id, next); err != nil {
+		return fmt.Errorf("failed to persist stream: %w", err)
+	}
+	if err := fn(); err != nil {
+		if rerr := m.storeWrite(ctx, id, prev); rerr != nil {
+			m.manager.Logger().Errorf("Failed to revert persisted stream '%v': %v\n", id, rerr)
+		}
+		return err
+	}
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+type directoryStore struct {
+	dir string
+}
+
+// NewDirectoryStore returns a Store that writes stream configs as YAML files to
+// a directory, where the name of each file is the stream id.
+func NewDirectoryStore(dir string) Store {
+	return &directoryStore{dir: filepath.Clean(dir)}
+}
+
+func (d *directoryStore) path(id string) string {
+	return filepath.Join(d.dir, id+".yaml")
+}
+
+func (d *directoryStore) List(ctx context.Context) (map[string][]byte, error) {
+	confs := map[string][]byte{}
+
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return confs, nil
+		}
+		return nil, err
+	}
+
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".yaml") {
+			continue
+		}
+		confBytes, err := os.ReadFile(filepath.Join(d.dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		confs[strings.TrimSuffix(e.Name(), ".yaml")] = confBytes
+	}
+	return confs, nil
+}
+
+func (d *directoryStore) Set(ctx context.Context, id string, conf []byte) error {
+	if err := os.MkdirAll(d.dir, 0o755); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so that a partially written config is
+	// never read back.
+	tmpFile, err := os.CreateTemp(d.dir, "."+id+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err = tmpFile.Write(conf); err != nil {
+		tmpFile.Close()
+		os.Remove(tmpFile.Name())
+		return err
+	}
+	if err = tmpFile.Close(); err != nil {
+		os.Remove(tmpFile.Name())
+		return err
+	}
+	return os.Rename(tmpFile.Name(), d.path(id))
+}
+
+func (d *directoryStore) Delete(ctx context.Context, id string) error {
+	if err := os.Remove(d.path(id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+type cacheStore struct {
+	mgr      bundle.NewManagement
+	resource string
+	key      string
+
+	mut sync.Mutex
+}
+
+// NewCacheStore returns a Store that writes stream configs to a cache resource.
+// All stream configs are stored within a single key as a JSON object of
+// stream ids to configs.
+func NewCacheStore(mgr bundle.NewManagement, resource, key string) Store {
+	return &cacheStore{
+		mgr:      mgr,
+		resource: resource,
+		key:      key,
+	}
+}
+
+func (c *cacheStore) read(ctx context.Context) (confs map[string]string, err error) {
+	confs = map[string]string{}
+	if cerr := c.mgr.AccessCache(ctx, c.resource, func(cache cache.V1) {
+		var setBytes []byte
+		if setBytes, err = cache.Get(ctx, c.key); err != nil {
+			if errors.Is(err, component.ErrKeyNotFound) {
+				err = nil
+			}
+			return
+		}
+		err = json.Unmarshal(setBytes, &confs)
+	}); cerr != nil {
+		return nil, cerr
+	}
+	return
+}
+
+func (c *cacheStore) write(ctx context.Context, confs map[string]string) (err error) {
+	var setBytes []byte
+	if setBytes, err = json.Marshal(confs); err != nil {
+		return
+	}
+	if cerr := c.mgr.AccessCache(ctx, c.resource, func(cache cache.V1) {
+		err = cache.Set(ctx, c.key, setBytes, nil)
+	}); cerr != nil {
+		return cerr
+	}
+	return
+}
+
+func (c *cacheStore) List(ctx context.Context) (map[string][]byte, error) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	confs, err := c.read(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[string][]byte, len(confs))
+	for k, v := range confs {
+		res[k] = []byte(v)
+	}
+	return res, nil
+}
+
+func (c *cacheStore) Set(ctx context.Context, id string, conf []byte) error {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	confs, err := c.read(ctx)
+	if err != nil {
+		return err
+	}
+	confs[id] = string(conf)
+	return c.write(ctx, confs)
+}
+
+func (c *cacheStore) Delete(ctx context.Context, id string) error {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	confs, err := c.read(ctx)
+	if err != nil {
+		return err
+	}
+	if _, exists := confs[id]; !exists {
+		return nil
+	}
+	delete(confs, id)
+	return c.write(ctx, confs)
+}
@@ -0,0 +1,276 @@
+package manager_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/bundle/mock"
+	"github.com/benthosdev/benthos/v4/internal/component/metrics"
+	"github.com/benthosdev/benthos/v4/internal/log"
+	bmanager "github.com/benthosdev/benthos/v4/internal/manager"
+	mmock "github.com/benthosdev/benthos/v4/internal/manager/mock"
+	"github.com/benthosdev/benthos/v4/internal/stream/manager"
+)
+
+func testStore(t *testing.T, s manager.Store) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	confs, err := s.List(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, confs)
+
+	require.NoError(t, s.Set(ctx, "foo", []byte("foo config")))
+	require.NoError(t, s.Set(ctx, "bar", []byte("bar config")))
+	require.NoError(t, s.Set(ctx, "foo", []byte("new foo config")))
+
+	confs, err = s.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{
+		"foo": []byte("new foo config"),
+		"bar": []byte("bar config"),
+	}, confs)
+
+	require.NoError(t, s.Delete(ctx, "foo"))
+	require.NoError(t, s.Delete(ctx, "baz"))
+
+	confs, err = s.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{
+		"bar": []byte("bar config"),
+	}, confs)
+}
+
+func TestDirectoryStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "streams")
+	testStore(t, manager.NewDirectoryStore(dir))
+
+	barBytes, err := os.ReadFile(filepath.Join(dir, "bar.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "bar config", string(barBytes))
+}
+
+func TestCacheStore(t *testing.T) {
+	mgr := mock.NewManager()
+	mgr.Caches["foocache"] = map[string]mmock.CacheItem{}
+
+	testStore(t, manager.NewCacheStore(mgr, "foocache", "streams"))
+	assert.Contains(t, mgr.Caches["foocache"], "streams")
+
+	_, err := manager.NewCacheStore(mgr, "nope", "streams").List(context.Background())
+	require.Error(t, err)
+}
+
+func TestTypeAPIStoreAndVersions(t *testing.T) {
+	res, err := bmanager.NewV2(bmanager.NewResourceConfig(), mock.NewManager(), log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	mgr := manager.New(res,
+		manager.OptSetAPITimeout(time.Second*10),
+		manager.OptSetStore(manager.NewDirectoryStore(dir)),
+	)
+
+	r := router(mgr)
+	conf, err := harmlessConf().Sanitised()
+	require.NoError(t, err)
+
+	request := genRequest("POST", "/streams/foo", conf)
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	etag := response.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	assert.FileExists(t, filepath.Join(dir, "foo.yaml"))
+
+	request = genRequest("GET", "/streams/foo", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.Equal(t, etag, response.Header().Get("ETag"))
+
+	newConf := harmlessConf()
+	newConf.Input.HTTPServer.Path = "/foobar"
+	newConfSanit, err := newConf.Sanitised()
+	require.NoError(t, err)
+
+	request = genRequest("PUT", "/streams/foo", newConfSanit)
+	request.Header.Set("If-Match", `"nope"`)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusPreconditionFailed, response.Code, response.Body.String())
+
+	request = genRequest("PUT", "/streams/foo", newConfSanit)
+	request.Header.Set("If-Match", etag)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	newETag := response.Header().Get("ETag")
+	assert.NotEqual(t, etag, newETag)
+
+	request = genRequest("DELETE", "/streams/foo", nil)
+	request.Header.Set("If-Match", etag)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusPreconditionFailed, response.Code, response.Body.String())
+
+	// A fresh manager with the same store should load the updated stream.
+	res2, err := bmanager.NewV2(bmanager.NewResourceConfig(), mock.NewManager(), log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	mgr2 := manager.New(res2,
+		manager.OptSetAPITimeout(time.Second*10),
+		manager.OptSetStore(manager.NewDirectoryStore(dir)),
+	)
+	require.NoError(t, mgr2.LoadStored(context.Background()))
+
+	info, err := mgr2.Read("foo")
+	require.NoError(t, err)
+	assert.Equal(t, "/foobar", info.Config().Input.HTTPServer.Path)
+	assert.Equal(t, newETag, `"`+info.Version()+`"`)
+	require.NoError(t, mgr2.Stop(time.Second*5))
+
+	request = genRequest("DELETE", "/streams/foo", nil)
+	request.Header.Set("If-Match", newETag)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.NoFileExists(t, filepath.Join(dir, "foo.yaml"))
+
+	require.NoError(t, mgr.Stop(time.Second*5))
+}
+
+type failingStore struct {
+	mut   sync.Mutex
+	fail  bool
+	confs map[string][]byte
+}
+
+func (f *failingStore) List(ctx context.Context) (map[string][]byte, error) {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	confs := map[string][]byte{}
+	for k, v := range f.confs {
+		confs[k] = v
+	}
+	return confs, nil
+}
+
+func (f *failingStore) Set(ctx context.Context, id string, conf []byte) error {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	if f.fail {
+		return errors.New("store is down")
+	}
+	f.confs[id] = conf
+	return nil
+}
+
+func (f *failingStore) Delete(ctx context.Context, id string) error {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	if f.fail {
+		return errors.New("store is down")
+	}
+	delete(f.confs, id)
+	return nil
+}
+
+func (f *failingStore) setFail(fail bool) {
+	f.mut.Lock()
+	f.fail = fail
+	f.mut.Unlock()
+}
+
+func TestTypeAPIStoreFailure(t *testing.T) {
+	res, err := bmanager.NewV2(bmanager.NewResourceConfig(), mock.NewManager(), log.Noop(), metrics.Noop())
+	require.NoError(t, err)
+
+	store := &failingStore{confs: map[string][]byte{}}
+	mgr := manager.New(res,
+		manager.OptSetAPITimeout(time.Second*10),
+		manager.OptSetStore(store),
+	)
+
+	r := router(mgr)
+	conf, err := harmlessConf().Sanitised()
+	require.NoError(t, err)
+
+	// Changes are not applied when they can't be persisted.
+	store.setFail(true)
+	request := genRequest("POST", "/streams/foo", conf)
+	response := httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusBadGateway, response.Code, response.Body.String())
+
+	_, err = mgr.Read("foo")
+	require.Equal(t, manager.ErrStreamDoesNotExist, err)
+
+	store.setFail(false)
+	request = genRequest("POST", "/streams/foo", conf)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	require.Contains(t, store.confs, "foo")
+	storedFoo := store.confs["foo"]
+
+	// Creating a stream that already exists leaves the store untouched.
+	request = genRequest("POST", "/streams/foo", conf)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusBadRequest, response.Code, response.Body.String())
+	assert.Equal(t, storedFoo, store.confs["foo"])
+
+	newConf := harmlessConf()
+	newConf.Input.HTTPServer.Path = "/foobar"
+	newConfSanit, err := newConf.Sanitised()
+	require.NoError(t, err)
+
+	store.setFail(true)
+	request = genRequest("PUT", "/streams/foo", newConfSanit)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusBadGateway, response.Code, response.Body.String())
+
+	info, err := mgr.Read("foo")
+	require.NoError(t, err)
+	assert.Equal(t, "/post", info.Config().Input.HTTPServer.Path)
+
+	request = genRequest("DELETE", "/streams/foo", nil)
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusBadGateway, response.Code, response.Body.String())
+
+	_, err = mgr.Read("foo")
+	require.NoError(t, err)
+
+	request = genRequest("POST", "/streams", map[string]interface{}{
+		"bar": conf,
+	})
+	response = httptest.NewRecorder()
+	r.ServeHTTP(response, request)
+	require.Equal(t, http.StatusBadRequest, response.Code, response.Body.String())
+
+	_, err = mgr.Read("foo")
+	require.NoError(t, err)
+	_, err = mgr.Read("bar")
+	require.Equal(t, manager.ErrStreamDoesNotExist, err)
+
+	store.setFail(false)
+	assert.Equal(t, map[string][]byte{"foo": storedFoo}, store.confs)
+
+	require.NoError(t, mgr.Stop(time.Second*5))
+}
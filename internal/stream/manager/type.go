@@ -24,6 +24,7 @@ type StreamStatus struct {
 	logger       log.Modular
 	metrics      *metrics.Local
 	createdAt    time.Time
+	version      string
 }
 
 // NewStreamStatus creates a new StreamStatus.
@@ -48,6 +49,11 @@ func (s *StreamStatus) IsRunning() bool {
 	return atomic.LoadInt64(&s.stoppedAfter) == 0
 }
 
+// IsPaused returns a boolean indicating whether the stream is currently paused.
+func (s *StreamStatus) IsPaused() bool {
+	return s.strm.IsPaused()
+}
+
 // IsReady returns a boolean indicating whether the stream is connected at both
 // the input and output level.
 func (s *StreamStatus) IsReady() bool {
@@ -67,6 +73,12 @@ func (s *StreamStatus) Config() stream.Config {
 	return s.config
 }
 
+// Version returns a string that identifies the current configuration of the
+// stream, which changes whenever the configuration is modified.
+func (s *StreamStatus) Version() string {
+	return s.version
+}
+
 // Metrics returns a metrics aggregator of the stream.
 func (s *StreamStatus) Metrics() *metrics.Local {
 	return s.metrics
@@ -94,8 +106,12 @@ type Type struct {
 	manager    bundle.NewManagement
 	apiTimeout time.Duration
 	apiEnabled bool
+	store      Store
 
-	lock sync.Mutex
+	// Serialises API calls that modify streams so that version checks and
+	// store writes are consistent with the modifications themselves.
+	apiLock sync.Mutex
+	lock    sync.Mutex
 }
 
 // New creates a new stream manager.Type.
@@ -136,6 +152,7 @@ func OptSetAPITimeout(tout time.Duration) func(*Type) {
 var (
 	ErrStreamExists       = errors.New("stream already exists")
 	ErrStreamDoesNotExist = errors.New("stream does not exist")
+	ErrVersionMismatch    = errors.New("stream version does not match")
 )
 
 //------------------------------------------------------------------------------
@@ -143,6 +160,10 @@ var (
 // Create attempts to construct and run a new stream under a unique ID. If the
 // ID already exists an error is returned.
 func (m *Type) Create(id string, conf stream.Config) error {
+	return m.create(id, conf, false)
+}
+
+func (m *Type) create(id string, conf stream.Config, paused bool) error {
 	m.lock.Lock()
 	defer m.lock.Unlock()
 
@@ -154,18 +175,30 @@ func (m *Type) Create(id string, conf stream.Config) error {
 		return ErrStreamExists
 	}
 
+	_, version, err := marshalStreamConfig(conf)
+	if err != nil {
+		return err
+	}
+
 	strmFlatMetrics := metrics.NewLocal()
 	sMgr := m.manager.ForStream(id).WithAddedMetrics(strmFlatMetrics).(bundle.NewManagement)
 
 	var wrapper *StreamStatus
-	strm, err := stream.New(conf, sMgr, stream.OptOnClose(func() {
-		wrapper.setClosed()
-	}))
+	strmOpts := []func(*stream.Type){
+		stream.OptOnClose(func() {
+			wrapper.setClosed()
+		}),
+	}
+	if paused {
+		strmOpts = append(strmOpts, stream.OptPaused())
+	}
+	strm, err := stream.New(conf, sMgr, strmOpts...)
 	if err != nil {
 		return err
 	}
 
 	wrapper = NewStreamStatus(conf, strm, sMgr.Logger(), strmFlatMetrics)
+	wrapper.version = version
 	m.streams[id] = wrapper
 	return nil
 }
@@ -207,10 +240,39 @@ func (m *Type) Update(id string, conf stream.Config, timeout time.Duration) erro
 		return nil
 	}
 
+	// Stopping a stream resumes it, so the paused state is captured first in
+	// order to carry it over to the new stream.
+	paused := wrapper.IsPaused()
 	if err := m.Delete(id, timeout); err != nil {
 		return err
 	}
-	return m.Create(id, conf)
+	return m.create(id, conf, paused)
+}
+
+// Pause stops a stream from consuming data from its input without closing the
+// stream. Pausing a stream that is already paused has no effect.
+func (m *Type) Pause(id string) error {
+	wrapper, err := m.Read(id)
+	if err != nil {
+		return err
+	}
+	if wrapper.strm.Pause() {
+		wrapper.logger.Infoln("Stream paused")
+	}
+	return nil
+}
+
+// Resume continues consuming data from the input of a paused stream. Resuming a
+// stream that is not paused has no effect.
+func (m *Type) Resume(id string) error {
+	wrapper, err := m.Read(id)
+	if err != nil {
+		return err
+	}
+	if wrapper.strm.Resume() {
+		wrapper.logger.Infoln("Stream resumed")
+	}
+	return nil
 }
 
 // Delete attempts to stop and remove a stream by its ID. Returns an error if
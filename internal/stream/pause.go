@@ -0,0 +1,109 @@
+package stream
+
+import (
+	"sync"
+
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+// inputGate sits between the input layer of a stream and the rest of the
+// stream, and when paused it stops consuming transactions from the input. The
+// input remains connected and any transactions already past the gate continue
+// to be processed and acknowledged as normal.
+type inputGate struct {
+	out  chan message.Transaction
+	taps *tapper
+
+	// pauseChan is closed whilst the gate is paused and resumeChan is closed
+	// whilst the gate is running.
+	pauseChan  chan struct{}
+	resumeChan chan struct{}
+	mut        sync.Mutex
+}
+
+func newInputGate(taps *tapper) *inputGate {
+	resumeChan := make(chan struct{})
+	close(resumeChan)
+	return &inputGate{
+		out:        make(chan message.Transaction),
+		taps:       taps,
+		pauseChan:  make(chan struct{}),
+		resumeChan: resumeChan,
+	}
+}
+
+// consume begins forwarding transactions from an input channel until it is
+// closed.
+func (g *inputGate) consume(in <-chan message.Transaction) {
+	go g.loop(in)
+}
+
+func (g *inputGate) loop(in <-chan message.Transaction) {
+	defer close(g.out)
+	for {
+		g.mut.Lock()
+		pauseChan, resumeChan := g.pauseChan, g.resumeChan
+		g.mut.Unlock()
+
+		select {
+		case <-pauseChan:
+			<-resumeChan
+			continue
+		default:
+		}
+
+		select {
+		case tran, open := <-in:
+			if !open {
+				return
+			}
+			g.taps.offer(TapPointInput, tran.Payload)
+			g.out <- tran
+		case <-pauseChan:
+		}
+	}
+}
+
+// pause stops the gate from consuming transactions, returns false if the gate
+// was already paused.
+func (g *inputGate) pause() bool {
+	g.mut.Lock()
+	defer g.mut.Unlock()
+
+	select {
+	case <-g.pauseChan:
+		return false
+	default:
+	}
+	g.resumeChan = make(chan struct{})
+	close(g.pauseChan)
+	return true
+}
+
+// resume continues consuming transactions, returns false if the gate was not
+// paused.
+func (g *inputGate) resume() bool {
+	g.mut.Lock()
+	defer g.mut.Unlock()
+
+	select {
+	case <-g.resumeChan:
+		return false
+	default:
+	}
+	g.pauseChan = make(chan struct{})
+	close(g.resumeChan)
+	return true
+}
+
+func (g *inputGate) isPaused() bool {
+	g.mut.Lock()
+	defer g.mut.Unlock()
+
+	select {
+	case <-g.pauseChan:
+		return true
+	default:
+	}
+	return false
+}
@@ -0,0 +1,346 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/benthosdev/benthos/v4/internal/bloblang/mapping"
+	iprocessor "github.com/benthosdev/benthos/v4/internal/component/processor"
+	"github.com/benthosdev/benthos/v4/internal/message"
+)
+
+// Points within a stream that messages can be tapped from, processors are
+// tapped with the point pipeline.processors.<index>.
+const (
+	TapPointInput  = "input"
+	TapPointOutput = "output"
+)
+
+const (
+	tapProcessorPrefix = "pipeline.processors."
+	tapBufferSize      = 64
+	tapDefaultRate     = 10
+)
+
+var errTapClosed = errors.New("stream is closed")
+
+type tapSub struct {
+	point string
+	ch    chan *message.Batch
+}
+
+// tapper distributes copies of the messages passing through tap points of a
+// stream to subscribers. Messages are dropped for subscribers that are unable
+// to keep up, and so tapping a stream never applies back pressure.
+type tapper struct {
+	subCount int64
+
+	subs   map[string]map[*tapSub]struct{}
+	closed bool
+	mut    sync.RWMutex
+}
+
+func newTapper() *tapper {
+	return &tapper{
+		subs: map[string]map[*tapSub]struct{}{},
+	}
+}
+
+func (t *tapper) offer(point string, b *message.Batch) {
+	if atomic.LoadInt64(&t.subCount) == 0 {
+		return
+	}
+
+	t.mut.RLock()
+	defer t.mut.RUnlock()
+
+	subs := t.subs[point]
+	if len(subs) == 0 {
+		return
+	}
+
+	bCopy := b.DeepCopy()
+	for s := range subs {
+		select {
+		case s.ch <- bCopy:
+		default:
+		}
+	}
+}
+
+func (t *tapper) subscribe(point string) (*tapSub, error) {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	if t.closed {
+		return nil, errTapClosed
+	}
+
+	s := &tapSub{
+		point: point,
+		ch:    make(chan *message.Batch, tapBufferSize),
+	}
+	if _, exists := t.subs[point]; !exists {
+		t.subs[point] = map[*tapSub]struct{}{}
+	}
+	t.subs[point][s] = struct{}{}
+	atomic.AddInt64(&t.subCount, 1)
+	return s, nil
+}
+
+func (t *tapper) unsubscribe(s *tapSub) {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	if _, exists := t.subs[s.point][s]; !exists {
+		return
+	}
+	delete(t.subs[s.point], s)
+	close(s.ch)
+	atomic.AddInt64(&t.subCount, -1)
+}
+
+// close terminates all subscriptions.
+func (t *tapper) close() {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	for _, subs := range t.subs {
+		for s := range subs {
+			close(s.ch)
+		}
+	}
+	t.subs = map[string]map[*tapSub]struct{}{}
+	t.closed = true
+	atomic.StoreInt64(&t.subCount, 0)
+}
+
+//------------------------------------------------------------------------------
+
+// tappedProcessor offers the results of a processor to a tap point.
+type tappedProcessor struct {
+	iprocessor.V1
+
+	points []string
+	taps   *tapper
+}
+
+func (p *tappedProcessor) ProcessMessage(msg *message.Batch) ([]*message.Batch, error) {
+	msgs, err := p.V1.ProcessMessage(msg)
+	for _, m := range msgs {
+		for _, point := range p.points {
+			p.taps.offer(point, m)
+		}
+	}
+	return msgs, err
+}
+
+func (t *Type) wrapTappedProcessor(index int, proc iprocessor.V1) iprocessor.V1 {
+	points := []string{tapProcessorPrefix + strconv.Itoa(index)}
+	if index == len(t.conf.Pipeline.Processors)-1 {
+		points = append(points, TapPointOutput)
+	}
+	return &tappedProcessor{
+		V1:     proc,
+		points: points,
+		taps:   t.taps,
+	}
+}
+
+//------------------------------------------------------------------------------
+
+type tapEvent struct {
+	Point    string            `json:"point"`
+	Content  string            `json:"content"`
+	Metadata map[string]string `json:"metadata"`
+	Error    string            `json:"error,omitempty"`
+}
+
+func (t *Type) validTapPoint(point string) bool {
+	switch point {
+	case TapPointInput, TapPointOutput:
+		return true
+	}
+	if !strings.HasPrefix(point, tapProcessorPrefix) {
+		return false
+	}
+	index, err := strconv.Atoi(strings.TrimPrefix(point, tapProcessorPrefix))
+	if err != nil {
+		return false
+	}
+	return index >= 0 && index < len(t.conf.Pipeline.Processors)
+}
+
+// HandleTap is an http.HandleFunc that streams a sample of the messages passing
+// through a point of the stream to the client, either as server-sent events or
+// over a websocket when the request is an upgrade.
+//
+// The tap point is set with the URL param `point`, which can be `input`,
+// `output` or `pipeline.processors.<index>`. The param `filter` sets an
+// optional Bloblang query that messages must match, and `rate` sets the maximum
+// number of messages sent per second, where zero means unlimited.
+func (t *Type) HandleTap(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	point := query.Get("point")
+	if point == "" {
+		point = TapPointInput
+	}
+	if !t.validTapPoint(point) {
+		http.Error(w, fmt.Sprintf("Tap point not recognised: %v", point), http.StatusBadRequest)
+		return
+	}
+
+	rate := tapDefaultRate
+	if rateStr := query.Get("rate"); rateStr != "" {
+		var err error
+		if rate, err = strconv.Atoi(rateStr); err != nil || rate < 0 {
+			http.Error(w, fmt.Sprintf("Rate must be a non-negative integer: %v", rateStr), http.StatusBadRequest)
+			return
+		}
+	}
+
+	var filter *mapping.Executor
+	if filterStr := query.Get("filter"); filterStr != "" {
+		// Filters are supplied by HTTP clients and therefore must not be able to
+		// access machine state such as environment variables or files.
+		env := t.manager.BloblEnvironment().OnlyPure().WithDisabledImports()
+
+		var err error
+		if filter, err = env.NewMapping(filterStr); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to parse filter: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	// With no processors the messages reaching the output are those of the
+	// input.
+	subPoint := point
+	if point == TapPointOutput && len(t.conf.Pipeline.Processors) == 0 {
+		subPoint = TapPointInput
+	}
+
+	sub, err := t.taps.subscribe(subPoint)
+	if err != nil {
+		http.Error(w, "Stream is closed", http.StatusServiceUnavailable)
+		return
+	}
+	defer t.taps.unsubscribe(sub)
+
+	var send func([]byte) error
+	ctx, done := context.WithCancel(r.Context())
+	defer done()
+
+	if websocket.IsWebSocketUpgrade(r) {
+		upgrader := websocket.Upgrader{}
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.manager.Logger().Debugf("Tap websocket upgrade failed: %v\n", err)
+			return
+		}
+		defer ws.Close()
+
+		// Read until the client closes the connection.
+		go func() {
+			for {
+				if _, _, err := ws.NextReader(); err != nil {
+					done()
+					return
+				}
+			}
+		}()
+		send = func(data []byte) error {
+			return ws.WriteMessage(websocket.TextMessage, data)
+		}
+	} else {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "Server error", http.StatusInternalServerError)
+			t.manager.Logger().Errorln("Failed to cast response writer to flusher")
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		send = func(data []byte) error {
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+				return err
+			}
+			flusher.Flush()
+			return nil
+		}
+	}
+
+	var windowStart time.Time
+	var windowCount int
+
+	for {
+		var batch *message.Batch
+		var open bool
+		select {
+		case batch, open = <-sub.ch:
+			if !open {
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+
+		for i := 0; i < batch.Len(); i++ {
+			if filter != nil {
+				matched, err := filter.QueryPart(i, batch)
+				if err != nil {
+					t.manager.Logger().Debugf("Tap filter failed: %v\n", err)
+					continue
+				}
+				if !matched {
+					continue
+				}
+			}
+
+			if rate > 0 {
+				if now := time.Now(); now.Sub(windowStart) >= time.Second {
+					windowStart, windowCount = now, 0
+				}
+				if windowCount >= rate {
+					continue
+				}
+				windowCount++
+			}
+
+			part := batch.Get(i)
+			event := tapEvent{
+				Point:    point,
+				Content:  string(part.Get()),
+				Metadata: map[string]string{},
+				Error:    iprocessor.GetFail(part),
+			}
+			_ = part.MetaIter(func(k, v string) error {
+				if k != message.FailFlagKey {
+					event.Metadata[k] = v
+				}
+				return nil
+			})
+
+			eventBytes, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			if err = send(eventBytes); err != nil {
+				return
+			}
+		}
+	}
+}
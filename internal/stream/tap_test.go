@@ -0,0 +1,166 @@
+package stream_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	bmock "github.com/benthosdev/benthos/v4/internal/bundle/mock"
+	"github.com/benthosdev/benthos/v4/internal/old/input"
+	"github.com/benthosdev/benthos/v4/internal/old/output"
+	"github.com/benthosdev/benthos/v4/internal/old/processor"
+	"github.com/benthosdev/benthos/v4/internal/stream"
+)
+
+type tapEvent struct {
+	Point    string            `json:"point"`
+	Content  string            `json:"content"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+func readTapEvents(t *testing.T, u string, limit int, timeout time.Duration) (events []tapEvent) {
+	t.Helper()
+
+	ctx, done := context.WithTimeout(context.Background(), timeout)
+	defer done()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	require.NoError(t, err)
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+	scanner := bufio.NewScanner(res.Body)
+	for len(events) < limit && scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		var e tapEvent
+		require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &e))
+		events = append(events, e)
+	}
+	return
+}
+
+func tapTestStream(t *testing.T) *stream.Type {
+	t.Helper()
+
+	conf := stream.NewConfig()
+	conf.Input.Type = input.TypeGenerate
+	conf.Input.Generate.Mapping = `root = "hello world"
+meta foo = "bar"`
+	conf.Input.Generate.Interval = "1ms"
+
+	procConf := processor.NewConfig()
+	procConf.Type = processor.TypeBloblang
+	procConf.Bloblang = `root = content().uppercase()`
+	conf.Pipeline.Processors = append(conf.Pipeline.Processors, procConf)
+
+	conf.Output.Type = output.TypeDrop
+
+	strm, err := stream.New(conf, bmock.NewManager())
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, strm.Stop(time.Second*5))
+	})
+	return strm
+}
+
+func TestTypeTapPoints(t *testing.T) {
+	strm := tapTestStream(t)
+
+	server := httptest.NewServer(http.HandlerFunc(strm.HandleTap))
+	defer server.Close()
+
+	events := readTapEvents(t, server.URL, 1, time.Second*5)
+	require.Len(t, events, 1)
+	assert.Equal(t, tapEvent{
+		Point:    "input",
+		Content:  "hello world",
+		Metadata: map[string]string{"foo": "bar"},
+	}, events[0])
+
+	for _, point := range []string{"pipeline.processors.0", "output"} {
+		events = readTapEvents(t, server.URL+"?point="+point, 1, time.Second*5)
+		require.Len(t, events, 1, point)
+		assert.Equal(t, point, events[0].Point)
+		assert.Equal(t, "HELLO WORLD", events[0].Content)
+	}
+}
+
+func TestTypeTapFilterAndRate(t *testing.T) {
+	strm := tapTestStream(t)
+
+	server := httptest.NewServer(http.HandlerFunc(strm.HandleTap))
+	defer server.Close()
+
+	events := readTapEvents(t, server.URL+"?rate=5", 100, time.Millisecond*500)
+	assert.Len(t, events, 5)
+
+	events = readTapEvents(t, server.URL+"?filter="+url.QueryEscape(`meta("foo") == "bar"`), 1, time.Second*5)
+	assert.Len(t, events, 1)
+
+	events = readTapEvents(t, server.URL+"?filter="+url.QueryEscape(`content() == "nope"`), 1, time.Millisecond*100)
+	assert.Empty(t, events)
+}
+
+func TestTypeTapWebsocket(t *testing.T) {
+	strm := tapTestStream(t)
+
+	server := httptest.NewServer(http.HandlerFunc(strm.HandleTap))
+	defer server.Close()
+
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"?point=output", nil)
+	require.NoError(t, err)
+	defer ws.Close()
+
+	var e tapEvent
+	require.NoError(t, ws.ReadJSON(&e))
+	assert.Equal(t, "output", e.Point)
+	assert.Equal(t, "HELLO WORLD", e.Content)
+}
+
+func TestTypeTapBadRequests(t *testing.T) {
+	strm := tapTestStream(t)
+
+	for _, query := range []string{
+		"?point=nope",
+		"?point=pipeline.processors.1",
+		"?rate=-1",
+		"?filter=" + url.QueryEscape("root = ^"),
+	} {
+		res := httptest.NewRecorder()
+		strm.HandleTap(res, httptest.NewRequest("GET", "/tap"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, res.Code, query)
+	}
+}
+
+func TestTypeTapFilterImpure(t *testing.T) {
+	strm := tapTestStream(t)
+
+	for _, filter := range []string{
+		`env("HOME").has_prefix("/")`,
+		`file("/etc/hosts").length() > 0`,
+		`import "./foo.blobl"
+root = true`,
+	} {
+		res := httptest.NewRecorder()
+		strm.HandleTap(res, httptest.NewRequest("GET", "/tap?filter="+url.QueryEscape(filter), nil))
+		assert.Equal(t, http.StatusBadRequest, res.Code, filter)
+		assert.Contains(t, res.Body.String(), "Failed to parse filter", filter)
+	}
+}
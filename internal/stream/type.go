@@ -22,6 +22,8 @@ type Type struct {
 	conf Config
 
 	inputLayer    iinput.Streamed
+	inputGate     *inputGate
+	taps          *tapper
 	bufferLayer   ibuffer.Streamed
 	pipelineLayer pipeline.Type
 	outputLayer   ioutput.Streamed
@@ -33,10 +35,13 @@ type Type struct {
 
 // New creates a new stream.Type.
 func New(conf Config, mgr bundle.NewManagement, opts ...func(*Type)) (*Type, error) {
+	taps := newTapper()
 	t := &Type{
-		conf:    conf,
-		manager: mgr,
-		onClose: func() {},
+		conf:      conf,
+		manager:   mgr,
+		inputGate: newInputGate(taps),
+		taps:      taps,
+		onClose:   func() {},
 	}
 	for _, opt := range opts {
 		opt(t)
@@ -78,6 +83,14 @@ func OptOnClose(onClose func()) func(*Type) {
 	}
 }
 
+// OptPaused sets the stream to begin in a paused state, where data is not
+// consumed from the input until the stream is resumed.
+func OptPaused() func(*Type) {
+	return func(t *Type) {
+		t.inputGate.pause()
+	}
+}
+
 //------------------------------------------------------------------------------
 
 // IsReady returns a boolean indicating whether both the input and output layers
@@ -86,6 +99,24 @@ func (t *Type) IsReady() bool {
 	return t.inputLayer.Connected() && t.outputLayer.Connected()
 }
 
+// Pause stops the stream from consuming data from its input, without closing
+// the input or interrupting data that is already in flight. Returns false if
+// the stream was already paused.
+func (t *Type) Pause() bool {
+	return t.inputGate.pause()
+}
+
+// Resume continues consuming data from the input of a paused stream. Returns
+// false if the stream was not paused.
+func (t *Type) Resume() bool {
+	return t.inputGate.resume()
+}
+
+// IsPaused returns a boolean indicating whether the stream is paused.
+func (t *Type) IsPaused() bool {
+	return t.inputGate.isPaused()
+}
+
 func (t *Type) start() (err error) {
 	// Constructors
 	iMgr := t.manager.IntoPath("input").(bundle.NewManagement)
@@ -100,7 +131,7 @@ func (t *Type) start() (err error) {
 	}
 	if tLen := len(t.conf.Pipeline.Processors); tLen > 0 {
 		pMgr := t.manager.IntoPath("pipeline")
-		if t.pipelineLayer, err = pipeline.New(t.conf.Pipeline, pMgr); err != nil {
+		if t.pipelineLayer, err = pipeline.NewWithWrapper(t.conf.Pipeline, pMgr, t.wrapTappedProcessor); err != nil {
 			return
 		}
 	}
@@ -112,7 +143,8 @@ func (t *Type) start() (err error) {
 	// Start chaining components
 	var nextTranChan <-chan message.Transaction
 
-	nextTranChan = t.inputLayer.TransactionChan()
+	t.inputGate.consume(t.inputLayer.TransactionChan())
+	nextTranChan = t.inputGate.out
 	if t.bufferLayer != nil {
 		if err = t.bufferLayer.Consume(nextTranChan); err != nil {
 			return
@@ -132,6 +164,7 @@ func (t *Type) start() (err error) {
 	go func(out ioutput.Streamed) {
 		for {
 			if err := out.WaitForClose(time.Second); err == nil {
+				t.taps.close()
 				t.onClose()
 				return
 			}
@@ -146,6 +179,9 @@ func (t *Type) start() (err error) {
 // proxy. This should guarantee that all in-flight and buffered data is resolved
 // before shutting down.
 func (t *Type) StopGracefully(timeout time.Duration) (err error) {
+	// A paused stream must be resumed in order for the closure of the input to
+	// propagate through the remaining layers.
+	t.inputGate.resume()
 	t.inputLayer.CloseAsync()
 	started := time.Now()
 	if err = t.inputLayer.WaitForClose(timeout); err != nil {
@@ -196,6 +232,7 @@ func (t *Type) StopGracefully(timeout time.Duration) (err error) {
 // the pipeline under certain circumstances but is less graceful than
 // stopGracefully, which should be attempted first.
 func (t *Type) StopOrdered(timeout time.Duration) (err error) {
+	t.inputGate.resume()
 	t.inputLayer.CloseAsync()
 	started := time.Now()
 	if err = t.inputLayer.WaitForClose(timeout); err != nil {
@@ -242,6 +279,7 @@ func (t *Type) StopOrdered(timeout time.Duration) (err error) {
 // the stream to gracefully wind down in the order of component layers. This
 // should only be attempted if both stopGracefully and stopOrdered failed.
 func (t *Type) StopUnordered(timeout time.Duration) (err error) {
+	t.inputGate.resume()
 	t.inputLayer.CloseAsync()
 	if t.bufferLayer != nil {
 		t.bufferLayer.CloseAsync()
@@ -1,16 +1,19 @@
 package stream_test
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	bmock "github.com/benthosdev/benthos/v4/internal/bundle/mock"
 	"github.com/benthosdev/benthos/v4/internal/component/metrics"
 	"github.com/benthosdev/benthos/v4/internal/log"
 	"github.com/benthosdev/benthos/v4/internal/manager"
 	"github.com/benthosdev/benthos/v4/internal/manager/mock"
+	"github.com/benthosdev/benthos/v4/internal/message"
 	"github.com/benthosdev/benthos/v4/internal/old/input"
 	"github.com/benthosdev/benthos/v4/internal/old/output"
 	"github.com/benthosdev/benthos/v4/internal/old/processor"
@@ -122,3 +125,62 @@ func TestTypeCloseUnordered(t *testing.T) {
 	require.NoError(t, err)
 	assert.NoError(t, strm.StopUnordered(time.Minute))
 }
+
+func TestTypePauseResume(t *testing.T) {
+	msgChan := make(chan string, 1000)
+
+	mgr := bmock.NewManager()
+	mgr.Outputs["foo"] = func(ctx context.Context, tran message.Transaction) error {
+		_ = tran.Payload.Iter(func(i int, p *message.Part) error {
+			msgChan <- string(p.Get())
+			return nil
+		})
+		return tran.Ack(ctx, nil)
+	}
+
+	conf := stream.NewConfig()
+	conf.Input.Type = input.TypeGenerate
+	conf.Input.Generate.Mapping = `root = "hello world"`
+	conf.Input.Generate.Interval = "1ms"
+	conf.Output.Type = output.TypeResource
+	conf.Output.Resource = "foo"
+
+	strm, err := stream.New(conf, mgr, stream.OptPaused())
+	require.NoError(t, err)
+	assert.True(t, strm.IsPaused())
+
+	select {
+	case <-msgChan:
+		t.Fatal("received message from paused stream")
+	case <-time.After(time.Millisecond * 50):
+	}
+
+	assert.True(t, strm.Resume())
+	assert.False(t, strm.Resume())
+	assert.False(t, strm.IsPaused())
+
+	select {
+	case msg := <-msgChan:
+		assert.Equal(t, "hello world", msg)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for message")
+	}
+
+	assert.True(t, strm.Pause())
+	assert.False(t, strm.Pause())
+	assert.True(t, strm.IsPaused())
+
+	// Allow for transactions that were in flight before pausing.
+	<-time.After(time.Millisecond * 50)
+	for len(msgChan) > 0 {
+		<-msgChan
+	}
+
+	select {
+	case <-msgChan:
+		t.Fatal("received message from paused stream")
+	case <-time.After(time.Millisecond * 50):
+	}
+
+	require.NoError(t, strm.Stop(time.Second*5))
+}
@@ -3,7 +3,6 @@ package tracing
 import (
 	"context"
 
-	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/trace"
 
@@ -14,6 +13,13 @@ const (
 	name = "benthos"
 )
 
+// The propagator used for injecting and extracting spans from text maps.
+var textMapPropagator = propagation.TraceContext{}
+
+// noopProvider is used for child spans of messages that aren't already traced,
+// as there is no provider to inherit.
+var noopProvider = trace.NewNoopTracerProvider()
+
 // GetSpan returns a span attached to a message part. Returns nil if the part
 // doesn't have a span attached.
 func GetSpan(p *message.Part) *Span {
@@ -26,14 +32,16 @@ func GetSpan(p *message.Part) *Span {
 }
 
 // CreateChildSpan takes a message part, extracts an existing span if there is
-// one and returns child span.
+// one and returns child span. Child spans are created by the same provider as
+// their parent, and if the part does not have a span then the returned span is
+// not recorded.
 func CreateChildSpan(operationName string, part *message.Part) *Span {
 	span := GetSpan(part)
 	if span == nil {
-		ctx, t := otel.GetTracerProvider().Tracer(name).Start(context.Background(), operationName)
+		ctx, t := noopProvider.Tracer(name).Start(context.Background(), operationName)
 		span = otelSpan(ctx, t)
 	} else {
-		ctx, t := otel.GetTracerProvider().Tracer(name).Start(span.ctx, operationName)
+		ctx, t := span.unwrap().TracerProvider().Tracer(name).Start(span.ctx, operationName)
 		span = otelSpan(ctx, t)
 	}
 	return span
@@ -88,15 +96,15 @@ func WithChildSpans(operationName string, msg *message.Batch) (*message.Batch, [
 // WithSiblingSpans takes a message, extracts spans per message part, creates
 // new sibling spans, and returns a new message with those spans embedded. The
 // original message is unchanged.
-func WithSiblingSpans(operationName string, msg *message.Batch) *message.Batch {
+func WithSiblingSpans(prov trace.TracerProvider, operationName string, msg *message.Batch) *message.Batch {
 	parts := make([]*message.Part, msg.Len())
 	_ = msg.Iter(func(i int, part *message.Part) error {
 		otSpan := GetSpan(part)
 		if otSpan == nil {
-			ctx, t := otel.GetTracerProvider().Tracer(name).Start(context.Background(), operationName)
+			ctx, t := prov.Tracer(name).Start(context.Background(), operationName)
 			otSpan = otelSpan(ctx, t)
 		} else {
-			ctx, t := otel.GetTracerProvider().Tracer(name).Start(
+			ctx, t := prov.Tracer(name).Start(
 				context.Background(), operationName,
 				trace.WithLinks(trace.LinkFromContext(otSpan.ctx)),
 			)
@@ -127,10 +135,10 @@ func IterateWithChildSpans(operationName string, msg *message.Batch, iter func(i
 
 // InitSpans sets up OpenTracing spans on each message part if one does not
 // already exist.
-func InitSpans(operationName string, msg *message.Batch) {
+func InitSpans(prov trace.TracerProvider, operationName string, msg *message.Batch) {
 	tracedParts := make([]*message.Part, msg.Len())
 	_ = msg.Iter(func(i int, p *message.Part) error {
-		tracedParts[i] = InitSpan(operationName, p)
+		tracedParts[i] = InitSpan(prov, operationName, p)
 		return nil
 	})
 	msg.SetAll(tracedParts)
@@ -138,11 +146,11 @@ func InitSpans(operationName string, msg *message.Batch) {
 
 // InitSpan sets up an OpenTracing span on a message part if one does not
 // already exist.
-func InitSpan(operationName string, part *message.Part) *message.Part {
+func InitSpan(prov trace.TracerProvider, operationName string, part *message.Part) *message.Part {
 	if GetSpan(part) != nil {
 		return part
 	}
-	ctx, _ := otel.GetTracerProvider().Tracer(name).Start(context.Background(), operationName)
+	ctx, _ := prov.Tracer(name).Start(context.Background(), operationName)
 	return message.WithContext(ctx, part)
 }
 
@@ -163,13 +171,13 @@ func InitSpanFromParent(operationName string, parent *Span, part *message.Part)
 	if GetSpan(part) != nil {
 		return part
 	}
-	ctx, _ := otel.GetTracerProvider().Tracer(name).Start(parent.ctx, operationName)
+	ctx, _ := parent.unwrap().TracerProvider().Tracer(name).Start(parent.ctx, operationName)
 	return message.WithContext(ctx, part)
 }
 
 // InitSpansFromParentTextMap obtains a span parent reference from a text map
 // and creates child spans for each message.
-func InitSpansFromParentTextMap(operationName string, textMapGeneric map[string]interface{}, msg *message.Batch) error {
+func InitSpansFromParentTextMap(prov trace.TracerProvider, operationName string, textMapGeneric map[string]interface{}, msg *message.Batch) error {
 	c := propagation.MapCarrier{}
 	for k, v := range textMapGeneric {
 		if vStr, ok := v.(string); ok {
@@ -177,11 +185,11 @@ func InitSpansFromParentTextMap(operationName string, textMapGeneric map[string]
 		}
 	}
 
-	ctx := otel.GetTextMapPropagator().Extract(context.Background(), c)
+	ctx := textMapPropagator.Extract(context.Background(), c)
 
 	tracedParts := make([]*message.Part, msg.Len())
 	_ = msg.Iter(func(i int, p *message.Part) error {
-		pCtx, _ := otel.GetTracerProvider().Tracer(name).Start(ctx, operationName)
+		pCtx, _ := prov.Tracer(name).Start(ctx, operationName)
 		tracedParts[i] = message.WithContext(pCtx, p)
 		return nil
 	})
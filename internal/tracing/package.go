@@ -1,6 +1,7 @@
-// Package tracing implements utility functions for interacting with a global
-// tracing system. Currently this system uses the opentracing APIs. However,
-// eventually we will need to migrate to the new opentelemetry APIs, and
-// therefore in order to reduce disruption this package abstracts interaction
-// with these packages.
+// Package tracing implements utility functions for interacting with the
+// tracing system. Root spans are created from a tracer provider given by the
+// caller, which is usually obtained from the manager of a component, and child
+// spans are created from the same provider as their parent. This package
+// abstracts interaction with the opentelemetry APIs in order to reduce
+// disruption should they change.
 package tracing
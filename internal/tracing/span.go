@@ -3,7 +3,6 @@ package tracing
 import (
 	"context"
 
-	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/trace"
@@ -52,7 +51,7 @@ func (s *Span) Finish() {
 // TextMap attempts to inject a span into a map object in text map format.
 func (s *Span) TextMap() (map[string]interface{}, error) {
 	c := propagation.MapCarrier{}
-	otel.GetTextMapPropagator().Inject(s.ctx, c)
+	textMapPropagator.Inject(s.ctx, c)
 
 	spanMapGeneric := make(map[string]interface{}, len(c))
 	for k, v := range c {
@@ -21,7 +21,9 @@ import (
 	_ "github.com/benthosdev/benthos/v4/internal/impl/mongodb"
 	_ "github.com/benthosdev/benthos/v4/internal/impl/msgpack"
 	_ "github.com/benthosdev/benthos/v4/internal/impl/nats"
+	_ "github.com/benthosdev/benthos/v4/internal/impl/opentelemetry"
 	_ "github.com/benthosdev/benthos/v4/internal/impl/parquet"
+	_ "github.com/benthosdev/benthos/v4/internal/impl/postgresql"
 	_ "github.com/benthosdev/benthos/v4/internal/impl/prometheus"
 	_ "github.com/benthosdev/benthos/v4/internal/impl/pulsar"
 	_ "github.com/benthosdev/benthos/v4/internal/impl/redis"
@@ -0,0 +1,10 @@
+//go:build (darwin && amd64) || (darwin && arm64) || (freebsd && 386) || (freebsd && amd64) || (linux && 386) || (linux && amd64) || (linux && arm) || (linux && arm64) || (linux && s390x) || (netbsd && amd64) || (openbsd && amd64) || (windows && 386) || (windows && amd64)
+// +build darwin,amd64 darwin,arm64 freebsd,386 freebsd,amd64 linux,386 linux,amd64 linux,arm linux,arm64 linux,s390x netbsd,amd64 openbsd,amd64 windows,386 windows,amd64
+
+package all
+
+import (
+	// The sqlite driver is a pure Go translation of the C library and is
+	// therefore only available on the platforms it has been generated for.
+	_ "modernc.org/sqlite"
+)
@@ -8,10 +8,14 @@ import (
 	"github.com/benthosdev/benthos/v4/internal/component/buffer"
 	"github.com/benthosdev/benthos/v4/internal/component/cache"
 	iinput "github.com/benthosdev/benthos/v4/internal/component/input"
+	"github.com/benthosdev/benthos/v4/internal/component/metrics"
 	ioutput "github.com/benthosdev/benthos/v4/internal/component/output"
 	iprocessor "github.com/benthosdev/benthos/v4/internal/component/processor"
 	"github.com/benthosdev/benthos/v4/internal/component/ratelimit"
+	"github.com/benthosdev/benthos/v4/internal/component/tracer"
 	"github.com/benthosdev/benthos/v4/internal/docs"
+	"github.com/benthosdev/benthos/v4/internal/log"
+	"github.com/benthosdev/benthos/v4/internal/manager"
 	"github.com/benthosdev/benthos/v4/internal/old/input"
 	"github.com/benthosdev/benthos/v4/internal/old/output"
 	"github.com/benthosdev/benthos/v4/internal/old/processor"
@@ -97,7 +101,7 @@ func (e *Environment) RegisterBatchBuffer(name string, spec *ConfigSpec, ctor Ba
 		if err != nil {
 			return nil, err
 		}
-		return buffer.NewStream(conf.Type, newAirGapBatchBuffer(b), nm.Logger(), nm.Metrics()), nil
+		return buffer.NewStream(conf.Type, newAirGapBatchBuffer(b), nm), nil
 	}, componentSpec)
 }
 
@@ -164,7 +168,7 @@ func (e *Environment) RegisterInput(name string, spec *ConfigSpec, ctor InputCon
 			return nil, err
 		}
 		rdr := newAirGapReader(i)
-		return input.NewAsyncReader(conf.Type, false, rdr, nm.Logger(), nm.Metrics())
+		return input.NewAsyncReader(conf.Type, false, rdr, nm)
 	}), componentSpec)
 }
 
@@ -191,7 +195,7 @@ func (e *Environment) RegisterBatchInput(name string, spec *ConfigSpec, ctor Bat
 			return nil, err
 		}
 		rdr := newAirGapBatchReader(i)
-		return input.NewAsyncReader(conf.Type, false, rdr, nm.Logger(), nm.Metrics())
+		return input.NewAsyncReader(conf.Type, false, rdr, nm)
 	}), componentSpec)
 }
 
@@ -377,24 +381,87 @@ func (e *Environment) WalkRateLimits(fn func(name string, config *ConfigView)) {
 	}
 }
 
+// RegisterMetricsExporter attempts to register a new metrics exporter plugin by
+// providing a description of the configuration for the plugin as well as a
+// constructor for the metrics exporter itself. The constructor will be called
+// for each instantiation of the component within a config.
+//
+// Experimental: This method may change outside of major version releases.
+func (e *Environment) RegisterMetricsExporter(name string, spec *ConfigSpec, ctor MetricsExporterConstructor) error {
+	componentSpec := spec.component
+	componentSpec.Name = name
+	componentSpec.Type = docs.TypeMetrics
+	return e.internal.MetricsAdd(func(conf metrics.Config, l log.Modular) (metrics.Type, error) {
+		nm, err := e.newPluginManagement(l)
+		if err != nil {
+			return nil, err
+		}
+		pluginConf, err := extractConfig(nm, spec, name, conf.Plugin, conf)
+		if err != nil {
+			return nil, err
+		}
+		m, err := ctor(pluginConf, newReverseAirGapLogger(l))
+		if err != nil {
+			return nil, err
+		}
+		return newAirGapMetrics(m), nil
+	}, componentSpec)
+}
+
 // WalkMetrics executes a provided function argument for every metrics component
-// that has been registered to the environment. Note that metrics components
-// available to an environment cannot be modified
+// that has been registered to the environment.
 func (e *Environment) WalkMetrics(fn func(name string, config *ConfigView)) {
-	for _, v := range bundle.AllMetrics.Docs() {
+	for _, v := range e.internal.MetricsDocs() {
 		fn(v.Name, &ConfigView{
 			component: v,
 		})
 	}
 }
 
+// RegisterOtelTracerProvider attempts to register a new open telemetry tracer
+// provider plugin by providing a description of the configuration for the
+// plugin as well as a constructor for the tracer provider itself. The
+// constructor will be called for each instantiation of the component within a
+// config.
+//
+// Experimental: This method may change outside of major version releases.
+func (e *Environment) RegisterOtelTracerProvider(name string, spec *ConfigSpec, ctor OtelTracerProviderConstructor) error {
+	componentSpec := spec.component
+	componentSpec.Name = name
+	componentSpec.Type = docs.TypeTracer
+	return e.internal.TracersAdd(func(conf tracer.Config) (tracer.Type, error) {
+		nm, err := e.newPluginManagement(log.Noop())
+		if err != nil {
+			return nil, err
+		}
+		pluginConf, err := extractConfig(nm, spec, name, conf.Plugin, conf)
+		if err != nil {
+			return nil, err
+		}
+		t, err := ctor(pluginConf)
+		if err != nil {
+			return nil, err
+		}
+		return newAirGapTracer(t), nil
+	}, componentSpec)
+}
+
 // WalkTracers executes a provided function argument for every tracer component
-// that has been registered to the environment. Note that tracer components
-// available to an environment cannot be modified
+// that has been registered to the environment.
 func (e *Environment) WalkTracers(fn func(name string, config *ConfigView)) {
-	for _, v := range bundle.AllTracers.Docs() {
+	for _, v := range e.internal.TracersDocs() {
 		fn(v.Name, &ConfigView{
 			component: v,
 		})
 	}
 }
+
+// Metrics exporters and tracers are created before any other resources and
+// therefore their configs are parsed with an isolated manager.
+func (e *Environment) newPluginManagement(l log.Modular) (bundle.NewManagement, error) {
+	return manager.NewV2(
+		manager.NewResourceConfig(), nil, l, metrics.Noop(),
+		manager.OptSetEnvironment(e.internal),
+		manager.OptSetBloblangEnvironment(e.getBloblangParserEnv()),
+	)
+}
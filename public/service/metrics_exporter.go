@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/benthosdev/benthos/v4/internal/component/metrics"
+)
+
+// MetricsExporterCounter represents a counter metric of a given name and
+// labels, created by a MetricsExporter.
+//
+// Experimental: This type may change outside of major version releases.
+type MetricsExporterCounter interface {
+	// Incr increments a counter metric by an integer amount.
+	Incr(count int64)
+}
+
+// MetricsExporterTimer represents a timing metric of a given name and labels,
+// created by a MetricsExporter.
+//
+// Experimental: This type may change outside of major version releases.
+type MetricsExporterTimer interface {
+	// Timing sets a timing metric, deltas are measured in nanoseconds.
+	Timing(delta int64)
+}
+
+// MetricsExporterGauge represents a gauge metric of a given name and labels,
+// created by a MetricsExporter.
+//
+// Experimental: This type may change outside of major version releases.
+type MetricsExporterGauge interface {
+	// Set a gauge metric to an integer value.
+	Set(value int64)
+}
+
+// MetricsExporterCounterCtor is a constructor for a MetricsExporterCounter that
+// must be called with a variadic list of label values exactly matching the
+// length and order of the label keys provided.
+//
+// Experimental: This type may change outside of major version releases.
+type MetricsExporterCounterCtor func(labelValues ...string) MetricsExporterCounter
+
+// MetricsExporterTimerCtor is a constructor for a MetricsExporterTimer that
+// must be called with a variadic list of label values exactly matching the
+// length and order of the label keys provided.
+//
+// Experimental: This type may change outside of major version releases.
+type MetricsExporterTimerCtor func(labelValues ...string) MetricsExporterTimer
+
+// MetricsExporterGaugeCtor is a constructor for a MetricsExporterGauge that
+// must be called with a variadic list of label values exactly matching the
+// length and order of the label keys provided.
+//
+// Experimental: This type may change outside of major version releases.
+type MetricsExporterGaugeCtor func(labelValues ...string) MetricsExporterGauge
+
+// MetricsExporter is an interface implemented by Benthos metrics exporters.
+// Exporters are provided the name and label keys of each metric upfront and
+// return a constructor that is called with label values each time a labelled
+// variant of the metric is needed.
+//
+// Experimental: This type may change outside of major version releases.
+type MetricsExporter interface {
+	NewCounterCtor(name string, labelKeys ...string) MetricsExporterCounterCtor
+	NewTimerCtor(name string, labelKeys ...string) MetricsExporterTimerCtor
+	NewGaugeCtor(name string, labelKeys ...string) MetricsExporterGaugeCtor
+	Close(ctx context.Context) error
+}
+
+//------------------------------------------------------------------------------
+
+// Implements metrics.Type
+type airGapMetrics struct {
+	airGapped MetricsExporter
+
+	// Exporter gauges only support setting an absolute value, and therefore we
+	// track the current value of each labelled variant in order to support
+	// increments and decrements.
+	gaugesMut sync.Mutex
+	gauges    map[string]*airGapGauge
+}
+
+func newAirGapMetrics(m MetricsExporter) metrics.Type {
+	return &airGapMetrics{
+		airGapped: m,
+		gauges:    map[string]*airGapGauge{},
+	}
+}
+
+func (a *airGapMetrics) GetCounter(path string) metrics.StatCounter {
+	return a.GetCounterVec(path).With()
+}
+
+func (a *airGapMetrics) GetCounterVec(path string, labelNames ...string) metrics.StatCounterVec {
+	ctor := a.airGapped.NewCounterCtor(path, labelNames...)
+	return metrics.FakeCounterVec(func(labelValues ...string) metrics.StatCounter {
+		return ctor(labelValues...)
+	})
+}
+
+func (a *airGapMetrics) GetTimer(path string) metrics.StatTimer {
+	return a.GetTimerVec(path).With()
+}
+
+func (a *airGapMetrics) GetTimerVec(path string, labelNames ...string) metrics.StatTimerVec {
+	ctor := a.airGapped.NewTimerCtor(path, labelNames...)
+	return metrics.FakeTimerVec(func(labelValues ...string) metrics.StatTimer {
+		return ctor(labelValues...)
+	})
+}
+
+func (a *airGapMetrics) GetGauge(path string) metrics.StatGauge {
+	return a.GetGaugeVec(path).With()
+}
+
+func (a *airGapMetrics) GetGaugeVec(path string, labelNames ...string) metrics.StatGaugeVec {
+	ctor := a.airGapped.NewGaugeCtor(path, labelNames...)
+	return metrics.FakeGaugeVec(func(labelValues ...string) metrics.StatGauge {
+		key := path + "\x00" + strings.Join(labelValues, "\x00")
+
+		a.gaugesMut.Lock()
+		defer a.gaugesMut.Unlock()
+
+		g, exists := a.gauges[key]
+		if !exists {
+			g = &airGapGauge{g: ctor(labelValues...)}
+			a.gauges[key] = g
+		}
+		return g
+	})
+}
+
+func (a *airGapMetrics) HandlerFunc() http.HandlerFunc {
+	return nil
+}
+
+func (a *airGapMetrics) Close() error {
+	return a.airGapped.Close(context.Background())
+}
+
+type airGapGauge struct {
+	mut   sync.Mutex
+	value int64
+	g     MetricsExporterGauge
+}
+
+func (a *airGapGauge) Set(value int64) {
+	a.mut.Lock()
+	a.value = value
+	a.g.Set(value)
+	a.mut.Unlock()
+}
+
+func (a *airGapGauge) Incr(count int64) {
+	a.mut.Lock()
+	a.value += count
+	a.g.Set(a.value)
+	a.mut.Unlock()
+}
+
+func (a *airGapGauge) Decr(count int64) {
+	a.mut.Lock()
+	a.value -= count
+	a.g.Set(a.value)
+	a.mut.Unlock()
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/benthosdev/benthos/v4/internal/component/metrics"
+	"github.com/benthosdev/benthos/v4/internal/log"
+)
+
+type mockMetricsExporter struct {
+	mut    sync.Mutex
+	prefix string
+	values map[string]int64
+	closed bool
+}
+
+func (m *mockMetricsExporter) key(name string, labelKeys, labelValues []string) string {
+	var kvs []string
+	for i, k := range labelKeys {
+		kvs = append(kvs, fmt.Sprintf("%v=%v", k, labelValues[i]))
+	}
+	return fmt.Sprintf("%v%v{%v}", m.prefix, name, strings.Join(kvs, ","))
+}
+
+type fnMetricsExporterStat func(int64)
+
+func (f fnMetricsExporterStat) Incr(v int64)   { f(v) }
+func (f fnMetricsExporterStat) Timing(v int64) { f(v) }
+func (f fnMetricsExporterStat) Set(v int64)    { f(v) }
+
+func (m *mockMetricsExporter) NewCounterCtor(name string, labelKeys ...string) MetricsExporterCounterCtor {
+	return func(labelValues ...string) MetricsExporterCounter {
+		k := m.key(name, labelKeys, labelValues)
+		return fnMetricsExporterStat(func(v int64) {
+			m.mut.Lock()
+			m.values[k] += v
+			m.mut.Unlock()
+		})
+	}
+}
+
+func (m *mockMetricsExporter) NewTimerCtor(name string, labelKeys ...string) MetricsExporterTimerCtor {
+	return func(labelValues ...string) MetricsExporterTimer {
+		k := m.key(name, labelKeys, labelValues)
+		return fnMetricsExporterStat(func(v int64) {
+			m.mut.Lock()
+			m.values[k] = v
+			m.mut.Unlock()
+		})
+	}
+}
+
+func (m *mockMetricsExporter) NewGaugeCtor(name string, labelKeys ...string) MetricsExporterGaugeCtor {
+	return func(labelValues ...string) MetricsExporterGauge {
+		k := m.key(name, labelKeys, labelValues)
+		return fnMetricsExporterStat(func(v int64) {
+			m.mut.Lock()
+			m.values[k] = v
+			m.mut.Unlock()
+		})
+	}
+}
+
+func (m *mockMetricsExporter) Close(ctx context.Context) error {
+	m.mut.Lock()
+	m.closed = true
+	m.mut.Unlock()
+	return nil
+}
+
+func TestMetricsExporterPlugin(t *testing.T) {
+	env := NewEnvironment()
+
+	var exporter *mockMetricsExporter
+	require.NoError(t, env.RegisterMetricsExporter("meow_exporter",
+		NewConfigSpec().Field(NewStringField("prefix")),
+		func(conf *ParsedConfig, log *Logger) (MetricsExporter, error) {
+			prefix, err := conf.FieldString("prefix")
+			if err != nil {
+				return nil, err
+			}
+			exporter = &mockMetricsExporter{
+				prefix: prefix,
+				values: map[string]int64{},
+			}
+			return exporter, nil
+		}))
+
+	var seen bool
+	env.WalkMetrics(func(name string, config *ConfigView) {
+		if name == "meow_exporter" {
+			seen = true
+		}
+	})
+	assert.True(t, seen)
+
+	var node yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte(`
+meow_exporter:
+  prefix: foo_
+`), &node))
+
+	conf := metrics.NewConfig()
+	require.NoError(t, node.Decode(&conf))
+	assert.Equal(t, "meow_exporter", conf.Type)
+
+	stats, err := env.internal.MetricsInit(conf, log.Noop())
+	require.NoError(t, err)
+
+	nm := newReverseAirGapMetrics(stats)
+
+	ctr := nm.NewCounter("counterone", "label1")
+	ctr.Incr(10, "a")
+	ctr.Incr(11, "a")
+	ctr.Incr(5, "b")
+
+	gge := nm.NewGauge("gaugeone")
+	gge.Set(12)
+	stats.GetGauge("gaugeone").Incr(3)
+	stats.GetGauge("gaugeone").Decr(1)
+
+	tmr := nm.NewTimer("timerone", "label1", "label2")
+	tmr.Timing(13, "c", "d")
+
+	assert.Equal(t, map[string]int64{
+		"foo_counterone{label1=a}":        21,
+		"foo_counterone{label1=b}":        5,
+		"foo_gaugeone{}":                  14,
+		"foo_timerone{label1=c,label2=d}": 13,
+	}, exporter.values)
+
+	require.NoError(t, stats.Close())
+	assert.True(t, exporter.closed)
+}
@@ -0,0 +1,71 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/benthosdev/benthos/v4/public/service"
+)
+
+func TestOtelTracerPluginStreamBuilder(t *testing.T) {
+	env := service.NewEnvironment()
+
+	recorder := tracetest.NewSpanRecorder()
+	prov := tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder))
+
+	require.NoError(t, env.RegisterOtelTracerProvider("meow_tracer", service.NewConfigSpec(),
+		func(conf *service.ParsedConfig) (trace.TracerProvider, error) {
+			return prov, nil
+		}))
+
+	builder := env.NewStreamBuilder()
+	require.NoError(t, builder.SetYAML(`
+input:
+  generate:
+    count: 2
+    interval: ""
+    mapping: 'root = "hello world"'
+
+pipeline:
+  processors:
+    - bloblang: 'root = content().uppercase()'
+
+output:
+  drop: {}
+
+tracer:
+  meow_tracer: {}
+
+logger:
+  level: none
+`))
+
+	strm, err := builder.Build()
+	require.NoError(t, err)
+
+	ctx, done := context.WithTimeout(context.Background(), time.Second*10)
+	defer done()
+	require.NoError(t, strm.Run(ctx))
+
+	spanNames := map[string]int{}
+	for _, s := range recorder.Ended() {
+		spanNames[s.Name()]++
+	}
+	assert.Equal(t, map[string]int{
+		"input_generate": 2,
+		"bloblang":       2,
+		"output_drop":    2,
+	}, spanNames)
+
+	// The plugin provider must not leak into the global provider, which would
+	// be shared by all other streams of the process.
+	assert.NotEqual(t, prov, otel.GetTracerProvider())
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"github.com/benthosdev/benthos/v4/internal/sqldriver"
+)
+
+// SQLPlaceholderFormat describes the syntax of argument placeholders within
+// queries expected by a SQL driver.
+//
+// Experimental: This type may change outside of major version releases.
+type SQLPlaceholderFormat int
+
+// Placeholder formats that can be specified for a SQL driver.
+const (
+	// SQLPlaceholderQuestion is of the form `?`.
+	SQLPlaceholderQuestion SQLPlaceholderFormat = iota
+	// SQLPlaceholderDollar is of the form `$1`.
+	SQLPlaceholderDollar
+	// SQLPlaceholderColon is of the form `:1`.
+	SQLPlaceholderColon
+	// SQLPlaceholderAtP is of the form `@p1`.
+	SQLPlaceholderAtP
+)
+
+// SQLDriverSpec describes how the sql components of Benthos should interact
+// with a database/sql driver.
+//
+// Experimental: This type may change outside of major version releases.
+type SQLDriverSpec struct {
+	spec sqldriver.Spec
+}
+
+// NewSQLDriverSpec creates a new SQL driver spec that uses question mark
+// placeholders.
+//
+// Experimental: This function may change outside of major version releases.
+func NewSQLDriverSpec() *SQLDriverSpec {
+	return &SQLDriverSpec{}
+}
+
+// Placeholders sets the format of argument placeholders within queries
+// generated for the driver.
+func (s *SQLDriverSpec) Placeholders(format SQLPlaceholderFormat) *SQLDriverSpec {
+	s.spec.Placeholder = sqldriver.PlaceholderFormat(format)
+	return s
+}
+
+// BatchWithTransactions specifies that batched inserts must be executed with a
+// prepared statement within a transaction, which is required by some column
+// oriented databases.
+func (s *SQLDriverSpec) BatchWithTransactions() *SQLDriverSpec {
+	s.spec.UseTxStmt = true
+	return s
+}
+
+// RegisterSQLDriver adds a database/sql driver to the list of drivers supported
+// by the sql components (sql_insert, sql_select, sql_raw, etc). The driver
+// itself must be registered separately with database/sql under the same name,
+// which is usually done by importing the driver package.
+//
+// Experimental: This function may change outside of major version releases.
+func RegisterSQLDriver(name string, spec *SQLDriverSpec) error {
+	return sqldriver.Register(name, spec.spec)
+}
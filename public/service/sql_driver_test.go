@@ -0,0 +1,28 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/benthosdev/benthos/v4/internal/sqldriver"
+)
+
+func TestRegisterSQLDriver(t *testing.T) {
+	require.NoError(t, RegisterSQLDriver("meow_sql", NewSQLDriverSpec().
+		Placeholders(SQLPlaceholderColon).
+		BatchWithTransactions()))
+
+	assert.Equal(t, sqldriver.Spec{
+		Placeholder: sqldriver.PlaceholderColon,
+		UseTxStmt:   true,
+	}, sqldriver.Get("meow_sql"))
+
+	err := RegisterSQLDriver("meow_sql", NewSQLDriverSpec())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already registered")
+
+	require.Error(t, RegisterSQLDriver("postgres", NewSQLDriverSpec()))
+	assert.Equal(t, sqldriver.Spec{}, sqldriver.Get("woof_sql"))
+}
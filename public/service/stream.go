@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/benthosdev/benthos/v4/internal/component/metrics"
+	"github.com/benthosdev/benthos/v4/internal/component/tracer"
 	"github.com/benthosdev/benthos/v4/internal/log"
 	"github.com/benthosdev/benthos/v4/internal/manager"
 	"github.com/benthosdev/benthos/v4/internal/shutdown"
@@ -24,14 +25,16 @@ type Stream struct {
 	conf   stream.Config
 	mgr    *manager.Type
 	stats  metrics.Type
+	tracer tracer.Type
 	logger log.Modular
 }
 
-func newStream(conf stream.Config, mgr *manager.Type, stats metrics.Type, logger log.Modular, onStart func()) *Stream {
+func newStream(conf stream.Config, mgr *manager.Type, stats metrics.Type, tracer tracer.Type, logger log.Modular, onStart func()) *Stream {
 	return &Stream{
 		conf:    conf,
 		mgr:     mgr,
 		stats:   stats,
+		tracer:  tracer,
 		logger:  logger,
 		shutSig: shutdown.NewSignaller(),
 		onStart: onStart,
@@ -91,6 +94,7 @@ func (s *Stream) StopWithin(timeout time.Duration) error {
 		// Still attempt to shut down other resources but do not block.
 		go func() {
 			s.mgr.CloseAsync()
+			s.tracer.Close()
 			s.stats.Close()
 		}()
 		return err
@@ -99,9 +103,13 @@ func (s *Stream) StopWithin(timeout time.Duration) error {
 	s.mgr.CloseAsync()
 	if err := s.mgr.WaitForClose(time.Until(stopAt)); err != nil {
 		// Same as above, attempt to shut down other resources but do not block.
-		go s.stats.Close()
+		go func() {
+			s.tracer.Close()
+			s.stats.Close()
+		}()
 		return err
 	}
 
+	_ = s.tracer.Close()
 	return s.stats.Close()
 }
@@ -19,6 +19,7 @@ import (
 	"github.com/benthosdev/benthos/v4/internal/component/cache"
 	"github.com/benthosdev/benthos/v4/internal/component/metrics"
 	"github.com/benthosdev/benthos/v4/internal/component/ratelimit"
+	"github.com/benthosdev/benthos/v4/internal/component/tracer"
 	"github.com/benthosdev/benthos/v4/internal/config"
 	"github.com/benthosdev/benthos/v4/internal/docs"
 	"github.com/benthosdev/benthos/v4/internal/log"
@@ -49,6 +50,7 @@ type StreamBuilder struct {
 	outputs    []output.Config
 	resources  manager.ResourceConfig
 	metrics    metrics.Config
+	tracer     tracer.Config
 	logger     log.Config
 
 	producerChan chan message.Transaction
@@ -70,6 +72,7 @@ func NewStreamBuilder() *StreamBuilder {
 		buffer:    buffer.NewConfig(),
 		resources: manager.NewResourceConfig(),
 		metrics:   metrics.NewConfig(),
+		tracer:    tracer.NewConfig(),
 		logger:    log.NewConfig(),
 		env:       globalEnvironment,
 	}
@@ -78,7 +81,7 @@ func NewStreamBuilder() *StreamBuilder {
 func (s *StreamBuilder) getLintContext() docs.LintContext {
 	ctx := docs.NewLintContext()
 	ctx.DocsProvider = s.env.internal
-	ctx.BloblangEnv = s.env.getBloblangParserEnv().Deactivated()
+	ctx.BloblangEnv = s.env.getBloblangParserEnv().Deactivated().WithTypeChecking()
 	return ctx
 }
 
@@ -547,6 +550,7 @@ func (s *StreamBuilder) setFromConfig(sconf config.Type) {
 	s.resources = sconf.ResourceConfig
 	s.logger = sconf.Logger
 	s.metrics = sconf.Metrics
+	s.tracer = sconf.Tracer
 }
 
 // SetBufferYAML parses a buffer YAML configuration and sets it to the builder
@@ -592,6 +596,27 @@ func (s *StreamBuilder) SetMetricsYAML(conf string) error {
 	return nil
 }
 
+// SetTracerYAML parses a tracer YAML configuration and adds it to the builder
+// such that all stream components emit tracing spans through it.
+func (s *StreamBuilder) SetTracerYAML(conf string) error {
+	nconf, err := getYAMLNode([]byte(conf))
+	if err != nil {
+		return err
+	}
+
+	if err := s.lintYAMLComponent(nconf, docs.TypeTracer); err != nil {
+		return err
+	}
+
+	tconf := tracer.NewConfig()
+	if err := nconf.Decode(&tconf); err != nil {
+		return err
+	}
+
+	s.tracer = tconf
+	return nil
+}
+
 // SetLoggerYAML parses a logger YAML configuration and adds it to the builder
 // such that all stream components emit logs through it.
 func (s *StreamBuilder) SetLoggerYAML(conf string) error {
@@ -700,8 +725,14 @@ func (s *StreamBuilder) buildWithEnv(env *bundle.Environment) (*Stream, error) {
 		}
 	}
 
-	stats, err := bundle.AllMetrics.Init(s.metrics, logger)
+	stats, err := env.MetricsInit(s.metrics, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	trac, err := env.TracersInit(s.tracer)
 	if err != nil {
+		_ = stats.Close()
 		return nil, err
 	}
 
@@ -727,8 +758,11 @@ func (s *StreamBuilder) buildWithEnv(env *bundle.Environment) (*Stream, error) {
 		conf.ResourceConfig, apiMut, logger, stats,
 		manager.OptSetEnvironment(env),
 		manager.OptSetBloblangEnvironment(s.env.getBloblangParserEnv()),
+		manager.OptSetTracer(trac.TracerProvider()),
 	)
 	if err != nil {
+		_ = trac.Close()
+		_ = stats.Close()
 		return nil, err
 	}
 
@@ -736,7 +770,7 @@ func (s *StreamBuilder) buildWithEnv(env *bundle.Environment) (*Stream, error) {
 		mgr.SetPipe(s.producerID, s.producerChan)
 	}
 
-	return newStream(conf.Config, mgr, stats, logger, func() {
+	return newStream(conf.Config, mgr, stats, trac, logger, func() {
 		if err := s.runConsumerFunc(mgr); err != nil {
 			logger.Errorf("Failed to run func consumer: %v", err)
 		}
@@ -748,6 +782,7 @@ type builderConfig struct {
 	stream.Config          `yaml:",inline"`
 	manager.ResourceConfig `yaml:",inline"`
 	Metrics                metrics.Config `yaml:"metrics"`
+	Tracer                 tracer.Config  `yaml:"tracer"`
 	Logger                 *log.Config    `yaml:"logger,omitempty"`
 }
 
@@ -781,6 +816,7 @@ func (s *StreamBuilder) buildConfig() builderConfig {
 
 	conf.ResourceConfig = s.resources
 	conf.Metrics = s.metrics
+	conf.Tracer = s.tracer
 	if s.customLogger == nil {
 		conf.Logger = &s.logger
 	}